@@ -1,9 +1,15 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/TykTechnologies/governance-action/pkg/core"
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -22,21 +28,505 @@ func main() {
 	logger, _ := config.Build()
 	defer logger.Sync()
 
+	var noFail, debugHTTP bool
+	var statusFile, outputFile, debugBundle string
+	var options core.RunOptions
+
 	rootCmd := &cobra.Command{
 		Use:   "governance-action",
 		Short: "Governance CI Action for analyzing OpenAPI specifications",
 		Long: `A CI action that analyzes OpenAPI specifications against governance rules.
 This action can be used in GitHub Actions and GitLab CI to ensure API compliance.`,
+		// Flags of the inputs apply to every command
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return applyInputFlags(cmd)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return core.RunAction(logger)
+			var recorder *integrations.HTTPRecorder
+			if debugHTTP {
+				recorder = integrations.EnableHTTPDebug()
+			}
+
+			if statusFile != "" {
+				options.Reports = append(options.Reports, statusFile)
+			}
+			if outputFile != "" {
+				options.Reports = append(options.Reports, outputFile)
+			}
+			if debugHTTP {
+				options.Reports = append(options.Reports, debugBundle)
+			}
+			result, err := core.RunAction(logger, options)
+			logger.Info("Governance run finished",
+				zap.String("verdict", result.Verdict), zap.Int("errors", result.Errors),
+				zap.Int("warnings", result.Warnings), zap.Duration("duration", result.Duration))
+			fmt.Println(result.ResultLine(err))
+
+			if recorder != nil {
+				metadata := map[string]string{
+					"version":  core.Version,
+					"platform": runtime.GOOS + "/" + runtime.GOARCH,
+					"ci":       integrations.DetectCI(),
+					"verdict":  result.Verdict,
+
+					"started_at":  result.StartedAt.Format(time.RFC3339),
+					"finished_at": result.FinishedAt.Format(time.RFC3339),
+				}
+				if err != nil {
+					metadata["error"] = err.Error()
+				}
+				if err := recorder.WriteBundle(debugBundle, metadata); err != nil {
+					logger.Warn("Failed to write the HTTP debug bundle", zap.Error(err))
+				} else {
+					logger.Info("Wrote HTTP debug bundle", zap.String("path", debugBundle))
+				}
+			}
+
+			if statusFile != "" {
+				if err := core.WriteStatusFile(statusFile, result, err); err != nil {
+					return err
+				}
+			}
+			if outputFile != "" {
+				if err := core.WriteResultsFile(outputFile, result, err); err != nil {
+					return err
+				}
+			}
+			// Leave gating to the pipeline, but still fail runs that didn't reach a verdict
+			if noFail && result.Verdict == core.VerdictFail {
+				logger.Warn("Governance verdict is fail, exiting with success because of --no-fail", zap.Error(err))
+				return nil
+			}
+			return err
 		},
 		// Disable help text on error for cleaner CI output
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
 
+	addInputFlags(rootCmd)
+	rootCmd.Flags().BoolVar(&noFail, "no-fail", core.Input("NO_FAIL") == "true",
+		"exit with success when the verdict is fail, e.g. to gate on the status file instead")
+	rootCmd.Flags().StringVar(&statusFile, "status-file", core.Input("STATUS_FILE"),
+		"write the verdict and counts of the run as JSON to this file")
+	rootCmd.Flags().StringVar(&outputFile, "output-file", core.Input("OUTPUT_FILE"),
+		"write the full result set of the run, findings included, as versioned JSON to this file")
+	rootCmd.Flags().BoolVar(&debugHTTP, "debug-http", core.Input("DEBUG_HTTP") == "true",
+		"capture sanitized HTTP requests and responses to a debug bundle for support")
+	defaultBundle := core.Input("DEBUG_BUNDLE")
+	if defaultBundle == "" {
+		defaultBundle = "governance-debug.zip"
+	}
+	rootCmd.Flags().StringVar(&debugBundle, "debug-bundle", defaultBundle, "path of the --debug-http bundle")
+	rootCmd.Flags().BoolVar(&options.DebugEnv, "debug-env", core.Input("DEBUG_ENV") == "true",
+		"print the governance environment variables, secrets redacted, and how each setting was resolved")
+	rootCmd.Flags().StringVar(&options.SnapshotDir, "snapshot-dir", core.Input("SNAPSHOT_DIR"),
+		"write canonical snapshots of the reports to this directory")
+	rootCmd.Flags().BoolVar(&options.SnapshotCompare, "snapshot-compare", core.Input("SNAPSHOT_COMPARE") == "true",
+		"fail when the reports drift from the snapshots in --snapshot-dir instead of writing them")
+	rootCmd.Flags().StringSliceVar(&options.Formats, "format", nil,
+		"reporters of the run, e.g. console, sarif, codequality, markdown, junit, json or admission, each optionally as format:path (repeatable)")
+	rootCmd.Flags().StringSliceVar(&options.OnlyPaths, "only-path", nil,
+		`only analyze and enforce the paths matching this glob, e.g. "/users/**" (repeatable)`)
+	rootCmd.Flags().StringSliceVar(&options.OnlyTags, "only-tag", nil,
+		"only analyze and enforce the operations with this tag (repeatable)")
+
+	tuiCmd := &cobra.Command{
+		Use:   "tui <spec>",
+		Short: "Work through the findings of a spec in an interactive terminal UI",
+		Long: `Lists the governance findings of a spec next to the spec itself. Jump between
+findings, fix them in your editor and re-check to mark the fixed ones.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return core.RunTUI(logger, args[0], os.Stdin, os.Stdout)
+		},
+	}
+	rootCmd.AddCommand(tuiCmd)
+
+	exemptCmd := &cobra.Command{
+		Use:   "exempt <fingerprint> <justification>",
+		Short: "Request an exemption for a finding that can't be fixed",
+		Long: `Submits an exemption request for the finding with the given fingerprint to the
+governance service for approval. The fingerprint is shown with each finding in the report.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return core.RunExemption(logger, args[0], args[1])
+		},
+	}
+	rootCmd.AddCommand(exemptCmd)
+
+	var scanOptions core.ScanOptions
+	scanCmd := &cobra.Command{
+		Use:   "scan [dir]",
+		Short: "Analyze every spec in the repository and report on the whole catalog",
+		Long: `Walks the directory (the working directory by default) for OpenAPI, Swagger and
+AsyncAPI specs, analyzes each of them and writes a consolidated catalog report.
+Meant for scheduled pipelines: failing specs are reported but don't fail the scan.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scanOptions.Root = "."
+			if len(args) > 0 {
+				scanOptions.Root = args[0]
+			}
+			_, err := core.RunScan(logger, scanOptions)
+			return err
+		},
+	}
+	defaultReport := core.Input("SCAN_REPORT")
+	if defaultReport == "" {
+		defaultReport = "governance-catalog.json"
+	}
+	scanCmd.Flags().StringVar(&scanOptions.Report, "report", defaultReport, "path of the JSON catalog report")
+	scanCmd.Flags().StringVar(&scanOptions.MetricsURL, "metrics-url", core.Input("METRICS_URL"),
+		"Prometheus Pushgateway URL to push the catalog metrics to")
+	scanCmd.Flags().StringVar(&scanOptions.GitHubOrg, "github-org", core.Input("SCAN_GITHUB_ORG"),
+		"scan the repositories of a GitHub organization instead of the directory")
+	scanCmd.Flags().StringVar(&scanOptions.GitLabGroup, "gitlab-group", core.Input("SCAN_GITLAB_GROUP"),
+		"scan the projects of a GitLab group instead of the directory")
+	rootCmd.AddCommand(scanCmd)
+
+	var aggregateStatusFile string
+	var aggregateNoFail bool
+	aggregateCmd := &cobra.Command{
+		Use:   "aggregate <dir>",
+		Short: "Merge the results of parallel jobs into one report and verdict",
+		Long: `Reads the status files that parallel jobs wrote with --status-file, e.g. the
+downloaded artifacts of a matrix, and reports the combined counts, per-file
+matrix and verdict. The thresholds are applied to the combined counts.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := core.RunAggregate(logger, args[0], aggregateStatusFile)
+			fmt.Println(result.ResultLine(err))
+			if aggregateStatusFile != "" {
+				if err := core.WriteStatusFile(aggregateStatusFile, result, err); err != nil {
+					return err
+				}
+			}
+			if aggregateNoFail && result.Verdict == core.VerdictFail {
+				logger.Warn("Governance verdict is fail, exiting with success because of --no-fail", zap.Error(err))
+				return nil
+			}
+			return err
+		},
+	}
+	aggregateCmd.Flags().StringVar(&aggregateStatusFile, "status-file", core.Input("AGGREGATE_STATUS_FILE"),
+		"write the combined verdict and counts as JSON to this file")
+	aggregateCmd.Flags().BoolVar(&aggregateNoFail, "no-fail", core.Input("NO_FAIL") == "true",
+		"exit with success when the combined verdict is fail")
+	rootCmd.AddCommand(aggregateCmd)
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Check the repository configuration file",
+	}
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "validate [file]",
+		Short: "Validate the configuration file against its schema",
+		Long: `Checks the configuration file (.governance.yml by default) against the JSON
+Schema of the configuration and reports every problem with its path and line,
+for pre-merge checks of configuration changes.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if err := core.ValidateConfigFile(path); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return errors.New("configuration file is invalid")
+			}
+			fmt.Println("Configuration file is valid")
+			return nil
+		},
+	})
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the configuration file",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			os.Stdout.Write(core.ConfigSchema())
+		},
+	})
+	rootCmd.AddCommand(configCmd)
+
+	resultsCmd := &cobra.Command{
+		Use:   "results",
+		Short: "Work with the results file written with --output-file",
+	}
+	resultsCmd.AddCommand(&cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the results file",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			os.Stdout.Write(core.ResultsSchema())
+		},
+	})
+	rootCmd.AddCommand(resultsCmd)
+
+	var rulesetTestOptions core.RulesetTestOptions
+	rulesetCmd := &cobra.Command{
+		Use:   "ruleset",
+		Short: "Develop governance rulesets",
+	}
+	rulesetTestCmd := &cobra.Command{
+		Use:   "test <ruleset> <spec>...",
+		Short: "Evaluate a local ruleset against sample specs and report which rules fired",
+		Long: `Submits the ruleset file with each sample spec to the governance service and
+reports how often each rule fired on each spec, so rules can be checked in CI
+before the ruleset is published.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return core.RunRulesetTest(logger, args[0], args[1:], rulesetTestOptions)
+		},
+	}
+	rulesetTestCmd.Flags().BoolVar(&rulesetTestOptions.RequireAll, "require-all", false,
+		"fail when a rule of the ruleset fires on none of the specs")
+	rulesetCmd.AddCommand(rulesetTestCmd)
+	rootCmd.AddCommand(rulesetCmd)
+
+	var fixtureOptions core.FixtureOptions
+	var listViolations bool
+	fixtureCmd := &cobra.Command{
+		Use:   "generate-fixture",
+		Short: "Generate a spec that deliberately violates selected rules",
+		Long: `Writes an OpenAPI spec that breaks the selected rules, for end-to-end tests
+asserting that a pipeline blocks bad specs. Without --violations the spec is
+compliant, for the passing counterpart of such tests.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if listViolations {
+				core.PrintFixtureViolations(os.Stdout)
+				return nil
+			}
+			return core.RunGenerateFixture(fixtureOptions)
+		},
+	}
+	fixtureCmd.Flags().StringSliceVar(&fixtureOptions.Violations, "violations", nil,
+		"comma-separated violations the spec contains, see --list")
+	fixtureCmd.Flags().StringVar(&fixtureOptions.Format, "format", "yaml", "format of the spec: yaml or json")
+	fixtureCmd.Flags().StringVarP(&fixtureOptions.Output, "output", "o", "", "file the spec is written to instead of stdout")
+	fixtureCmd.Flags().BoolVar(&listViolations, "list", false, "list the known violations")
+	rootCmd.AddCommand(fixtureCmd)
+
+	var hookOptions core.HookOptions
+	hookCmd := &cobra.Command{
+		Use:   "hook [spec]",
+		Short: "Run as an Argo CD or Flux pre-sync hook",
+		Long: `Analyzes the spec file, or the specs in a directory such as a mounted ConfigMap,
+from a Kubernetes Job run before a GitOps sync. The outcome is printed as an
+event line and written as the container's termination message. Exits 0 for a
+pass or warn, 1 for a fail and 2 when no verdict was reached, e.g. the
+governance service was down, so a pod failure policy only retries the latter.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				hookOptions.Spec = args[0]
+			}
+			result, err := core.RunHook(logger, hookOptions)
+			fmt.Println(result.ResultLine(err))
+			if code := core.HookExitCode(result, err); code != core.HookExitPass {
+				logger.Error("Pre-sync hook failed", zap.Int("exit_code", code), zap.Error(err))
+				logger.Sync()
+				os.Exit(code)
+			}
+			return nil
+		},
+	}
+	hookCmd.Flags().StringVar(&hookOptions.ConfigMap, "configmap", core.Input("HOOK_CONFIGMAP"),
+		"read the specs from the keys of this ConfigMap, as [namespace/]name, through the Kubernetes API")
+	hookCmd.Flags().StringVar(&hookOptions.TerminationLog, "termination-log", core.Input("HOOK_TERMINATION_LOG"),
+		"termination message path of the container (default /dev/termination-log)")
+	rootCmd.AddCommand(hookCmd)
+
+	var lintVerbose bool
+	lintCmd := &cobra.Command{
+		Use:   "lint <file>...",
+		Short: "Analyze spec files from the terminal",
+		Long: `Runs the analysis of a CI run on the spec files, globs or git://<ref>:<path>
+specs given as arguments, for checking specs while editing them. The settings
+come from the flags, the environment and the configuration file as usual, but
+nothing is published to pull requests or the pipeline and no outputs are set.
+Exits 1 when the verdict is fail or no verdict was reached.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lintLogger := logger
+			if !lintVerbose {
+				lintLogger = logger.WithOptions(zap.IncreaseLevel(zapcore.WarnLevel))
+			}
+			result, err := core.RunLint(lintLogger, core.LintOptions{Files: args})
+			fmt.Println(result.LintSummary(err))
+			if err != nil {
+				logger.Sync()
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+	lintCmd.Flags().BoolVarP(&lintVerbose, "verbose", "v", false, "log every step of the run as in CI")
+	rootCmd.AddCommand(lintCmd)
+
+	validateCmd := &cobra.Command{
+		Use:   "validate <file>...",
+		Short: "Check the syntax and structure of spec files locally",
+		Long: `Parses the spec files, globs or git://<ref>:<path> specs given as arguments
+and checks their YAML or JSON syntax and duplicate keys. OpenAPI 3.0 and
+Swagger 2.0 documents are then loaded with their $refs and validated against
+the specification with kin-openapi. Nothing is sent to the governance service.
+Exits 1 when any spec has a problem.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := core.ValidateSpecs(core.ValidateOptions{Files: args})
+			if err != nil {
+				return err
+			}
+			for _, problem := range result.Problems {
+				fmt.Println(problem)
+			}
+			fmt.Println(result.Summary())
+			if len(result.Problems) > 0 {
+				logger.Sync()
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+	rootCmd.AddCommand(validateCmd)
+
+	selfTestCmd := &cobra.Command{
+		Use:   "self-test",
+		Short: "Run the analysis against an embedded mock service and verify the outputs",
+		Long: `Starts an embedded mock governance service, runs the analysis against it in every
+output format and checks the outputs, retries and auth failure handling. A smoke
+test for new self-hosted runner images; no governance service is needed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return core.RunSelfTest(logger)
+		},
+	}
+	rootCmd.AddCommand(selfTestCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		logger.Error("Action failed", zap.Error(err))
 		os.Exit(1)
 	}
 }
+
+// inputFlag is an action input exposed as a command line flag
+type inputFlag struct {
+	name  string // Input name, the flag is named with dashes
+	bool  bool   // Set without a value, e.g. --discover
+	value string // Default of the input, as in action.yml
+	usage string
+}
+
+// inputFlags are the inputs settable as flags of every command, so the binary
+// is usable outside CI without exporting INPUT_ variables. Inputs with a flag
+// of their own, such as --status-file, aren't repeated.
+var inputFlags = []inputFlag{
+	{"governance_service", false, "", "base URL of the governance service"},
+	{"governance_auth", false, "", "API token for the governance service, prefer GOVERNANCE_AUTH to keep it out of the process list"},
+	{"rule_id", false, "", "ID of the rule to evaluate"},
+	{"ruleset_version", false, "", "pin the ruleset version to evaluate against, the latest by default"},
+	{"ruleset_file", false, "", "local Spectral-style ruleset evaluated instead of --rule-id"},
+	{"api_path", false, "", "spec file to analyze, or comma-separated paths and globs such as apis/**/*.yaml"},
+	{"manifest", false, "", "manifest binding spec files to governance service API IDs and names"},
+	{"discover", true, "false", "without --api-path or --manifest, analyze every OpenAPI and Swagger spec in the working directory"},
+	{"document", false, "all", "YAML document to analyze in a multi-document spec file: all, or its 1-based index"},
+	{"normalize", true, "false", "send the spec in a canonical form so finding ranges are stable across cosmetic edits"},
+	{"bundle", true, "false", "inline the files the spec references with $ref"},
+	{"substitute_env", false, "", "comma-separated environment variables whose ${VAR} placeholders in the spec are substituted"},
+	{"max_spec_size", false, "50MB", "largest spec to analyze, e.g. 50MB, 0 disables the limit"},
+	{"mocked", false, "", `mock mode for testing: "success", "fail" or "warning"`},
+	{"config_file", false, "", "repository configuration file, .governance.yml by default"},
+	{"profile", false, "", "ruleset profile from the configuration file, e.g. internal, partner or public"},
+	{"policy_bundle", false, "", `URL of the organization policy bundle, or "service" to fetch it from the governance service`},
+	{"policy_bundle_token", false, "", "bearer token sent when fetching the policy bundle from a URL"},
+	{"mode", false, "", `enforcement mode, "enforce" or "advisory"`},
+	{"max_errors", false, "", "maximum number of errors before the run fails, 0 by default"},
+	{"max_warnings", false, "", "maximum number of warnings before the run fails, unlimited by default"},
+	{"unknown_severity", false, "warning", "severity findings with an unknown severity level count as: error, warning or info"},
+	{"exclude_deprecated", true, "false", "exclude findings on deprecated or sunset operations"},
+	{"honor_exemptions", true, "true", "exclude findings covered by approved exemptions"},
+	{"preview_upgrade", true, "false", "also evaluate against the latest ruleset version and report the delta"},
+	{"report_passed_rules", true, "false", "also report the rules of the ruleset that passed"},
+	{"rule_docs", false, "", "YAML or JSON bundle mapping rule names to a description and remediation"},
+	{"org_id", false, "", "organization of a multi-tenant governance deployment"},
+	{"team_id", false, "", "team within --org-id that results are attributed to"},
+	{"region", false, "", "data residency region, resolved to a governance service URL by the configuration file"},
+	{"payload_version", false, "auto", "analysis request payload shape: v2, v1 or auto"},
+	{"retries", false, "2", "retries for governance service requests failing with network errors, 429 or 5xx responses"},
+	{"retry_backoff", false, "1s", "wait before the first retry, doubled for every further retry"},
+	{"timeout", false, "30s", "timeout of each governance service request, every retry gets the full timeout"},
+	{"cache_dir", false, "", "directory for cached data such as ruleset metadata"},
+	{"metadata_cache_ttl", false, "", "how long cached ruleset metadata is used without re-fetching, e.g. 30m, 0 disables the cache"},
+	{"history", false, "", `run history for trend gates: "service", a URL or a local store file`},
+	{"history_token", false, "", "bearer token sent when reading and writing history from a URL"},
+	{"trend_gate", false, "", "comma-separated counts that must not increase over --trend-window: errors, warnings, total"},
+	{"trend_window", false, "7d", "time window trend gates look back over, e.g. 7d"},
+	{"trend_branch", false, "", "branch whose history trend gates compare against, the default branch by default"},
+	{"artifact_url", false, "", "base URL the report files are uploaded to, for the report links"},
+	{"admission_key", false, "", "key the admission verdict is signed with (HMAC-SHA256)"},
+	{"sarif_file", false, "governance.sarif", "path of the SARIF report"},
+	{"code_quality_file", false, "gl-code-quality-report.json", "path of the GitLab Code Quality report"},
+	{"junit_file", false, "governance-junit.xml", "path of the JUnit XML report"},
+	{"report_dir", false, "governance-report", "directory of the Markdown report"},
+	{"report_pages", false, "file", "what the Markdown report is paged by: file or tag"},
+	{"report_page_size", false, "500", "findings per page of the Markdown report"},
+	{"timezone", false, "UTC", "IANA timezone of the run timestamps in the reports, e.g. Europe/Berlin"},
+	{"locale", false, "en", "language of the console report: en, de, fr or es"},
+	{"snippet_context", false, "0", "lines of the spec shown before and after each finding snippet"},
+	{"snippet_max_lines", false, "20", "maximum lines of a snippet, 0 for no limit"},
+	{"snippet_tab_width", false, "4", "tab stop width tabs in snippets are expanded to, 0 keeps tabs"},
+	{"snippet_max_width", false, "160", "columns snippet lines are cut to around the finding, 0 for no limit"},
+	{"output_prefix", false, "", "prepended to every output name"},
+	{"gitlab_output_file", false, "governance_output.env", "path of the dotenv file GitLab outputs are written to"},
+	{"downstream_variables", true, "false", "also write GitLab outputs as GOVERNANCE_* variables for downstream pipelines"},
+	{"github_token", false, "", "GitHub token for pull request integrations, prefer GITHUB_TOKEN to keep it out of the process list"},
+	{"check_run", true, "false", "publish findings as a check run with inline annotations"},
+	{"step_summary", true, "true", "write a Markdown report to the GitHub workflow run summary"},
+	{"pr_comment", true, "false", "comment the report on the pull request"},
+	{"apply_labels", true, "false", "apply governance labels to the pull request"},
+	{"reviewers", false, "", "comma-separated users and teams to request review from when errors are found"},
+	{"blame", true, "false", "attribute findings on changed lines to the introducing commit and author"},
+	{"blame_base", false, "", "git revision to detect changed lines against"},
+	{"diff_base", false, "", `git revision to also analyze the specs at, reporting the findings the change adds and resolves, or "auto" for the pull request base`},
+	{"slack_webhook", false, "", "Slack incoming webhook findings are posted to"},
+	{"deployment_gate", true, "false", "gate deployments of the analyzed commit on the verdict"},
+	{"deployment_id", false, "", "deployment whose status --deployment-gate sets"},
+	{"deployment_environment", false, "", "environment --deployment-gate creates a deployment in"},
+	{"gitlab_token", false, "", "GitLab token for merge request integrations, prefer GITLAB_TOKEN to keep it out of the process list"},
+	{"mr_discussions", true, "false", "start a merge request discussion on the spec line of each finding"},
+	{"resolve_stale_discussions", true, "false", "resolve the merge request discussions of findings no longer reported"},
+	{"status_check", true, "false", "report the verdict to a GitLab external status check"},
+	{"status_check_name", false, "Governance", "name of the external status check --status-check reports to"},
+}
+
+// addInputFlags adds the input flags to a command and its subcommands. The
+// defaults are only shown in the help, flags that aren't given leave the
+// inputs of the environment to apply.
+func addInputFlags(cmd *cobra.Command) {
+	for _, input := range inputFlags {
+		name := strings.ReplaceAll(input.name, "_", "-")
+		if input.bool {
+			cmd.PersistentFlags().Bool(name, input.value == "true", input.usage)
+		} else {
+			cmd.PersistentFlags().String(name, input.value, input.usage)
+		}
+	}
+}
+
+// applyInputFlags sets the inputs of the flags given on the command line,
+// taking precedence over the environment
+func applyInputFlags(cmd *cobra.Command) error {
+	for _, input := range inputFlags {
+		name := strings.ReplaceAll(input.name, "_", "-")
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || !flag.Changed {
+			continue
+		}
+		if err := core.SetInput(strings.ToUpper(input.name), flag.Value.String(), "--"+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
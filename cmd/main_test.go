@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// ownFlags are the inputs with a flag of their own on the root command
+var ownFlags = map[string]string{
+	"only_path":        "only-path",
+	"only_tag":         "only-tag",
+	"no_fail":          "no-fail",
+	"status_file":      "status-file",
+	"output_file":      "output-file",
+	"output_format":    "format",
+	"debug_http":       "debug-http",
+	"debug_env":        "debug-env",
+	"debug_bundle":     "debug-bundle",
+	"snapshot_dir":     "snapshot-dir",
+	"snapshot_compare": "snapshot-compare",
+}
+
+// Every input of the action must be settable as a flag, and the help must show
+// the defaults the action applies
+func TestInputFlagsCoverActionInputs(t *testing.T) {
+	content, err := os.ReadFile("../action.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var action struct {
+		Inputs map[string]struct {
+			Default string `yaml:"default"`
+		} `yaml:"inputs"`
+	}
+	if err := yaml.Unmarshal(content, &action); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &cobra.Command{Use: "governance-action"}
+	addInputFlags(cmd)
+
+	for name, input := range action.Inputs {
+		if _, ok := ownFlags[name]; ok {
+			continue
+		}
+		flag := cmd.PersistentFlags().Lookup(strings.ReplaceAll(name, "_", "-"))
+		if flag == nil {
+			t.Errorf("input %s has no flag", name)
+			continue
+		}
+		want := input.Default
+		if strings.Contains(want, "${{") {
+			// Expressions are only evaluated by the runner
+			want = ""
+		}
+		if want == "" && flag.Value.Type() == "bool" {
+			want = "false"
+		}
+		if flag.DefValue != want {
+			t.Errorf("flag --%s defaults to %q, the input to %q", flag.Name, flag.DefValue, want)
+		}
+	}
+}
@@ -1,129 +1,753 @@
 package core
 
 import (
-	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/TykTechnologies/governance-action/pkg/integrations"
 	"go.uber.org/zap"
 )
 
-// RunAction is the main entry point for the governance action
-func RunAction(logger *zap.Logger) error {
-	logger.Info("Starting governance action")
+// RunAction is the main entry point for the governance action. The result is
+// returned even when the run fails, with the error verdict if no verdict was reached.
+//
+// A run goes through stages: the configuration is resolved, the specs are
+// analyzed, the verdict is decided, the results are published to the
+// integrations and finally persisted as outputs and report files.
+func RunAction(logger *zap.Logger, options RunOptions) (*RunResult, error) {
+	result := &RunResult{Verdict: VerdictError, StartedAt: time.Now().UTC()}
+	defer result.finish()
 
-	// Detect CI platform
+	logger.Info("Starting governance action", zap.String("version", Version))
+
+	// Detect CI platform, ad-hoc local runs ignore it
 	ci := integrations.DetectCI()
+	if options.Local {
+		ci = "local"
+	}
 	logger.Info("Detected CI platform", zap.String("platform", ci))
 
 	// Get context information
 	ciContext := integrations.GetContext(ci)
 	logger.Info("Retrieved context", zap.Any("context", ciContext))
+	result.ci, result.ciContext = ci, ciContext
+
+	config, err := configureRun(options, ciContext, logger)
+	if err != nil {
+		return result, err
+	}
+	result.StartedAt = result.StartedAt.In(config.Timezone)
+	if options.DebugEnv {
+		printDebugEnv(config.Console, config)
+	}
+
+	// Catch missing token permissions before the analysis rather than at the end
+	preflightIntegrations(context.Background(), config, ci, logger)
 
+	// Resolve the specs to analyze
+	targets, err := resolveTargets(config)
+	if err != nil {
+		logger.Error("Failed to resolve specs", zap.Error(err))
+		return result, fmt.Errorf("configuration error: %w", err)
+	}
+	if config.Discover && config.APIPath == "" && config.Manifest == "" {
+		paths := make([]string, 0, len(targets))
+		for _, target := range targets {
+			paths = append(paths, target.Path)
+		}
+		logger.Info("Discovered specs", zap.Int("spec_count", len(targets)), zap.Strings("specs", paths))
+	}
+
+	client, ruleset, err := connectGovernance(context.Background(), config, logger)
+	if err != nil {
+		return result, err
+	}
+	// Report retries and performance even when the analysis fails
+	defer result.recordServiceStats(config, client, logger)
+
+	report := &analysisReport{Ruleset: ruleset, Started: result.StartedAt, Coverage: &operationCoverage{}}
+	result.report = report
+	options.Reports = append(options.Reports, reportFiles(config)...)
+	report.ArtifactURLs = artifactURLs(config, ci, options.Reports)
+	report.ReportURL = reportURL(ci, options.Reports, report.ArtifactURLs)
+	run := &actionRun{
+		config: config, ci: ci, ciContext: ciContext, client: client,
+		targets: targets, report: report, result: result, logger: logger,
+	}
+
+	if err := run.analyze(); err != nil {
+		return result, err
+	}
+	// A comment requesting an exemption only submits the request
+	if command := exemptionFromComment(ci, ciContext, logger); command != nil {
+		result.record(config, report)
+		return result, requestExemption(context.Background(), config, client, ciContext, command, report.Findings, targets, logger)
+	}
+	run.correlate()
+
+	trendErr := run.decideVerdict()
+	run.publish()
+	if err := run.persist(options); err != nil {
+		return result, err
+	}
+	if trendErr != nil {
+		if config.Mode == ModeAdvisory {
+			logger.Warn("Trend gate failed, not failing in advisory mode", zap.Error(trendErr))
+		} else {
+			logger.Error("Trend gate failed", zap.Error(trendErr))
+			return result, fmt.Errorf("trend gate failed: %w", trendErr)
+		}
+	}
+
+	logger.Info("Governance action completed successfully")
+	return result, nil
+}
+
+// configureRun resolves the configuration of a run: the inputs and the
+// configuration file, then the command line options, the profile, the branch
+// ruleset and the organization policy over them, and the enforcement mode
+func configureRun(options RunOptions, ciContext map[string]string, logger *zap.Logger) (*Configuration, error) {
 	// Get configuration from environment
 	config, err := getConfiguration()
 	if err != nil {
 		logger.Error("Failed to get configuration", zap.Error(err))
-		return fmt.Errorf("configuration error: %w", err)
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+	// Command line options take precedence over the inputs
+	if len(options.OnlyPaths) > 0 {
+		config.OnlyPaths = options.OnlyPaths
+		config.override("only_path", "--only-path")
+	}
+	if len(options.OnlyTags) > 0 {
+		config.OnlyTags = options.OnlyTags
+		config.override("only_tag", "--only-tag")
+	}
+	if len(options.Formats) > 0 {
+		config.OutputFormats = options.Formats
+		config.override("output_format", "--format")
+	}
+	if options.APIPath != "" {
+		config.APIPath = options.APIPath
+		config.override("api_path", options.APIPathSource)
+	}
+	if options.Local {
+		config.localRun()
+	}
+	config.Console = os.Stdout
+	if options.Console != nil {
+		config.Console = options.Console
+	}
+
+	// Select the ruleset profile by name or branch
+	profileName, profile, err := selectProfile(config.Profile, config.Profiles, ciContext["branch"])
+	if err != nil {
+		logger.Error("Failed to select profile", zap.Error(err))
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+	if profile != nil {
+		logger.Info("Using profile", zap.String("profile", profileName))
+		config.Profile = profileName
+		config.applyProfile(profileName, profile)
+	}
+
+	// Select the ruleset for the branch unless it's set explicitly or by the profile
+	if ruleset := resolveRuleset(config.Rulesets, ciContext["branch"]); ruleset != nil && config.unsetOrFromFile("rule_id", config.RuleID) {
+		logger.Info("Using branch ruleset", zap.String("branch", ciContext["branch"]),
+			zap.String("rule_id", ruleset.RuleID), zap.String("ruleset_version", ruleset.RulesetVersion))
+		config.RuleID = ruleset.RuleID
+		config.override("rule_id", "the branch ruleset for "+ciContext["branch"])
+		if config.unsetOrFromFile("ruleset_version", config.RulesetVersion) {
+			config.RulesetVersion = ruleset.RulesetVersion
+		}
+	}
+
+	// Enforce the organization's central policy over the repository settings
+	if err := enforceOrgPolicy(context.Background(), config, logger); err != nil {
+		logger.Error("Failed to apply the organization policy bundle", zap.Error(err))
+		return nil, fmt.Errorf("configuration error: %w", err)
 	}
 
 	// Validate configuration
 	if err := config.Validate(); err != nil {
 		logger.Error("Invalid configuration", zap.Error(err))
-		return fmt.Errorf("invalid configuration: %w", err)
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	var results []integrations.LintResult
+	// Resolve the enforcement mode for the current branch
+	config.Mode = resolveMode(config.Mode, config.Policies, ciContext)
+	logger.Info("Resolved enforcement mode", zap.String("mode", config.Mode), zap.String("branch", ciContext["branch"]),
+		zap.String("event_type", ciContext["event_type"]))
+	return config, nil
+}
 
-	// Check if mocked mode is enabled
-	if config.Mocked != "" {
-		logger.Info("Running in mocked mode", zap.String("mocked_type", config.Mocked))
+// actionRun is the state a run threads through its stages
+type actionRun struct {
+	config    *Configuration
+	ci        string
+	ciContext map[string]string
+	client    *integrations.GovernanceClient
+	targets   []specTarget
+	report    *analysisReport
+	result    *RunResult
+	logger    *zap.Logger
 
-		// Generate mock results based on the mocked type
-		results = generateMockResults(config.Mocked, config.RuleID)
-		logger.Info("Generated mock results", zap.Int("result_count", len(results)), zap.String("mocked_type", config.Mocked))
-	} else {
-		// Normal mode - create governance client and analyze
-		client := integrations.NewGovernanceClient(config.GovernanceService, config.GovernanceAuth, logger)
+	// The diff-based modes analyze the specs a second time, concurrently
+	upgrade *concurrentAnalysis
+	base    *baseAnalysis
+}
 
-		// Read and validate the OAS file
-		oasContent, err := readOASFile(config.APIPath)
+// analyze analyzes the specs and sorts the findings into the ones that count
+// towards the result and the excluded ones
+func (r *actionRun) analyze() error {
+	config, report, logger := r.config, r.report, r.logger
+	analysisStarted := time.Now()
+	if config.PreviewUpgrade {
+		r.upgrade = startUpgradePreview(context.Background(), config, r.client, r.targets, logger)
+	}
+	if rev := resolveDiffBase(config, r.ciContext); rev != "" {
+		if _, err := integrations.ResolveRevision(context.Background(), rev); err != nil {
+			logger.Warn("Failed to resolve diff_base, skipping the comparison", zap.String("diff_base", rev), zap.Error(err))
+		} else {
+			r.base = startBaseAnalysis(context.Background(), config, r.client, r.targets, rev, logger)
+			report.DiffBase = rev
+		}
+	} else if config.DiffBase != "" {
+		logger.Warn("No base revision to compare against, set diff_base to a revision")
+	}
+	for _, target := range r.targets {
+		findings, content, err := analyzeTarget(context.Background(), config, r.client, target, logger)
 		if err != nil {
-			logger.Error("Failed to read OAS file", zap.Error(err), zap.String("path", config.APIPath))
-			return fmt.Errorf("failed to read OAS file: %w", err)
+			return err
 		}
+		report.Files = append(report.Files, target.Path)
 
-		// Analyze the OAS file
-		filename := filepath.Base(config.APIPath)
-		results, err = client.AnalyzeOAS(context.Background(), oasContent, config.RuleID, filename)
-		if err != nil {
-			logger.Error("Failed to analyze OAS", zap.Error(err))
-			return fmt.Errorf("failed to analyze OAS: %w", err)
+		// Apply the organization's severity overrides and ignores
+		var ignored []Finding
+		findings, ignored = config.OrgPolicy.filter(findings)
+		report.Excluded = append(report.Excluded, ignored...)
+
+		docs := parseSpecDocuments(content)
+		tagFindings(findings, docs)
+		// Surface the approvals recorded on the operations, honoring them if enabled
+		approvals, findings, approved := collectApprovals(config, target.Path, findings, docs)
+		report.Approvals = append(report.Approvals, approvals...)
+		report.Excluded = append(report.Excluded, approved...)
+		for _, doc := range docs {
+			// Compute spec statistics so violation counts can be normalized by API size
+			report.Stats = report.Stats.add(specStats(doc))
+		}
+		now := time.Now()
+		report.Coverage.addSpec(config, target.Path, docs, now)
+		// Drop findings on operations that are scheduled for removal
+		if config.ExcludeDeprecated && len(docs) > 0 {
+			var excluded []Finding
+			findings, excluded = excludeDeprecated(findings, docs, now)
+			report.Excluded = append(report.Excluded, excluded...)
 		}
+		// Only count findings on the selected part of the spec
+		var outOfScope []Finding
+		findings, outOfScope = excludeOutOfScope(findings, docs, config.OnlyPaths, config.OnlyTags)
+		report.Excluded = append(report.Excluded, outOfScope...)
+		report.Findings = append(report.Findings, findings...)
+	}
+	r.result.AnalysisDurationMS = time.Since(analysisStarted).Milliseconds()
+	// Taken before the upgrade preview evaluates the specs again
+	if r.client != nil {
+		report.Diagnostics = r.client.Diagnostics()
 	}
 
-	// Process and report results
-	if err := processResults(results, logger); err != nil {
-		logger.Error("Failed to process results", zap.Error(err))
-		return fmt.Errorf("failed to process results: %w", err)
+	// Centrally granted exemptions don't count towards the result
+	if config.HonorExemptions && r.client != nil {
+		honorExemptions(context.Background(), r.client, r.ciContext, report, logger)
 	}
 
-	logger.Info("Governance action completed successfully")
+	// Operations with exempted findings are only partly governed
+	report.Coverage.countExempted(report.Excluded)
 	return nil
 }
 
-// Configuration holds the action configuration
+// correlate adds what the reports show besides the findings: the diff-based
+// modes, the commits that introduced the findings, links and the passed rules
+func (r *actionRun) correlate() {
+	config, report, logger := r.config, r.report, r.logger
+	// Correlate the findings of the diff-based modes with the main analysis
+	if r.upgrade != nil {
+		report.Upgrade = previewUpgrade(config, r.client, r.upgrade, report, logger)
+	}
+	if r.base != nil {
+		report.Diff = r.base.compare(config, r.client, report, logger)
+	}
+
+	// Attribute findings on changed lines to the commits that introduced them
+	if config.Blame {
+		attributeFindings(context.Background(), report.Findings, blameBase(config, r.ciContext), logger)
+	}
+
+	// Link findings to their lines so reviewers can jump straight to them
+	linkFindings(r.ci, r.ciContext, report.Findings)
+	linkFindings(r.ci, r.ciContext, report.Excluded)
+
+	// Report what was checked, not only what failed
+	if config.ReportPassedRules {
+		if report.Rules = summarizeRules(report.Findings, report.Ruleset); report.Rules == nil {
+			logger.Warn("No ruleset metadata to report the passed rules from")
+		}
+	}
+}
+
+// decideVerdict decides the verdict, trend gates included, before anything
+// reports it. The trend gate error is returned for the run to fail with once
+// the results are published.
+func (r *actionRun) decideVerdict() error {
+	config, report, result := r.config, r.report, r.result
+	result.record(config, report)
+	report.Finished = result.FinishedAt
+	result.ReportURL = report.ReportURL
+	trendErr := checkTrend(context.Background(), config, r.ciContext, r.client, report, result, r.logger)
+	if trendErr != nil && result.Verdict == VerdictPass {
+		result.Verdict, result.Reason = VerdictFail, ReasonTrend
+		if config.Mode == ModeAdvisory {
+			result.Verdict = VerdictWarn
+		}
+	}
+	report.Verdict = result.Verdict
+	return trendErr
+}
+
+// publish reports the results to the integrations. Publishing failures
+// degrade the reporting, never the verdict.
+func (r *actionRun) publish() {
+	config, ci, ciContext, report, logger := r.config, r.ci, r.ciContext, r.report, r.logger
+	// Label the pull or merge request
+	if config.ApplyLabels {
+		applyLabels(context.Background(), config, ci, ciContext, report, logger)
+	}
+
+	// Pull in the governance reviewers when errors are found
+	if len(config.Reviewers) > 0 {
+		requestReviewers(context.Background(), config, ci, ciContext, report, logger)
+	}
+
+	// Report the findings as a check run with inline annotations
+	if config.CheckRun && ci == "github" {
+		publishCheckRun(context.Background(), config, ciContext, report, logger)
+	}
+
+	// Notify the owning teams
+	if config.SlackWebhook != "" || len(config.SlackChannels) > 0 {
+		notifySlack(context.Background(), config, ciContext, report, logger)
+	}
+
+	// Comment the report on the pull request
+	if config.PRComment && ci == "github" {
+		publishPRComment(context.Background(), config, ciContext, report, logger)
+	}
+
+	// Discuss the findings on the merge request lines
+	if config.MRDiscussions && ci == "gitlab" {
+		publishMRDiscussions(context.Background(), config, ciContext, report, logger)
+	}
+
+	// Let the deployment of the commit proceed only with a passing verdict
+	if config.DeploymentGate && ci == "github" {
+		publishDeploymentGate(context.Background(), config, ci, ciContext, r.result, logger)
+	}
+	// Report the verdict to the merge request's external status check
+	if config.StatusCheck && ci == "gitlab" {
+		publishStatusCheck(context.Background(), config, ciContext, r.result, logger)
+	}
+}
+
+// persist sets the outputs and writes the reports, failing when the
+// thresholds are exceeded or the reports drifted from their snapshots
+func (r *actionRun) persist(options RunOptions) error {
+	config, report, result, logger := r.config, r.report, r.result, r.logger
+	setReportingOutputs(config, report, logger)
+	setSummaryOutputs(config, report, result)
+	setArtifactOutputs(config, report)
+	if len(result.FileResults) > 0 {
+		setMatrixOutput(config, result.FileResults)
+	}
+	processErr := processResults(config, report, result, logger)
+	// Regression test the rendered reports, e.g. after changing the severity
+	// styles, whatever the verdict
+	if options.SnapshotDir != "" {
+		if err := writeSnapshots(config, report, result, options.SnapshotDir, options.SnapshotCompare); err != nil {
+			logger.Error("Report snapshot check failed", zap.Error(err))
+			return fmt.Errorf("snapshot check failed: %w", err)
+		}
+		if !options.SnapshotCompare {
+			logger.Info("Wrote report snapshots", zap.String("dir", options.SnapshotDir))
+		}
+	}
+	if processErr != nil {
+		logger.Error("Failed to process results", zap.Error(processErr))
+		return fmt.Errorf("failed to process results: %w", processErr)
+	}
+	return nil
+}
+
+// Configuration holds the action configuration, grouped by the stage of the
+// run that uses the settings
 type Configuration struct {
+	ServiceSettings
+	SpecSettings
+	VerdictSettings
+	PublishSettings
+	ReportSettings
+
+	ConfigFile string
+	Local      bool // Ad-hoc run outside the pipeline, publishing nothing
+
+	// overrides are the settings changed after reading the environment, by
+	// setting, e.g. a rule_id from the profile
+	overrides map[string]string
+	// fileDefaults are the settings taken from the config file for lack of
+	// an input
+	fileDefaults map[string]bool
+}
+
+// ServiceSettings configure the governance service and the ruleset it evaluates
+type ServiceSettings struct {
 	GovernanceService string
 	GovernanceAuth    string
 	RuleID            string
-	APIPath           string
+	RulesetVersion    string
 	Mocked            string
+	Region            string
+	OrgID             string
+	TeamID            string
+	PayloadVersion    string
+	CacheDir          string
+	MetadataCacheTTL  time.Duration
+	Retries           int
+	RetryBackoff      time.Duration
+	Timeout           time.Duration
+	RulesetFile       string // Local ruleset evaluated instead of rule_id
+	InlineRuleset     *integrations.InlineRuleset
+	RuleDocsFile      string                // Local rule documentation bundle
+	RuleDocs          *integrations.Ruleset // Rule docs loaded from RuleDocsFile
+	PolicyBundle      string                // URL of the organization policy bundle, or "service"
+	PolicyBundleToken string                // Bearer token for the policy bundle URL
+	OrgPolicy         *OrgPolicy            // Loaded organization policy bundle
+}
+
+// SpecSettings select the specs and the parts of them that are analyzed
+type SpecSettings struct {
+	APIPath           string
+	Manifest          string
+	Discover          bool   // Discover the specs in the checkout without api_path or manifest
+	Document          string // YAML document to analyze, all by default
+	Normalize         bool
+	Bundle            bool // Inline the files referenced by the spec
+	SubstituteEnv     []string
+	MaxSpecSize       int64
+	OnlyPaths         []string // Path globs the analysis is restricted to
+	OnlyTags          []string // Operation tags the analysis is restricted to
+	ExcludeDeprecated bool
+	Prerequisites     *Prerequisites // What specs must declare before they're analyzed
+	Approvals         *Approvals     // How approval annotations on operations are checked
+}
+
+// VerdictSettings decide which findings count and whether the run passes
+type VerdictSettings struct {
+	Mode              string
+	Policies          []BranchPolicy
+	Rulesets          []BranchRuleset
+	Profile           string
+	Profiles          map[string]Profile
+	MaxErrors         *int
+	MaxWarnings       *int
+	UnknownSeverity   string // Severity findings with an unknown severity level count as
+	HonorExemptions   bool
+	DiffBase          string // Revision the specs are compared at, or auto
+	PreviewUpgrade    bool
+	ReportPassedRules bool // Also report the rules that passed
+	Blame             bool
+	BlameBase         string
+	History           string   // URL or path of the run history file, or "service"
+	HistoryToken      string   // Bearer token for the history URL
+	TrendGate         []string // Counts that must not increase over the trend window
+	TrendWindow       time.Duration
+	TrendBranch       string // Branch the trend is tracked on, the default branch by default
+}
+
+// PublishSettings configure where the results are published
+type PublishSettings struct {
+	GitHubToken         string
+	GitLabToken         string
+	ApplyLabels         bool
+	CheckRun            bool
+	PRComment           bool
+	MRDiscussions       bool // Start merge request discussions on the findings
+	ResolveDiscussions  bool // Resolve the discussions of findings no longer reported
+	StepSummary         bool
+	Reviewers           []string
+	SlackWebhook        string            // Global Slack webhook
+	SlackChannels       map[string]string // Slack webhooks by CODEOWNERS owner
+	DeploymentGate      bool              // Gate the deployment of the commit on the verdict
+	DeploymentID        string            // Deployment to set the status of, instead of the event's
+	DeployEnvironment   string            // Environment to create a deployment in outside deployment runs
+	StatusCheck         bool              // Report the verdict to a GitLab external status check
+	StatusCheckName     string            // Name of the external status check to report to
+	DownstreamVariables bool
+	OutputPrefix        string // Prepended to the output names, e.g. per matrix job
+	ArtifactURL         string // Base URL the report files are uploaded to
+}
+
+// ReportSettings configure how the console report and the report files render
+type ReportSettings struct {
+	Console         io.Writer // Receives the console report and workflow commands
+	Locale          string
+	Timezone        *time.Location // Of the report timestamps
+	SeverityStyles  map[string]SeverityStyle
+	RuleLinks       []RuleLink // Style guide pages by rule glob
+	SnippetContext  int        // Lines shown before and after a finding's snippet
+	SnippetMaxLines int        // 0 for no limit
+	SnippetTabWidth int        // 0 keeps tabs
+	SnippetMaxWidth int        // Columns a snippet line is cut to, 0 for no limit
+	OutputFormats   []string   // Report files written besides the console report, e.g. sarif
+	SarifFile       string     // Path of the SARIF report
+	CodeQualityFile string     // Path of the GitLab Code Quality report
+	ReportDir       string     // Directory of the Markdown report
+	ReportPages     string     // What the Markdown report is paged by, file or tag
+	ReportPageSize  int        // Findings per page of the Markdown report
+	JUnitFile       string     // Path of the JUnit report
+	AdmissionKey    string     // Key the admission verdict is signed with
+}
+
+// Variables the core settings are read from, in order of precedence
+var (
+	governanceServiceChain = []string{"INPUT_GOVERNANCE_SERVICE", "GOVERNANCE_SERVICE", "GOVERNANCE_API_URL"}
+	governanceAuthChain    = []string{"INPUT_GOVERNANCE_AUTH", "GOVERNANCE_AUTH", "GOVERNANCE_API_TOKEN"}
+	ruleIDChain            = []string{"INPUT_RULE_ID", "RULE_ID", "GOVERNANCE_RULE_ID"}
+	apiPathChain           = []string{"INPUT_API_PATH", "API_PATH", "OAS_FILE_PATH"}
+	mockedChain            = []string{"INPUT_MOCKED", "MOCKED"}
+)
+
+// lookupChain returns the first non-empty variable of a fallback chain
+func lookupChain(chain []string) string {
+	for _, name := range chain {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// getInput reads an action input, preferring the INPUT_ prefixed variable set by
+// GitHub Actions over the plain variable name
+func getInput(name string) string {
+	recordInput(name)
+	if value := os.Getenv("INPUT_" + name); value != "" {
+		return value
+	}
+	return os.Getenv(name)
+}
+
+// Input reads an action input for flags that can also be set as inputs
+func Input(name string) string {
+	return getInput(name)
+}
+
+// SetInput sets an action input from the command line flag named by source,
+// taking precedence over the INPUT_ and plain variables
+func SetInput(name, value, source string) error {
+	recordInputFlag(name, source)
+	return os.Setenv("INPUT_"+name, value)
 }
 
 // getConfiguration retrieves configuration from environment variables
 func getConfiguration() (*Configuration, error) {
+	// The core settings fall back through GitHub inputs, plain and GitLab
+	// variable names
 	config := &Configuration{
-		GovernanceService: os.Getenv("INPUT_GOVERNANCE_SERVICE"),
-		GovernanceAuth:    os.Getenv("INPUT_GOVERNANCE_AUTH"),
-		RuleID:            os.Getenv("INPUT_RULE_ID"),
-		APIPath:           os.Getenv("INPUT_API_PATH"),
-		Mocked:            os.Getenv("INPUT_MOCKED"),
+		ServiceSettings: ServiceSettings{
+			GovernanceService: lookupChain(governanceServiceChain),
+			GovernanceAuth:    lookupChain(governanceAuthChain),
+			RuleID:            lookupChain(ruleIDChain),
+			Mocked:            lookupChain(mockedChain),
+		},
+		SpecSettings: SpecSettings{APIPath: lookupChain(apiPathChain)},
 	}
 
-	// Fallback to direct environment variables if INPUT_ prefixed ones are not set
-	if config.GovernanceService == "" {
-		config.GovernanceService = os.Getenv("GOVERNANCE_SERVICE")
+	config.Manifest = getInput("MANIFEST")
+	config.Discover = getInput("DISCOVER") == "true"
+	config.ConfigFile = getInput("CONFIG_FILE")
+	config.Mode = getInput("MODE")
+	config.Region = getInput("REGION")
+	config.PayloadVersion = getInput("PAYLOAD_VERSION")
+	if config.PayloadVersion == "" {
+		config.PayloadVersion = integrations.PayloadAuto
 	}
-	if config.GovernanceAuth == "" {
-		config.GovernanceAuth = os.Getenv("GOVERNANCE_AUTH")
+	config.ApplyLabels = getInput("APPLY_LABELS") == "true"
+	config.CheckRun = getInput("CHECK_RUN") == "true"
+	config.PRComment = getInput("PR_COMMENT") == "true"
+	config.DeploymentGate = getInput("DEPLOYMENT_GATE") == "true"
+	config.DeploymentID = getInput("DEPLOYMENT_ID")
+	config.DeployEnvironment = getInput("DEPLOYMENT_ENVIRONMENT")
+	config.AdmissionKey = getInput("ADMISSION_KEY")
+	config.StatusCheck = getInput("STATUS_CHECK") == "true"
+	if config.StatusCheckName = getInput("STATUS_CHECK_NAME"); config.StatusCheckName == "" {
+		config.StatusCheckName = defaultStatusCheckName
 	}
-	if config.RuleID == "" {
-		config.RuleID = os.Getenv("RULE_ID")
+	config.MRDiscussions = getInput("MR_DISCUSSIONS") == "true"
+	config.ResolveDiscussions = getInput("RESOLVE_STALE_DISCUSSIONS") == "true"
+	config.StepSummary = getInput("STEP_SUMMARY") != "false"
+	config.SlackWebhook = getInput("SLACK_WEBHOOK")
+	config.ExcludeDeprecated = getInput("EXCLUDE_DEPRECATED") == "true"
+	config.DownstreamVariables = getInput("DOWNSTREAM_VARIABLES") == "true"
+	config.GitHubToken = getInput("GITHUB_TOKEN")
+	config.GitLabToken = getInput("GITLAB_TOKEN")
+	config.Reviewers = splitList(getInput("REVIEWERS"))
+	config.SubstituteEnv = splitList(getInput("SUBSTITUTE_ENV"))
+	config.Blame = getInput("BLAME") == "true"
+	config.BlameBase = getInput("BLAME_BASE")
+	config.DiffBase = getInput("DIFF_BASE")
+	config.Profile = getInput("PROFILE")
+	config.RulesetVersion = getInput("RULESET_VERSION")
+	config.PreviewUpgrade = getInput("PREVIEW_UPGRADE") == "true"
+	config.ArtifactURL = getInput("ARTIFACT_URL")
+	config.Bundle = getInput("BUNDLE") == "true"
+	config.PolicyBundle = getInput("POLICY_BUNDLE")
+	config.PolicyBundleToken = getInput("POLICY_BUNDLE_TOKEN")
+	config.ReportPassedRules = getInput("REPORT_PASSED_RULES") == "true"
+	config.RulesetFile = getInput("RULESET_FILE")
+	config.RuleDocsFile = getInput("RULE_DOCS")
+	config.History = getInput("HISTORY")
+	config.HistoryToken = getInput("HISTORY_TOKEN")
+	config.TrendGate = splitList(getInput("TREND_GATE"))
+	config.TrendBranch = getInput("TREND_BRANCH")
+	config.OutputFormats = splitList(getInput("OUTPUT_FORMAT"))
+	if config.SarifFile = getInput("SARIF_FILE"); config.SarifFile == "" {
+		config.SarifFile = defaultSarifFile
 	}
-	if config.APIPath == "" {
-		config.APIPath = os.Getenv("API_PATH")
+	if config.CodeQualityFile = getInput("CODE_QUALITY_FILE"); config.CodeQualityFile == "" {
+		config.CodeQualityFile = defaultCodeQualityFile
 	}
-	if config.Mocked == "" {
-		config.Mocked = os.Getenv("MOCKED")
+	if config.JUnitFile = getInput("JUNIT_FILE"); config.JUnitFile == "" {
+		config.JUnitFile = defaultJUnitFile
+	}
+	if config.ReportDir = getInput("REPORT_DIR"); config.ReportDir == "" {
+		config.ReportDir = defaultReportDir
+	}
+	if config.ReportPages = getInput("REPORT_PAGES"); config.ReportPages == "" {
+		config.ReportPages = pagesByFile
+	}
+	if config.UnknownSeverity = getInput("UNKNOWN_SEVERITY"); config.UnknownSeverity == "" {
+		config.UnknownSeverity = severityWarning
+	}
+	config.OutputPrefix = getInput("OUTPUT_PREFIX")
+	config.HonorExemptions = getInput("HONOR_EXEMPTIONS") != "false"
+	config.OrgID = getInput("ORG_ID")
+	config.TeamID = getInput("TEAM_ID")
+	config.Document = getInput("DOCUMENT")
+	config.Normalize = getInput("NORMALIZE") == "true"
+	config.OnlyPaths = splitList(getInput("ONLY_PATH"))
+	config.OnlyTags = splitList(getInput("ONLY_TAG"))
+	if config.Locale = getInput("LOCALE"); config.Locale == "" {
+		config.Locale = defaultLocale
 	}
 
-	// GitLab CI specific fallbacks
-	if config.GovernanceService == "" {
-		config.GovernanceService = os.Getenv("GOVERNANCE_API_URL")
+	var err error
+	if config.CacheDir = getInput("CACHE_DIR"); config.CacheDir == "" {
+		config.CacheDir = defaultCacheDir()
+	}
+	if config.MaxSpecSize, err = getSizeInput("MAX_SPEC_SIZE", defaultMaxSpecSize); err != nil {
+		return nil, err
+	}
+	if config.MetadataCacheTTL, err = getDurationInput("METADATA_CACHE_TTL", time.Hour); err != nil {
+		return nil, err
+	}
+	if config.Timezone, err = time.LoadLocation(getInput("TIMEZONE")); err != nil {
+		return nil, fmt.Errorf("timezone: %w", err)
+	}
+	retries, err := getIntInput("RETRIES")
+	if err != nil {
+		return nil, err
 	}
-	if config.GovernanceAuth == "" {
-		config.GovernanceAuth = os.Getenv("GOVERNANCE_API_TOKEN")
+	config.Retries = defaultRetries
+	if retries != nil {
+		config.Retries = *retries
 	}
-	if config.RuleID == "" {
-		config.RuleID = os.Getenv("GOVERNANCE_RULE_ID")
+	if config.TrendWindow, err = getDurationInput("TREND_WINDOW", defaultTrendWindow); err != nil {
+		return nil, err
+	}
+	if config.RulesetFile != "" {
+		if config.InlineRuleset, err = integrations.LoadInlineRuleset(config.RulesetFile); err != nil {
+			return nil, err
+		}
+	}
+	if config.RuleDocsFile != "" {
+		if config.RuleDocs, err = integrations.LoadRuleDocs(config.RuleDocsFile); err != nil {
+			return nil, err
+		}
+	}
+	if config.RetryBackoff, err = getDurationInput("RETRY_BACKOFF", time.Second); err != nil {
+		return nil, err
+	}
+	if config.Timeout, err = getDurationInput("TIMEOUT", defaultTimeout); err != nil {
+		return nil, err
+	}
+	if config.SnippetContext, err = getIntInputOr("SNIPPET_CONTEXT", 0); err != nil {
+		return nil, err
+	}
+	if config.SnippetMaxLines, err = getIntInputOr("SNIPPET_MAX_LINES", defaultSnippetMaxLines); err != nil {
+		return nil, err
+	}
+	if config.SnippetTabWidth, err = getIntInputOr("SNIPPET_TAB_WIDTH", defaultSnippetTabWidth); err != nil {
+		return nil, err
+	}
+	if config.SnippetMaxWidth, err = getIntInputOr("SNIPPET_MAX_WIDTH", defaultSnippetMaxWidth); err != nil {
+		return nil, err
+	}
+	if config.ReportPageSize, err = getIntInputOr("REPORT_PAGE_SIZE", defaultReportPageSize); err != nil {
+		return nil, err
+	}
+	if config.MaxErrors, err = getIntInput("MAX_ERRORS"); err != nil {
+		return nil, err
+	}
+	if config.MaxWarnings, err = getIntInput("MAX_WARNINGS"); err != nil {
+		return nil, err
+	}
+
+	// Repository configuration file
+	fileConfig, err := loadFileConfig(config.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	config.Policies = fileConfig.Policies
+	config.Rulesets = fileConfig.Rulesets
+	config.Profiles = fileConfig.Profiles
+	config.SeverityStyles = fileConfig.Severities
+	config.SlackChannels = fileConfig.SlackChannels
+	config.RuleLinks = fileConfig.RuleLinks
+	config.Prerequisites = fileConfig.Prerequisites
+	config.Approvals = fileConfig.Approvals
+	config.applyFileDefaults(fileConfig)
+	if config.OutputPrefix == outputPrefixAuto {
+		spec := config.APIPath
+		if spec == "" {
+			spec = config.Manifest
+		}
+		config.OutputPrefix = specOutputPrefix(spec)
 	}
-	if config.APIPath == "" {
-		config.APIPath = os.Getenv("OAS_FILE_PATH")
+
+	// Resolve the governance service from the data residency region
+	if config.Region != "" {
+		config.GovernanceService, err = resolveRegionEndpoint(config.Region, config.GovernanceService, fileConfig.Regions)
+		if err != nil {
+			return nil, err
+		}
+		config.override("governance_service", "region "+config.Region+" of the config file")
 	}
 
 	return config, nil
@@ -131,17 +755,103 @@ func getConfiguration() (*Configuration, error) {
 
 // Validate checks if the configuration is valid
 func (c *Configuration) Validate() error {
+	if c.Mode != "" {
+		if err := validateMode(c.Mode); err != nil {
+			return err
+		}
+	}
+	for _, policy := range c.Policies {
+		if policy.Branch == "" && policy.Event == "" {
+			return fmt.Errorf("policies: branch or event is required")
+		}
+		if policy.Event != "" && !slices.Contains(integrations.EventTypes, policy.Event) {
+			return fmt.Errorf("policies: unknown event %s, must be one of: %s", policy.Event, strings.Join(integrations.EventTypes, ", "))
+		}
+		if err := validateMode(policy.Mode); err != nil {
+			return fmt.Errorf("policies: branch %s: %w", policy.Branch, err)
+		}
+	}
+	for _, ruleset := range c.Rulesets {
+		if ruleset.Branch == "" {
+			return fmt.Errorf("rulesets: branch is required")
+		}
+		if ruleset.RuleID == "" {
+			return fmt.Errorf("rulesets: branch %s: rule_id is required", ruleset.Branch)
+		}
+	}
+	if err := validateSeverityStyles(c.SeverityStyles); err != nil {
+		return err
+	}
+	if err := validateDocumentSelector(c.Document); err != nil {
+		return err
+	}
+	if err := validateLocale(c.Locale); err != nil {
+		return err
+	}
+	if err := c.Approvals.validate(); err != nil {
+		return err
+	}
+	if c.TeamID != "" && c.OrgID == "" {
+		return fmt.Errorf("team_id requires org_id")
+	}
+	if c.RulesetFile != "" && c.RulesetVersion != "" {
+		return fmt.Errorf("ruleset_version can't be combined with ruleset_file")
+	}
+	if c.PreviewUpgrade && c.RulesetVersion == "" {
+		return fmt.Errorf("preview_upgrade requires ruleset_version to compare against")
+	}
+	for _, metric := range c.TrendGate {
+		if !slices.Contains(trendMetrics, metric) {
+			return fmt.Errorf("trend_gate: unknown count %s, must be one of: %s", metric, strings.Join(trendMetrics, ", "))
+		}
+	}
+	if _, ok := severityLevels[c.UnknownSeverity]; !ok {
+		return fmt.Errorf("unknown_severity must be one of: %s, %s, %s", severityError, severityWarning, severityInfo)
+	}
+	for _, entry := range c.OutputFormats {
+		if _, _, err := parseOutputFormat(entry); err != nil {
+			return err
+		}
+	}
+	if !slices.Contains(reportPagings, c.ReportPages) {
+		return fmt.Errorf("report_pages must be one of: %s", strings.Join(reportPagings, ", "))
+	}
+	if c.ReportPageSize <= 0 {
+		return fmt.Errorf("report_page_size must be positive")
+	}
+	if len(c.TrendGate) > 0 && c.History == "" {
+		return fmt.Errorf("trend_gate requires history to compare against")
+	}
+	if c.TrendWindow <= 0 {
+		return fmt.Errorf("trend_window must be positive")
+	}
+	if !validOutputPrefix.MatchString(c.OutputPrefix) {
+		return fmt.Errorf("output_prefix may only contain letters, digits and underscores")
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+	if c.SnippetContext < 0 || c.SnippetMaxLines < 0 || c.SnippetTabWidth < 0 || c.SnippetMaxWidth < 0 {
+		return fmt.Errorf("snippet_context, snippet_max_lines, snippet_tab_width and snippet_max_width must not be negative")
+	}
+	if !integrations.ValidPayloadVersion(c.PayloadVersion) {
+		return fmt.Errorf("payload_version must be one of: auto, v1, v2")
+	}
+
 	// If mocked mode is enabled, validate the mocked value
 	if c.Mocked != "" {
 		if c.Mocked != "success" && c.Mocked != "fail" && c.Mocked != "warning" {
 			return fmt.Errorf("mocked must be one of: success, fail, warning")
 		}
 		// In mocked mode, governance service and auth are not required
-		if c.RuleID == "" {
-			return fmt.Errorf("rule_id is required")
+		if c.RuleID == "" && c.RulesetFile == "" {
+			return fmt.Errorf("rule_id or ruleset_file is required")
 		}
-		if c.APIPath == "" {
-			return fmt.Errorf("api_path is required")
+		if c.APIPath == "" && c.Manifest == "" && !c.Discover {
+			return fmt.Errorf("api_path, manifest or discover is required")
 		}
 		return nil
 	}
@@ -153,17 +863,103 @@ func (c *Configuration) Validate() error {
 	if c.GovernanceAuth == "" {
 		return fmt.Errorf("governance_auth is required")
 	}
-	if c.RuleID == "" {
-		return fmt.Errorf("rule_id is required")
+	if c.RuleID == "" && c.RulesetFile == "" {
+		return fmt.Errorf("rule_id or ruleset_file is required")
 	}
-	if c.APIPath == "" {
-		return fmt.Errorf("api_path is required")
+	if c.APIPath == "" && c.Manifest == "" && !c.Discover {
+		return fmt.Errorf("api_path, manifest or discover is required")
 	}
 	return nil
 }
 
-// readOASFile reads the OAS file from the specified path
-func readOASFile(path string) (string, error) {
+// getIntInput reads an optional integer input, returning nil when it's not set
+func getIntInput(name string) (*int, error) {
+	value := getInput(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer: %w", strings.ToLower(name), err)
+	}
+	return &n, nil
+}
+
+// getIntInputOr reads an optional integer input, falling back when it's not set
+func getIntInputOr(name string, fallback int) (int, error) {
+	n, err := getIntInput(name)
+	if err != nil || n == nil {
+		return fallback, err
+	}
+	return *n, nil
+}
+
+// getDurationInput reads an optional duration input such as "30m"
+func getDurationInput(name string, fallback time.Duration) (time.Duration, error) {
+	value := getInput(name)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := parseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration such as 30m or 7d: %w", strings.ToLower(name), err)
+	}
+	return d, nil
+}
+
+// parseDuration parses a duration such as 30m, also accepting whole days such as 7d
+func parseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
+
+// getSizeInput reads a byte size input such as 50MB, using fallback when it's unset
+func getSizeInput(name string, fallback int64) (int64, error) {
+	value := getInput(name)
+	if value == "" {
+		return fallback, nil
+	}
+	size, err := parseByteSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a size such as 50MB: %w", strings.ToLower(name), err)
+	}
+	return size, nil
+}
+
+// defaultCacheDir returns the user cache directory for the action
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "governance-action")
+}
+
+// splitList splits a comma-separated input into its trimmed, non-empty values
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// readOASFile reads the OAS file from the specified path, refusing files larger
+// than maxSize bytes unless it's 0
+func readOASFile(path string, maxSize int64) (string, error) {
+	if _, _, ok := parseGitSpec(path); ok {
+		return readGitSpec(path, maxSize)
+	}
+	if err := checkSpecPath(path); err != nil {
+		return "", err
+	}
+
 	// Resolve relative paths
 	if !filepath.IsAbs(path) {
 		absPath, err := filepath.Abs(path)
@@ -173,6 +969,18 @@ func readOASFile(path string) (string, error) {
 		path = absPath
 	}
 
+	// Check the size before reading so a huge spec can't exhaust memory
+	if maxSize > 0 {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+		if info.Size() > maxSize {
+			return "", fmt.Errorf("file %s is %s, larger than the max_spec_size of %s; split the spec into smaller files or raise max_spec_size",
+				path, formatByteSize(info.Size()), formatByteSize(maxSize))
+		}
+	}
+
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file %s: %w", path, err)
@@ -181,8 +989,9 @@ func readOASFile(path string) (string, error) {
 	return string(content), nil
 }
 
-// generateMockResults creates predefined governance analysis results for testing
-func generateMockResults(mockedType string, ruleID string) []integrations.LintResult {
+// generateMockResults creates predefined governance analysis results for testing,
+// reported for the spec with the given repository path
+func generateMockResults(mockedType string, ruleID string, source string) []integrations.LintResult {
 	switch mockedType {
 	case "success":
 		// Return empty results for success
@@ -200,7 +1009,7 @@ func generateMockResults(mockedType string, ruleID string) []integrations.LintRe
 					Start: integrations.LintLocation{Line: 10, Character: 5},
 					End:   integrations.LintLocation{Line: 10, Character: 15},
 				},
-				Source: "mock-source",
+				Source: source,
 				API: integrations.APIReference{
 					ID:   "mock-api-id",
 					Name: "Mock API",
@@ -218,7 +1027,7 @@ func generateMockResults(mockedType string, ruleID string) []integrations.LintRe
 					Start: integrations.LintLocation{Line: 8, Character: 3},
 					End:   integrations.LintLocation{Line: 8, Character: 12},
 				},
-				Source: "mock-source",
+				Source: source,
 				API: integrations.APIReference{
 					ID:   "mock-api-id",
 					Name: "Mock API",
@@ -241,7 +1050,7 @@ func generateMockResults(mockedType string, ruleID string) []integrations.LintRe
 					Start: integrations.LintLocation{Line: 10, Character: 5},
 					End:   integrations.LintLocation{Line: 10, Character: 15},
 				},
-				Source: "mock-source",
+				Source: source,
 				API: integrations.APIReference{
 					ID:   "mock-api-id",
 					Name: "Mock API",
@@ -259,7 +1068,7 @@ func generateMockResults(mockedType string, ruleID string) []integrations.LintRe
 					Start: integrations.LintLocation{Line: 12, Character: 7},
 					End:   integrations.LintLocation{Line: 12, Character: 10},
 				},
-				Source: "mock-source",
+				Source: source,
 				API: integrations.APIReference{
 					ID:   "mock-api-id",
 					Name: "Mock API",
@@ -277,7 +1086,7 @@ func generateMockResults(mockedType string, ruleID string) []integrations.LintRe
 					Start: integrations.LintLocation{Line: 8, Character: 3},
 					End:   integrations.LintLocation{Line: 8, Character: 12},
 				},
-				Source: "mock-source",
+				Source: source,
 				API: integrations.APIReference{
 					ID:   "mock-api-id",
 					Name: "Mock API",
@@ -293,82 +1102,260 @@ func generateMockResults(mockedType string, ruleID string) []integrations.LintRe
 	}
 }
 
+// analysisReport collects everything the reporters need about a run
+type analysisReport struct {
+	Files    []string // Analyzed spec files, in analysis order
+	Findings []Finding
+	Excluded []Finding // Findings that don't count towards the result
+	Ruleset  *integrations.Ruleset
+	Stats    *SpecStats
+	Upgrade  *findingDelta // Findings delta of the ruleset upgrade preview
+	Diff     *findingDelta // Findings delta since the diff_base revision
+	DiffBase string        // Revision the diff is against
+	Rules    *ruleSummary  // Passed and failed rules, with report_passed_rules
+	Coverage *operationCoverage
+	// Approvals are the approvals annotated on the operations
+	Approvals []operationApproval
+	// Diagnostics are the evaluation times the service reported, if any
+	Diagnostics *integrations.Diagnostics
+	Started     time.Time // Run start, in the configured timezone
+	Finished    time.Time // When the verdict was reached
+	// Verdict is pass, warn or fail, trend gates included, decided before the
+	// results are published
+	Verdict string
+	// ReportURL links to the full report, ArtifactURLs to each report file
+	ReportURL    string
+	ArtifactURLs map[string]string
+	// ReportingErrors are the integrations that failed to publish the results
+	ReportingErrors []reportingError
+}
+
 // processResults handles the analysis results and determines success/failure
-func processResults(results []integrations.LintResult, logger *zap.Logger) error {
-	if len(results) == 0 {
-		logger.Info("No governance issues found")
-		return nil
+func processResults(config *Configuration, report *analysisReport, result *RunResult, logger *zap.Logger) error {
+	findings := report.Findings
+	errorCount, warningCount := countSeverities(findings)
+
+	// Set output variables for the CI platform
+	setOutput(config, "error_count", fmt.Sprintf("%d", errorCount))
+	setOutput(config, "warning_count", fmt.Sprintf("%d", warningCount))
+	setOutput(config, "total_issues", fmt.Sprintf("%d", len(findings)))
+	setOutput(config, "excluded_count", fmt.Sprintf("%d", len(report.Excluded)))
+	if len(report.Excluded) > 0 {
+		logger.Info("Excluded findings", zap.Int("excluded_count", len(report.Excluded)))
+	}
+	if stats := report.Stats; stats != nil {
+		setOutput(config, "path_count", fmt.Sprintf("%d", stats.Paths))
+		setOutput(config, "operation_count", fmt.Sprintf("%d", stats.Operations))
+		setOutput(config, "schema_count", fmt.Sprintf("%d", stats.Schemas))
+		setOutput(config, "security_scheme_count", fmt.Sprintf("%d", stats.SecuritySchemes))
+		setOutput(config, "webhook_count", fmt.Sprintf("%d", stats.Webhooks))
+		logger.Info("Spec statistics",
+			zap.Int("paths", stats.Paths), zap.Int("operations", stats.Operations),
+			zap.Int("schemas", stats.Schemas), zap.Int("security_schemes", stats.SecuritySchemes),
+			zap.Int("webhooks", stats.Webhooks))
+	}
+	if report.Rules != nil {
+		setRuleOutputs(config, report.Rules)
+	}
+	if report.Coverage != nil {
+		setCoverageOutputs(config, report.Coverage)
+	}
+	if diagnostics := report.Diagnostics; diagnostics != nil {
+		setOutput(config, "evaluation_time_ms", fmt.Sprintf("%d", diagnostics.EvaluationTime.Milliseconds()))
+		if slowest := slowestRules(diagnostics, 1); len(slowest) > 0 {
+			logger.Info("Governance service evaluation", zap.Duration("evaluation_time", diagnostics.EvaluationTime),
+				zap.String("slowest_rule", slowest[0].Rule), zap.Duration("slowest_rule_time", slowest[0].Duration))
+		}
+	}
+
+	if len(findings) == 0 {
+		logger.Info("No governance issues found", zap.String("started_at", formatTimestamp(report.Started)),
+			zap.String("finished_at", formatTimestamp(report.Finished)))
+	}
+
+	// Write the reports, e.g. for code scanning, and print the console report
+	if err := writeReports(config, report, result, logger); err != nil {
+		return err
+	}
+
+	// Show the report on the workflow run page
+	if config.StepSummary && os.Getenv("GITHUB_STEP_SUMMARY") != "" {
+		if err := writeStepSummary(config, report); err != nil {
+			logger.Warn("Failed to write the step summary", zap.Error(err))
+		}
+	}
+
+	// Annotate the findings on the changed files, unless the check run does
+	if os.Getenv("GITHUB_ACTIONS") == "true" && !config.CheckRun && !config.Local {
+		printWorkflowAnnotations(config.Console, findings)
+	}
+
+	// Fail if the thresholds are exceeded, unless the branch is only advised
+	if err := checkThresholds(config, errorCount, warningCount); err != nil {
+		if config.Mode == ModeAdvisory {
+			logger.Warn("Governance thresholds exceeded, not failing in advisory mode", zap.Error(err))
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// printReport prints the console report of the findings, or only the
+// summary sections when there are none
+func printReport(w io.Writer, config *Configuration, report *analysisReport) {
+	findings := report.Findings
+	if len(findings) == 0 {
+		printCoverage(w, config, report.Coverage)
+		printRuleSummary(w, config, report.Rules)
+		printDiagnostics(w, config, report.Diagnostics)
+		printExcludedFindings(w, config, report.Excluded)
+		printApprovals(w, config, report.Approvals)
+		if report.Upgrade != nil {
+			printFindingDelta(w, config, config.text("report.upgrade", config.RulesetVersion), report.Upgrade)
+		}
+		if report.Diff != nil {
+			printFindingDelta(w, config, config.text("report.diff", report.DiffBase), report.Diff)
+		}
+		printReportingErrors(w, config, report.ReportingErrors)
+		return
 	}
 
-	// Read OAS file lines for snippet printing
-	oasLines := []string{}
-	apiPath := os.Getenv("INPUT_API_PATH")
-	if apiPath == "" {
-		apiPath = os.Getenv("API_PATH")
+	// Read OAS file lines for snippet printing. The whole file is read, as
+	// single-line JSON specs exceed any line buffer.
+	fileLines := map[string][]string{}
+	// Minified JSON specs are shown formatted, as their findings are all on line 1
+	jsonViews := map[string]*jsonView{}
+	files := slices.Clone(report.Files)
+	for _, finding := range findings {
+		// Findings in bundled files are shown in the file the author edited
+		if !slices.Contains(files, finding.File) {
+			files = append(files, finding.File)
+		}
 	}
-	if apiPath != "" {
-		if file, err := os.Open(apiPath); err == nil {
-			scanner := bufio.NewScanner(file)
-			for scanner.Scan() {
-				oasLines = append(oasLines, scanner.Text())
+	for _, path := range files {
+		if content, err := readSpecFile(path); err == nil {
+			fileLines[path] = strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+			if view := minifiedJSON(string(content), config.SnippetMaxWidth); view != nil {
+				jsonViews[path] = view
 			}
-			file.Close()
 		}
 	}
 
-	fmt.Println("\n================ Governance Analysis Report ================")
-	errorCount := 0
-	warningCount := 0
-	for _, result := range results {
-		sev := "INFO"
-		icon := "ℹ️"
-		switch result.Severity {
-		case 0:
-			sev = "ERROR"
-			icon = "❌"
-			errorCount++
-		case 1:
-			sev = "WARNING"
-			icon = "⚠️"
-			warningCount++
+	fmt.Fprintf(w, "\n================ %s ================\n", config.text("report.title"))
+	fmt.Fprintln(w, config.text("report.run_times", formatTimestamp(report.Started), formatTimestamp(report.Finished)))
+	if len(report.Files) > 1 {
+		// Show which specs block the pipeline before the individual findings
+		printFileMatrix(w, config, fileMatrix(config, report))
+	}
+	// Findings in files bundled into a spec are also grouped by file
+	grouped := len(report.Files) > 1 || slices.ContainsFunc(findings, func(f Finding) bool { return f.Spec != "" })
+	currentFile := ""
+	for _, result := range findings {
+		// Group findings under a heading per spec file in multi-spec runs
+		if grouped && result.File != currentFile {
+			currentFile = result.File
+			fmt.Fprintf(w, "📄 %s\n", currentFile)
 		}
+
+		style := config.severityStyle(result.Severity)
 		path := strings.Join(result.Path, ".")
-		fmt.Printf("%s [%s] [%s] %s\n    %s\n    Location: line %d, char %d - line %d, char %d\n",
-			icon, sev, path, result.Rule.Name, result.Message,
-			result.Range.Start.Line, result.Range.Start.Character,
-			result.Range.End.Line, result.Range.End.Character)
+		fmt.Fprintf(w, "%s [%s] [%s] %s\n    %s\n    %s\n",
+			style.Icon, style.colorize(style.Label), path, result.Rule.Name, result.Message,
+			config.text("report.location", result.Range.Start.Line, result.Range.Start.Character,
+				result.Range.End.Line, result.Range.End.Character))
+		if rule := report.Ruleset.Rule(result.Rule.Name); rule != nil && rule.Remediation != "" {
+			fmt.Fprintln(w, "    "+config.text("report.remediation", rule.Remediation))
+		}
+		if link := config.ruleLink(result.Rule.Name); link != "" {
+			fmt.Fprintln(w, "    "+config.text("report.rule_link", link))
+		}
 
 		// Print OAS snippet if available
-		if len(oasLines) > 0 && int(result.Range.Start.Line) > 0 && int(result.Range.End.Line) <= len(oasLines) {
-			fmt.Println("    --- OAS snippet ---")
-			for i := int(result.Range.Start.Line) - 1; i < int(result.Range.End.Line) && i < len(oasLines); i++ {
-				fmt.Printf("    %4d | %s\n", i+1, oasLines[i])
-			}
-			fmt.Println("    -------------------")
+		if view := jsonViews[result.File]; view != nil {
+			formatted := view.mapRange(result.Range)
+			fmt.Fprintln(w, "    "+config.text("report.formatted_location", formatted.Start.Line, formatted.Start.Character,
+				formatted.End.Line, formatted.End.Character))
+			printSnippet(w, config, view.lines, formatted)
+		} else {
+			printSnippet(w, config, fileLines[result.File], result.Range)
 		}
-	}
-	fmt.Println("===========================================================\n")
 
-	// Set output variables for GitHub Actions
-	if os.Getenv("GITHUB_ACTIONS") == "true" {
-		setGitHubOutput("error_count", fmt.Sprintf("%d", errorCount))
-		setGitHubOutput("warning_count", fmt.Sprintf("%d", warningCount))
-		setGitHubOutput("total_issues", fmt.Sprintf("%d", len(results)))
+		fmt.Fprintln(w, "    "+config.text("report.fingerprint", result.Fingerprint()))
+		if result.Permalink != "" {
+			fmt.Fprintln(w, "    "+config.text("report.permalink", result.Permalink))
+		}
+		if blame := result.Blame; blame != nil {
+			fmt.Fprintln(w, "    "+config.text("report.introduced_by", blame.Commit, blame.Author, blame.Email, blame.Summary))
+		}
+		if !result.FirstSeen.IsZero() {
+			fmt.Fprintln(w, "    "+config.text("report.first_seen", formatTimestamp(result.FirstSeen.In(config.Timezone))))
+		}
+	}
+	printFrameworkRollup(w, config, frameworkRollup(findings, report.Ruleset))
+	printTagRollup(w, config, tagRollup(findings))
+	if stats := report.Stats; stats != nil {
+		printHeading(w, config.text("report.statistics"))
+		fmt.Fprintln(w, "    "+config.text("report.statistics_line",
+			stats.Paths, stats.Operations, stats.Schemas, stats.SecuritySchemes))
+	}
+	printCoverage(w, config, report.Coverage)
+	printRuleSummary(w, config, report.Rules)
+	printDiagnostics(w, config, report.Diagnostics)
+	printExcludedFindings(w, config, report.Excluded)
+	printApprovals(w, config, report.Approvals)
+	if report.Upgrade != nil {
+		printFindingDelta(w, config, config.text("report.upgrade", config.RulesetVersion), report.Upgrade)
 	}
+	if report.Diff != nil {
+		printFindingDelta(w, config, config.text("report.diff", report.DiffBase), report.Diff)
+	}
+	printReportingErrors(w, config, report.ReportingErrors)
+	fmt.Fprintln(w, "===========================================================")
+	fmt.Fprintln(w)
+}
 
-	// Set output variables for GitLab CI
-	if os.Getenv("GITLAB_CI") == "true" {
-		setGitLabOutput("error_count", fmt.Sprintf("%d", errorCount))
-		setGitLabOutput("warning_count", fmt.Sprintf("%d", warningCount))
-		setGitLabOutput("total_issues", fmt.Sprintf("%d", len(results)))
+// printExcludedFindings prints the findings that don't count towards the result
+// and why, e.g. because they were exempted
+func printExcludedFindings(w io.Writer, config *Configuration, excluded []Finding) {
+	if len(excluded) == 0 {
+		return
 	}
+	printHeading(w, config.text("report.excluded"))
+	for _, finding := range excluded {
+		fmt.Fprintf(w, "    [%s] %s (%s, %s)\n", strings.Join(finding.Path, "."), finding.Rule.Name, finding.Fingerprint(), finding.ExclusionReason)
+	}
+}
 
-	// Fail if there are errors
-	if errorCount > 0 {
-		return fmt.Errorf("governance analysis failed with %d errors and %d warnings", errorCount, warningCount)
+// countSeverities counts the error and warning findings
+func countSeverities(findings []Finding) (errorCount, warningCount int) {
+	for _, result := range findings {
+		switch result.Severity {
+		case 0:
+			errorCount++
+		case 1:
+			warningCount++
+		}
 	}
+	return errorCount, warningCount
+}
 
-	return nil
+// setOutput sets an output variable for the detected CI platform
+func setOutput(config *Configuration, name, value string) {
+	if config.Local {
+		return
+	}
+	name = config.OutputPrefix + name
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		setGitHubOutput(name, value)
+	}
+	if os.Getenv("GITLAB_CI") == "true" {
+		setGitLabOutput(name, value)
+		if config.DownstreamVariables {
+			setGitLabOutput(downstreamVariable(name), value)
+		}
+	}
 }
 
 // setGitHubOutput sets a GitHub Actions output variable
@@ -382,21 +1369,45 @@ func setGitHubOutput(name, value string) {
 	}
 }
 
-// setGitLabOutput sets a GitLab CI output variable
+// setGitLabOutput sets a GitLab CI output variable in the dotenv file GitLab
+// accepts as an `artifacts:reports:dotenv` report. Runs for several specs can
+// share the file as long as they write distinct names, e.g. with output_prefix.
 func setGitLabOutput(name, value string) {
-	// GitLab CI uses environment variables for outputs
-	// We can also write to a file that can be sourced in subsequent jobs
-	outputFile := os.Getenv("GITLAB_OUTPUT_FILE")
-	if outputFile == "" {
-		outputFile = "governance_output.env"
+	if err := writeDotenv(gitLabOutputFile(), name, value); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write output %s: %v\n", name, err)
 	}
+}
+
+// outputPrefixAuto derives the output prefix from the analyzed spec
+const outputPrefixAuto = "auto"
+
+// validOutputPrefix matches prefixes that are valid in output and variable names
+var validOutputPrefix = regexp.MustCompile(`^[A-Za-z0-9_]*$`)
+
+// outputNameSeparators are replaced by underscores in derived output prefixes
+var outputNameSeparators = regexp.MustCompile(`[^A-Za-z0-9]+`)
 
-	f, err := os.OpenFile(outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err == nil {
-		defer f.Close()
-		fmt.Fprintf(f, "export %s=%s\n", name, value)
+// specOutputPrefix derives an output prefix from a spec path, e.g.
+// apis_users_openapi_ for apis/users/openapi.yaml
+func specOutputPrefix(path string) string {
+	path = strings.TrimSuffix(repoPath(path), filepath.Ext(path))
+	prefix := strings.Trim(outputNameSeparators.ReplaceAllString(path, "_"), "_")
+	if prefix == "" {
+		return ""
 	}
+	return strings.ToLower(prefix) + "_"
+}
+
+// gitLabOutputFile returns the path of the GitLab dotenv output file
+func gitLabOutputFile() string {
+	if outputFile := getInput("GITLAB_OUTPUT_FILE"); outputFile != "" {
+		return outputFile
+	}
+	return "governance_output.env"
+}
 
-	// Also set as environment variable for current job
-	os.Setenv(name, value)
+// downstreamVariable returns the name an output is passed to downstream
+// pipelines under, e.g. GOVERNANCE_ERROR_COUNT for error_count
+func downstreamVariable(name string) string {
+	return "GOVERNANCE_" + strings.ToUpper(name)
 }
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// admissionKind identifies the verdict object to admission policies
+const admissionKind = "GovernanceVerdict"
+
+// admissionVersion is the version of the verdict object
+const admissionVersion = 1
+
+// defaultAdmissionFile is where the admission format writes the verdict by default
+const defaultAdmissionFile = "governance-admission.json"
+
+// admissionVerdict is the compact verdict of a run for Kubernetes admission
+// policies, e.g. OPA Gatekeeper or Kyverno, to refuse workloads whose specs
+// never passed governance
+type admissionVerdict struct {
+	Kind           string          `json:"kind"`
+	Version        int             `json:"version"`
+	Specs          []admissionSpec `json:"specs"`
+	Ruleset        string          `json:"ruleset"`
+	RulesetVersion string          `json:"ruleset_version,omitempty"`
+	Verdict        string          `json:"verdict"`
+	Passed         bool            `json:"passed"`
+	Repository     string          `json:"repository,omitempty"`
+	Commit         string          `json:"commit,omitempty"`
+	IssuedAt       time.Time       `json:"issued_at"`
+	// Signature is the hex HMAC-SHA256 of the other fields, as compact JSON
+	// with sorted keys, with admission_key. Unsigned without a key.
+	Signature string `json:"signature,omitempty"`
+}
+
+// admissionSpec is an analyzed spec, identified by the hash of its content
+type admissionSpec struct {
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+}
+
+// newAdmissionVerdict returns the verdict object of a run, hashing the
+// analyzed specs as they are on disk
+func newAdmissionVerdict(config *Configuration, report *analysisReport, result *RunResult) (*admissionVerdict, error) {
+	verdict := &admissionVerdict{
+		Kind:           admissionKind,
+		Version:        admissionVersion,
+		Specs:          make([]admissionSpec, 0, len(report.Files)),
+		Ruleset:        config.RuleID,
+		RulesetVersion: config.RulesetVersion,
+		Verdict:        result.Verdict,
+		Passed:         result.proceeds(),
+		Repository:     result.ciContext["repository"],
+		Commit:         result.ciContext["commit"],
+		IssuedAt:       report.Finished.UTC().Truncate(time.Second),
+	}
+	if ruleset := report.Ruleset; ruleset != nil && ruleset.ID != "" {
+		verdict.Ruleset, verdict.RulesetVersion = ruleset.ID, ruleset.Version
+	}
+	for _, file := range report.Files {
+		content, err := readSpecFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash spec %s: %w", file, err)
+		}
+		sum := sha256.Sum256(content)
+		verdict.Specs = append(verdict.Specs, admissionSpec{File: repoPath(file), SHA256: hex.EncodeToString(sum[:])})
+	}
+	return verdict, nil
+}
+
+// sign sets the signature of the verdict. The signed fields are marshalled
+// the way OPA's json.marshal does, so Rego policies can verify it with
+// crypto.hmac.sha256.
+func (v *admissionVerdict) sign(key string) error {
+	v.Signature = ""
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// Round trip through a map for sorted keys
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if data, err = json.Marshal(fields); err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(data)
+	v.Signature = hex.EncodeToString(mac.Sum(nil))
+	return nil
+}
+
+// writeAdmissionVerdict writes the verdict object of a run, signed with
+// admission_key if set
+func writeAdmissionVerdict(config *Configuration, report *analysisReport, result *RunResult, path string) error {
+	verdict, err := newAdmissionVerdict(config, report, result)
+	if err != nil {
+		return err
+	}
+	if config.AdmissionKey != "" {
+		if err := verdict.sign(config.AdmissionKey); err != nil {
+			return fmt.Errorf("failed to sign admission verdict: %w", err)
+		}
+	}
+	data, err := json.MarshalIndent(verdict, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal admission verdict: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write admission verdict %s: %w", path, err)
+	}
+	return nil
+}
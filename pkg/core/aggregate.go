@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// shardStatus is the status file of one job of a sharded pipeline
+type shardStatus struct {
+	Path   string
+	Status runStatus
+}
+
+// RunAggregate merges the status files that parallel jobs wrote under dir into
+// one result, for pipelines that shard the specs across runners. The combined
+// counts are checked against the thresholds again, so limits apply to the whole
+// API surface rather than each shard. The run fails if any job didn't reach a
+// verdict.
+func RunAggregate(logger *zap.Logger, dir, statusFile string) (*RunResult, error) {
+	result := &RunResult{Verdict: VerdictError, StartedAt: time.Now().UTC()}
+	defer result.finish()
+
+	config, err := getConfiguration()
+	if err != nil {
+		return result, fmt.Errorf("configuration error: %w", err)
+	}
+	result.StartedAt = result.StartedAt.In(config.Timezone)
+
+	// Apply the same profile and enforcement mode as the jobs
+	ciContext := integrations.GetContext(integrations.DetectCI())
+	profileName, profile, err := selectProfile(config.Profile, config.Profiles, ciContext["branch"])
+	if err != nil {
+		return result, fmt.Errorf("configuration error: %w", err)
+	}
+	if profile != nil {
+		config.Profile = profileName
+		config.applyProfile(profileName, profile)
+	}
+	if err := enforceOrgPolicy(context.Background(), config, logger); err != nil {
+		return result, fmt.Errorf("configuration error: %w", err)
+	}
+	config.Mode = resolveMode(config.Mode, config.Policies, ciContext)
+
+	shards, err := readShards(dir, statusFile, logger)
+	if err != nil {
+		return result, err
+	}
+	logger.Info("Aggregating job results", zap.String("dir", dir), zap.Int("job_count", len(shards)))
+
+	failed := mergeShards(result, shards)
+	result.Mode = config.Mode
+	result.Profile = config.Profile
+	result.Reason = exceededThreshold(config, result.Errors, result.Warnings)
+	switch {
+	case len(failed) > 0:
+		result.Verdict, result.Reason = VerdictError, ReasonError
+	case result.Reason != ReasonNone:
+		result.Verdict = VerdictFail
+		if config.Mode == ModeAdvisory {
+			result.Verdict = VerdictWarn
+		}
+	default:
+		result.Verdict = VerdictPass
+	}
+
+	printAggregate(config, result, failed)
+	setOutput(config, "error_count", strconv.Itoa(result.Errors))
+	setOutput(config, "warning_count", strconv.Itoa(result.Warnings))
+	setOutput(config, "total_issues", strconv.Itoa(result.Total))
+	setOutput(config, "excluded_count", strconv.Itoa(result.Excluded))
+	if summary, err := json.Marshal(summarize(result)); err == nil {
+		setOutput(config, "summary", string(summary))
+	}
+	if len(result.FileResults) > 0 {
+		setMatrixOutput(config, result.FileResults)
+	}
+
+	if len(failed) > 0 {
+		return result, fmt.Errorf("%d of %d jobs failed: %s", len(failed), len(shards), strings.Join(failed, "; "))
+	}
+	if result.Verdict == VerdictFail {
+		return result, checkThresholds(config, result.Errors, result.Warnings)
+	}
+	return result, nil
+}
+
+// readShards reads the status files under dir, skipping the combined status
+// file and JSON files that aren't status files
+func readShards(dir, statusFile string, logger *zap.Logger) ([]shardStatus, error) {
+	var shards []shardStatus
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".json" || sameFile(path, statusFile) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read job result: %w", err)
+		}
+		var status runStatus
+		if err := json.Unmarshal(data, &status); err != nil || status.RunResult == nil || status.Verdict == "" {
+			logger.Debug("Skipping file that isn't a status file", zap.String("path", path))
+			return nil
+		}
+		shards = append(shards, shardStatus{Path: path, Status: status})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job results in %s: %w", dir, err)
+	}
+	if len(shards) == 0 {
+		return nil, errors.New("no status files found in " + dir + ", write them with status_file in each job")
+	}
+	return shards, nil
+}
+
+// sameFile reports whether two paths name the same file
+func sameFile(a, b string) bool {
+	if b == "" {
+		return false
+	}
+	aInfo, errA := os.Stat(a)
+	bInfo, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(aInfo, bInfo)
+}
+
+// mergeShards adds up the job results into result, returning the jobs that
+// didn't reach a verdict
+func mergeShards(result *RunResult, shards []shardStatus) []string {
+	var failed []string
+	var started, finished time.Time
+	for _, shard := range shards {
+		status := shard.Status
+		if status.Verdict == VerdictError {
+			reason := status.Error
+			if reason == "" {
+				reason = "no verdict"
+			}
+			failed = append(failed, shard.Path+": "+reason)
+		}
+
+		result.Files = append(result.Files, status.Files...)
+		result.Errors += status.Errors
+		result.Warnings += status.Warnings
+		result.Total += status.Total
+		result.Excluded += status.Excluded
+		result.RetriesUsed += status.RetriesUsed
+		result.AnalysisDurationMS += status.AnalysisDurationMS
+		result.UploadSizeBytes += status.UploadSizeBytes
+		result.ServiceLatencyMS += status.ServiceLatencyMS
+		result.Artifacts = append(result.Artifacts, status.Artifacts...)
+
+		// Single spec jobs only report their totals
+		if len(status.FileResults) > 0 {
+			result.FileResults = append(result.FileResults, status.FileResults...)
+		} else if len(status.Files) == 1 {
+			result.FileResults = append(result.FileResults, FileResult{
+				File: status.Files[0], Errors: status.Errors, Warnings: status.Warnings, Verdict: status.Verdict,
+			})
+		}
+
+		if !status.StartedAt.IsZero() && (started.IsZero() || status.StartedAt.Before(started)) {
+			started = status.StartedAt
+		}
+		if status.FinishedAt.After(finished) {
+			finished = status.FinishedAt
+		}
+	}
+
+	// The pipeline ran from the first job's start to the last job's end
+	if !started.IsZero() {
+		result.StartedAt = started
+		result.FinishedAt = finished
+	}
+	return failed
+}
+
+// printAggregate prints the combined report of a sharded pipeline
+func printAggregate(config *Configuration, result *RunResult, failed []string) {
+	fmt.Printf("\n================ %s ================\n", config.text("report.title"))
+	if len(result.FileResults) > 0 {
+		printFileMatrix(os.Stdout, config, result.FileResults)
+	}
+	for _, job := range failed {
+		fmt.Printf("❌ %s\n", job)
+	}
+	fmt.Printf("%d errors, %d warnings, %d total issues, %d excluded: %s\n",
+		result.Errors, result.Warnings, result.Total, result.Excluded, config.text("verdict."+result.Verdict))
+	fmt.Println("===========================================================")
+	fmt.Println()
+}
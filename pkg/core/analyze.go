@@ -0,0 +1,235 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// defaultRetries is how often transient governance service failures are retried
+const defaultRetries = 2
+
+// defaultTimeout bounds each governance service request
+const defaultTimeout = 30 * time.Second
+
+// connectGovernance creates the governance client and fetches the ruleset
+// metadata. In mocked mode there is no client and no metadata.
+func connectGovernance(ctx context.Context, config *Configuration, logger *zap.Logger) (*integrations.GovernanceClient, *integrations.Ruleset, error) {
+	// Check if mocked mode is enabled
+	// Without a service, the rule docs are the only metadata
+	if config.Mocked != "" {
+		logger.Info("Running in mocked mode", zap.String("mocked_type", config.Mocked))
+		return nil, config.RuleDocs, nil
+	}
+
+	// Normal mode - create governance client and analyze
+	client := integrations.NewGovernanceClient(config.GovernanceService, config.GovernanceAuth, logger)
+	if config.MetadataCacheTTL > 0 {
+		client.EnableMetadataCache(filepath.Join(config.CacheDir, "metadata"), config.MetadataCacheTTL)
+	}
+	client.SetRetries(config.Retries, config.RetryBackoff)
+	client.SetTimeout(config.Timeout)
+	client.SetTenant(config.OrgID, config.TeamID)
+
+	// Fetching the ruleset metadata validates the token before anything is
+	// uploaded, a local ruleset brings its own and the analysis checks the token
+	var ruleset *integrations.Ruleset
+	var err error
+	if config.InlineRuleset != nil {
+		logger.Info("Evaluating local ruleset", zap.String("ruleset_file", config.RulesetFile),
+			zap.Int("rules", len(config.InlineRuleset.Metadata.Rules)))
+		ruleset = config.InlineRuleset.Metadata
+	} else if ruleset, err = client.GetRuleset(ctx, config.RuleID); err != nil {
+		// Reports can be enriched from the rule docs instead, unless the token
+		// was rejected
+		if config.RuleDocs != nil && !integrations.IsAuthError(err) {
+			logger.Warn("Failed to fetch ruleset metadata, enriching the report from the rule docs",
+				zap.String("rule_docs", config.RuleDocsFile), zap.Error(err))
+			ruleset = config.RuleDocs
+		} else {
+			switch {
+			case errors.Is(err, integrations.ErrExpiredToken):
+				logger.Error("Governance token has expired, generate a new token and update governance_auth")
+			case errors.Is(err, integrations.ErrInvalidToken):
+				logger.Error("Governance token is invalid, check the value of governance_auth")
+			case errors.Is(err, integrations.ErrInsufficientPermissions):
+				logger.Error("Governance token lacks permissions for the ruleset", zap.String("rule_id", config.RuleID))
+			default:
+				logger.Error("Failed to validate governance token", zap.Error(err))
+			}
+			return nil, nil, fmt.Errorf("token validation failed: %w", err)
+		}
+	}
+
+	ruleset = ruleset.WithDocs(config.RuleDocs)
+
+	// Results are attributed to the tenant, so make sure the token belongs to it
+	if config.OrgID != "" {
+		if err := checkTenantScope(ctx, client, config, logger); err != nil {
+			return nil, nil, fmt.Errorf("token validation failed: %w", err)
+		}
+	}
+
+	payloadVersion := config.PayloadVersion
+	if payloadVersion == integrations.PayloadAuto {
+		payloadVersion, err = client.DetectPayloadVersion(ctx)
+		if err != nil {
+			logger.Warn("Failed to detect the governance service version, using the v2 payload", zap.Error(err))
+			payloadVersion = integrations.PayloadV2
+		}
+	}
+	logger.Info("Using analysis payload version", zap.String("payload_version", payloadVersion))
+	client.SetPayloadVersion(payloadVersion)
+	return client, ruleset, nil
+}
+
+// analyzeTarget reads and analyzes a single spec, returning its findings and content
+func analyzeTarget(ctx context.Context, config *Configuration, client *integrations.GovernanceClient, target specTarget, logger *zap.Logger) ([]Finding, string, error) {
+	var findings []Finding
+	var content string
+	// The service records the spec under its repository path
+	source := repoPath(target.Path)
+
+	if config.Mocked != "" {
+		// Generate mock results based on the mocked type
+		results := generateMockResults(config.Mocked, config.RuleID, source)
+		logger.Info("Generated mock results", zap.Int("result_count", len(results)), zap.String("mocked_type", config.Mocked))
+
+		// The spec isn't required in mocked mode, it's only read for statistics
+		content, _ = readOASFile(target.Path, config.MaxSpecSize)
+		findings = yamlDocument{Content: content}.findings(results, source)
+	} else {
+		// Read and validate the OAS file
+		var err error
+		content, err = readOASFile(target.Path, config.MaxSpecSize)
+		if err != nil {
+			logger.Error("Failed to read OAS file", zap.Error(err), zap.String("path", target.Path))
+			return nil, "", fmt.Errorf("failed to read OAS file: %w", err)
+		}
+
+		// Fill in templated values so rules don't flag the placeholders
+		var missing []string
+		content, missing = substituteEnv(content, config.SubstituteEnv)
+		if len(missing) > 0 {
+			logger.Warn("Spec placeholders left unsubstituted, variables are not set", zap.Strings("variables", missing), zap.String("path", target.Path))
+		}
+
+		// Multi-document YAML files are analyzed per document
+		documents, err := selectDocuments(splitDocuments(content), config.Document)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read OAS file %s: %w", target.Path, err)
+		}
+		if len(documents) > 1 {
+			logger.Info("Analyzing multi-document YAML file", zap.String("path", target.Path), zap.Int("documents", len(documents)))
+		}
+
+		for _, document := range documents {
+			// Tell the service the spec version, e.g. so OpenAPI 3.1 schemas are
+			// evaluated as JSON Schema 2020-12
+			format := target.Format
+			doc, parseErr := parseSpec(document.Content)
+			if parseErr == nil && format == "" {
+				format = doc.format()
+				logger.Debug("Detected spec format", zap.String("path", target.Path), zap.String("format", format))
+			}
+
+			// Verify the approvals recorded in the spec itself
+			if parseErr == nil {
+				findings = append(findings, document.findings(config.Approvals.check(doc, document.Content), source)...)
+			}
+
+			// Check the prerequisites locally. Specs missing error prerequisites
+			// fail anyway, so they aren't sent to the service.
+			var missing []integrations.LintResult
+			if parseErr == nil {
+				missing = config.Prerequisites.check(doc, document.Content)
+			}
+			if len(missing) > 0 {
+				findings = append(findings, document.findings(missing, source)...)
+				if config.Prerequisites.level() == severityLevels[severityError] {
+					logger.Warn("Spec is missing governance prerequisites, skipping the analysis",
+						zap.String("path", target.Path), zap.Int("document", document.Index), zap.Int("missing", len(missing)))
+					continue
+				}
+			}
+
+			// Inline the files the spec references, mapping findings back to them
+			analyzed := document.Content
+			var sources *sourceMap
+			if config.Bundle {
+				if analyzed, sources, err = bundleSpec(document, target.Path, config.Normalize); err != nil {
+					return nil, "", fmt.Errorf("failed to bundle OAS file %s: %w", target.Path, err)
+				}
+			}
+
+			// Send the canonical form so cosmetic edits don't move the ranges.
+			// Findings are still located in the file through their paths. Bundled
+			// specs are normalized by the bundling.
+			if config.Normalize && sources == nil {
+				if normalized, err := normalizeSpec(document.Content); err != nil {
+					logger.Warn("Failed to normalize spec, analyzing it as is", zap.Error(err), zap.String("path", target.Path))
+				} else {
+					analyzed = normalized
+				}
+			}
+
+			results, err := client.Analyze(ctx, integrations.AnalysisRequest{
+				Content:  analyzed,
+				RuleID:   config.RuleID,
+				Version:  config.RulesetVersion,
+				Filename: source,
+				APIID:    target.APIID,
+				APIName:  target.APIName,
+				Format:   format,
+				Ruleset:  config.InlineRuleset,
+			})
+			if err != nil {
+				logger.Error("Failed to analyze OAS", zap.Error(err), zap.String("path", target.Path), zap.Int("document", document.Index))
+				return nil, "", fmt.Errorf("failed to analyze OAS: %w", err)
+			}
+			if sources == nil {
+				findings = append(findings, document.findings(results, source)...)
+				continue
+			}
+			bundled := yamlDocument{Index: document.Index, Content: analyzed}.findings(results, source)
+			for i := range bundled {
+				bundled[i].File = target.Path
+				sources.mapFinding(&bundled[i])
+			}
+			findings = append(findings, bundled...)
+		}
+	}
+
+	for i := range findings {
+		if findings[i].File == "" {
+			findings[i].File = target.Path
+		}
+	}
+	mapUnknownSeverities(config, findings, logger)
+	return findings, content, nil
+}
+
+// checkTenantScope verifies the token is scoped to the configured org and team.
+// Services that don't report token scopes are trusted to enforce them.
+func checkTenantScope(ctx context.Context, client *integrations.GovernanceClient, config *Configuration, logger *zap.Logger) error {
+	scope, err := client.GetTokenScope(ctx)
+	if errors.Is(err, integrations.ErrScopeUnknown) {
+		logger.Warn("Governance service doesn't report token scopes, cannot verify the tenant",
+			zap.String("org_id", config.OrgID), zap.String("team_id", config.TeamID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := scope.Covers(config.OrgID, config.TeamID); err != nil {
+		logger.Error("Governance token is scoped to a different tenant, check org_id, team_id and governance_auth", zap.Error(err))
+		return err
+	}
+	logger.Info("Verified token tenant scope", zap.String("org_id", config.OrgID), zap.String("team_id", config.TeamID))
+	return nil
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// Operation extensions recording an approval in the spec itself
+const (
+	approvedByExtension = "x-governance-approved-by"
+	ticketExtension     = "x-governance-ticket"
+)
+
+// approvalRule names the findings for malformed approval annotations
+const approvalRule = "approval-annotation"
+
+// defaultTicketPattern matches issue keys such as GOV-123
+const defaultTicketPattern = `^[A-Z][A-Z0-9]+-[0-9]+$`
+
+// approverPattern matches approvers, a handle such as @jdoe or an email address
+var approverPattern = regexp.MustCompile(`^(@?[A-Za-z0-9][A-Za-z0-9._/-]*|[^@\s]+@[^@\s]+\.[^@\s]+)$`)
+
+// Approvals is how the approval annotations on operations are checked
+type Approvals struct {
+	// TicketPattern is the regular expression ticket IDs must match
+	TicketPattern string `yaml:"ticket_pattern"`
+	// RequireTicket rejects approvals without a ticket
+	RequireTicket bool `yaml:"require_ticket"`
+	// Honor excludes the findings on operations with a valid approval
+	Honor bool `yaml:"honor"`
+	// Severity of the findings for malformed annotations, warning by default
+	Severity string `yaml:"severity"`
+}
+
+// operationApproval is an approval annotated on an operation
+type operationApproval struct {
+	File       string   `json:"file"`
+	Document   int      `json:"document,omitempty"`
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	ApprovedBy []string `json:"approved_by"`
+	Tickets    []string `json:"tickets"`
+	// Problems are what's malformed about the annotations; only valid
+	// approvals are honored
+	Problems []string `json:"problems,omitempty"`
+	// Findings counts the findings on the operation, excluded ones included
+	Findings int `json:"findings"`
+
+	section    string // paths or webhooks
+	annotation string // The first annotation on the operation, to locate it
+}
+
+// valid reports whether the annotations are well-formed
+func (a operationApproval) valid() bool {
+	return len(a.Problems) == 0
+}
+
+// reason describes the approval, e.g. "approved by @jdoe (GOV-123)"
+func (a operationApproval) reason() string {
+	reason := "approved by " + strings.Join(a.ApprovedBy, ", ")
+	if len(a.Tickets) > 0 {
+		reason += " (" + strings.Join(a.Tickets, ", ") + ")"
+	}
+	return reason
+}
+
+// ticketPattern returns the pattern ticket IDs must match, validated with the
+// configuration
+func (a *Approvals) ticketPattern() *regexp.Regexp {
+	if a == nil || a.TicketPattern == "" {
+		return regexp.MustCompile(defaultTicketPattern)
+	}
+	return regexp.MustCompile(a.TicketPattern)
+}
+
+// level returns the severity level of the findings for malformed annotations
+func (a *Approvals) level() int {
+	if a != nil {
+		if level, ok := severityLevels[a.Severity]; ok {
+			return level
+		}
+	}
+	return severityLevels[severityWarning]
+}
+
+// validate checks the ticket pattern
+func (a *Approvals) validate() error {
+	if a == nil || a.TicketPattern == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(a.TicketPattern); err != nil {
+		return fmt.Errorf("approvals: invalid ticket_pattern: %w", err)
+	}
+	return nil
+}
+
+// annotationValues reads an annotation that is a string or a list of strings,
+// reporting whether it has another type
+func annotationValues(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return []string{strings.TrimSpace(v)}, true
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, strings.TrimSpace(s))
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// operationApprovals returns the approvals annotated on the operations of a
+// document, with what's malformed about them, by path and then method
+func (a *Approvals) operationApprovals(doc specDocument) []operationApproval {
+	pattern := a.ticketPattern()
+	var approvals []operationApproval
+	for _, section := range []string{"paths", "webhooks"} {
+		for path, methods := range doc.operations(section) {
+			for method, operation := range methods {
+				if operation[approvedByExtension] == nil && operation[ticketExtension] == nil {
+					continue
+				}
+				approval := operationApproval{
+					Method:     method,
+					Path:       path,
+					ApprovedBy: []string{},
+					Tickets:    []string{},
+					section:    section,
+					annotation: approvedByExtension,
+				}
+				if operation[approvedByExtension] == nil {
+					approval.annotation = ticketExtension
+				}
+				approvers, ok := annotationValues(operation[approvedByExtension])
+				if !ok {
+					approval.Problems = append(approval.Problems, approvedByExtension+" must be a string or a list of strings")
+				}
+				for _, approver := range approvers {
+					if !approverPattern.MatchString(approver) {
+						approval.Problems = append(approval.Problems, fmt.Sprintf("%s %q is neither a handle nor an email address", approvedByExtension, approver))
+						continue
+					}
+					approval.ApprovedBy = append(approval.ApprovedBy, approver)
+				}
+				if ok && len(approvers) == 0 {
+					approval.Problems = append(approval.Problems, ticketExtension+" needs an approver in "+approvedByExtension)
+				}
+
+				tickets, ok := annotationValues(operation[ticketExtension])
+				if !ok {
+					approval.Problems = append(approval.Problems, ticketExtension+" must be a string or a list of strings")
+				}
+				for _, ticket := range tickets {
+					if !pattern.MatchString(ticket) {
+						approval.Problems = append(approval.Problems, fmt.Sprintf("%s %q doesn't match %s", ticketExtension, ticket, pattern))
+						continue
+					}
+					approval.Tickets = append(approval.Tickets, ticket)
+				}
+				if ok && len(tickets) == 0 && a != nil && a.RequireTicket {
+					approval.Problems = append(approval.Problems, approvedByExtension+" needs a ticket in "+ticketExtension)
+				}
+				approvals = append(approvals, approval)
+			}
+		}
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		if approvals[i].Path != approvals[j].Path {
+			return approvals[i].Path < approvals[j].Path
+		}
+		return approvals[i].Method < approvals[j].Method
+	})
+	return approvals
+}
+
+// check returns a finding result for each malformed approval of the document
+func (a *Approvals) check(doc specDocument, content string) []integrations.LintResult {
+	root, _ := parseSpecNode(content)
+	var results []integrations.LintResult
+	for _, approval := range a.operationApprovals(doc) {
+		if approval.valid() {
+			continue
+		}
+		path := []string{approval.section, approval.Path, approval.Method, approval.annotation}
+		line := 1
+		if root != nil {
+			if location, ok := locatePath(root, path); ok {
+				line = location.StartLine
+			}
+		}
+		results = append(results, integrations.LintResult{
+			Code:     approvalRule,
+			Path:     path,
+			Message:  "Malformed approval: " + strings.Join(approval.Problems, "; "),
+			Severity: a.level(),
+			Range:    integrations.LintRange{Start: integrations.LintLocation{Line: line}, End: integrations.LintLocation{Line: line}},
+			Rule:     integrations.RuleReference{Name: approvalRule},
+		})
+	}
+	return results
+}
+
+// collectApprovals returns the approvals annotated in the documents of a spec
+// file, counting the findings on each operation. With honor, the findings on
+// operations with a valid approval are split off as excluded.
+func collectApprovals(config *Configuration, file string, findings []Finding, docs map[int]specDocument) (approvals []operationApproval, kept, excluded []Finding) {
+	indexes := make([]int, 0, len(docs))
+	for index := range docs {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	byOperation := map[string]int{}
+	for _, index := range indexes {
+		for _, approval := range config.Approvals.operationApprovals(docs[index]) {
+			approval.File, approval.Document = repoPath(file), index
+			byOperation[operationKey(file, index, approval.section, approval.Path, approval.Method)] = len(approvals)
+			approvals = append(approvals, approval)
+		}
+	}
+
+	honor := config.Approvals != nil && config.Approvals.Honor
+	for _, finding := range findings {
+		i, ok := -1, false
+		if len(finding.Path) >= 3 {
+			i, ok = byOperation[operationKey(file, finding.Document, finding.Path[0], finding.Path[1], strings.ToLower(finding.Path[2]))]
+		}
+		// Malformed annotations are reported, never approved
+		if !ok || finding.Rule.Name == approvalRule {
+			kept = append(kept, finding)
+			continue
+		}
+		approvals[i].Findings++
+		if honor && approvals[i].valid() {
+			finding.ExclusionReason = approvals[i].reason()
+			excluded = append(excluded, finding)
+			continue
+		}
+		kept = append(kept, finding)
+	}
+	return approvals, kept, excluded
+}
+
+// printApprovals prints the operations approved in the spec itself
+func printApprovals(w io.Writer, config *Configuration, approvals []operationApproval) {
+	if len(approvals) == 0 {
+		return
+	}
+	printHeading(w, config.text("report.approvals"))
+	for _, approval := range approvals {
+		status := approval.reason()
+		if !approval.valid() {
+			status = config.text("report.approval_invalid", strings.Join(approval.Problems, "; "))
+		}
+		fmt.Fprintf(w, "    %s %s (%s): %s, %d findings\n", strings.ToUpper(approval.Method), approval.Path, approval.File, status, approval.Findings)
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const approvalsSpec = `openapi: 3.0.3
+info: {title: t, version: "1"}
+paths:
+  /users:
+    get:
+      x-governance-approved-by: "@jdoe"
+      x-governance-ticket: GOV-123
+      responses: {"200": {description: ok}}
+    post:
+      x-governance-approved-by: [jane@example.com, "@team/api"]
+      responses: {"200": {description: ok}}
+    delete:
+      x-governance-approved-by: "not a handle!"
+      responses: {"200": {description: ok}}
+  /orders:
+    get:
+      x-governance-ticket: gov-1
+      responses: {"200": {description: ok}}
+`
+
+func TestOperationApprovals(t *testing.T) {
+	doc := parseSpecDocuments(approvalsSpec)[0]
+	tests := []struct {
+		name      string
+		approvals *Approvals
+		valid     []string
+		invalid   []string
+	}{
+		{name: "defaults", valid: []string{"GET /users", "POST /users"}, invalid: []string{"GET /orders", "DELETE /users"}},
+		{name: "ticket required", approvals: &Approvals{RequireTicket: true}, valid: []string{"GET /users"}, invalid: []string{"GET /orders", "DELETE /users", "POST /users"}},
+		{name: "ticket pattern", approvals: &Approvals{TicketPattern: `^(?i)gov-\d+$`}, valid: []string{"GET /users", "POST /users"}, invalid: []string{"GET /orders", "DELETE /users"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var valid, invalid []string
+			for _, approval := range tt.approvals.operationApprovals(doc) {
+				operation := strings.ToUpper(approval.Method) + " " + approval.Path
+				if approval.valid() {
+					valid = append(valid, operation)
+				} else {
+					invalid = append(invalid, operation)
+				}
+			}
+			slices.Sort(valid)
+			slices.Sort(invalid)
+			slices.Sort(tt.valid)
+			slices.Sort(tt.invalid)
+			if !slices.Equal(valid, tt.valid) {
+				t.Errorf("valid %v, want %v", valid, tt.valid)
+			}
+			if !slices.Equal(invalid, tt.invalid) {
+				t.Errorf("invalid %v, want %v", invalid, tt.invalid)
+			}
+		})
+	}
+}
+
+func TestCollectApprovals(t *testing.T) {
+	docs := parseSpecDocuments(approvalsSpec)
+	approved := testFinding("owasp-rate-limit", "paths", "/users", "get", "responses")
+	approvedUpper := testFinding("owasp-define-error-responses-401", "paths", "/users", "GET")
+	malformed := testFinding("owasp-rate-limit", "paths", "/users", "delete")
+	unapproved := testFinding("owasp-rate-limit", "paths", "/accounts", "get")
+	document := testFinding("info-contact", "info")
+	annotation := testFinding(approvalRule, "paths", "/users", "delete", approvedByExtension)
+	findings := []Finding{approved, approvedUpper, malformed, unapproved, document, annotation}
+
+	tests := []struct {
+		name     string
+		honor    bool
+		excluded []Finding
+	}{
+		{name: "reported only"},
+		{name: "honored", honor: true, excluded: []Finding{approved, approvedUpper}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Configuration{SpecSettings: SpecSettings{Approvals: &Approvals{Honor: tt.honor}}}
+			approvals, kept, excluded := collectApprovals(config, "openapi.yaml", findings, docs)
+			sameFindings(t, "excluded", excluded, tt.excluded)
+			if len(kept)+len(excluded) != len(findings) {
+				t.Errorf("kept %d and excluded %d of %d findings", len(kept), len(excluded), len(findings))
+			}
+			for _, finding := range excluded {
+				if finding.ExclusionReason != "approved by @jdoe (GOV-123)" {
+					t.Errorf("reason %q", finding.ExclusionReason)
+				}
+			}
+			counts := map[string]int{}
+			for _, approval := range approvals {
+				counts[approval.Method+" "+approval.Path] = approval.Findings
+			}
+			// The malformed annotation's own finding doesn't count
+			if want := map[string]int{"get /users": 2, "post /users": 0, "delete /users": 1, "get /orders": 0}; !maps.Equal(counts, want) {
+				t.Errorf("findings per approval %v, want %v", counts, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// runURL returns the page of the CI run, where the log and the uploaded
+// artifacts can be reached, or "" when not running in CI
+func runURL(ci string) string {
+	switch ci {
+	case "github":
+		server, repository, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+		if server == "" || repository == "" || runID == "" {
+			return ""
+		}
+		return server + "/" + repository + "/actions/runs/" + runID
+	case "gitlab":
+		return os.Getenv("CI_JOB_URL")
+	}
+	return ""
+}
+
+// artifactURLs returns the URLs the report files are reachable at once they're
+// uploaded, by file. Files are resolved against artifact_url, or the job
+// artifacts on GitLab. GitHub artifacts are zipped as a whole and only
+// reachable from the run page.
+func artifactURLs(config *Configuration, ci string, reports []string) map[string]string {
+	base := config.ArtifactURL
+	if base == "" && ci == "gitlab" && os.Getenv("CI_JOB_URL") != "" {
+		base = os.Getenv("CI_JOB_URL") + "/artifacts/file"
+	}
+	if base == "" || len(reports) == 0 {
+		return nil
+	}
+
+	urls := make(map[string]string, len(reports))
+	for _, report := range reports {
+		file := repoPath(report)
+		urls[file] = strings.TrimSuffix(base, "/") + "/" + file
+	}
+	return urls
+}
+
+// reportURL returns the URL of the full report: the first report file if its
+// URL is known, or the run page otherwise
+func reportURL(ci string, reports []string, urls map[string]string) string {
+	if len(reports) > 0 {
+		if url, ok := urls[repoPath(reports[0])]; ok {
+			return url
+		}
+	}
+	return runURL(ci)
+}
+
+// setArtifactOutputs sets the report_url and artifact_urls outputs
+func setArtifactOutputs(config *Configuration, report *analysisReport) {
+	if report.ReportURL != "" {
+		setOutput(config, "report_url", report.ReportURL)
+	}
+	if len(report.ArtifactURLs) > 0 {
+		if urls, err := json.Marshal(report.ArtifactURLs); err == nil {
+			setOutput(config, "artifact_urls", string(urls))
+		}
+	}
+}
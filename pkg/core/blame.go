@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"os"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// blameBase returns the git revision changes are measured against, preferring
+// the configured base over the merge queue, pull or merge request target
+func blameBase(config *Configuration, ciContext map[string]string) string {
+	if config.BlameBase != "" {
+		return config.BlameBase
+	}
+	if sha := ciContext["base_commit"]; sha != "" {
+		return sha
+	}
+	if sha := os.Getenv("CI_MERGE_REQUEST_DIFF_BASE_SHA"); sha != "" {
+		return sha
+	}
+	if ref := os.Getenv("GITHUB_BASE_REF"); ref != "" {
+		return "origin/" + ref
+	}
+	return ""
+}
+
+// attributeFindings uses git blame to attribute findings on changed lines to the
+// commit that introduced them. Failures are logged but never fail the run.
+func attributeFindings(ctx context.Context, findings []Finding, base string, logger *zap.Logger) {
+	if base == "" {
+		logger.Info("No base revision to compare against, skipping blame attribution")
+		return
+	}
+
+	changedByFile := map[string]map[int]bool{}
+	for i := range findings {
+		file := findings[i].File
+		changed, ok := changedByFile[file]
+		if !ok {
+			var err error
+			changed, err = integrations.ChangedLines(ctx, base, file)
+			if err != nil {
+				logger.Warn("Failed to determine changed lines, skipping blame attribution", zap.String("file", file), zap.Error(err))
+			}
+			changedByFile[file] = changed
+		}
+
+		location := findings[i].Location
+		for line := location.StartLine; line > 0 && line <= location.EndLine; line++ {
+			if !changed[line] {
+				continue
+			}
+			blame, err := integrations.BlameLine(ctx, file, line)
+			if err != nil {
+				logger.Warn("Failed to blame line", zap.String("file", file), zap.Int("line", line), zap.Error(err))
+				break
+			}
+			findings[i].Blame = blame
+			break
+		}
+	}
+}
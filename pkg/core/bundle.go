@@ -0,0 +1,271 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sourceOrigin is the file and position a node of a bundled spec comes from
+type sourceOrigin struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// sourceMap maps the lines of a bundled spec back to the files and lines the
+// author edited
+type sourceMap struct {
+	lines   map[int]sourceOrigin // By 1-based bundled line
+	columns map[int]int          // Column of the first node on a bundled line
+}
+
+// bundler inlines the external references of a spec, remembering where every
+// node came from
+type bundler struct {
+	origins map[*yaml.Node]sourceOrigin
+	files   map[string]*yaml.Node // Parsed external files by path
+	inlined int
+}
+
+// bundleSpec inlines the references to other files in a spec document, so
+// multi-file specs are analyzed as a whole, and returns the bundled content with
+// its source map. Specs without external references are returned as is with a
+// nil source map. Lines of the spec itself are mapped with the document offset.
+// With normalize the bundled spec is sent in its canonical form, which keeps
+// the nodes, so the source map still pairs them.
+func bundleSpec(document yamlDocument, path string, normalize bool) (string, *sourceMap, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(document.Content), &root); err != nil {
+		return "", nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return "", nil, fmt.Errorf("spec is empty")
+	}
+
+	b := &bundler{origins: map[*yaml.Node]sourceOrigin{}, files: map[string]*yaml.Node{}}
+	b.record(&root, path, document.Offset)
+	if err := b.resolve(root.Content[0], path, root.Content[0], []string{path}); err != nil {
+		return "", nil, err
+	}
+	if b.inlined == 0 {
+		return document.Content, nil, nil
+	}
+	if normalize {
+		canonicalize(&root)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return "", nil, fmt.Errorf("failed to encode bundled spec: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", nil, fmt.Errorf("failed to encode bundled spec: %w", err)
+	}
+
+	// The encoded spec has the same structure, so walking both trees together
+	// pairs each bundled line with the node it was written from
+	var bundled yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &bundled); err != nil {
+		return "", nil, fmt.Errorf("failed to parse bundled spec: %w", err)
+	}
+	sources := &sourceMap{lines: map[int]sourceOrigin{}, columns: map[int]int{}}
+	b.mapNodes(&root, &bundled, sources)
+	return buf.String(), sources, nil
+}
+
+// record remembers the file and position of a node and its children
+func (b *bundler) record(node *yaml.Node, file string, offset int) {
+	if _, ok := b.origins[node]; ok {
+		return
+	}
+	b.origins[node] = sourceOrigin{File: file, Line: node.Line + offset, Column: node.Column}
+	for _, child := range node.Content {
+		b.record(child, file, offset)
+	}
+}
+
+// resolve replaces the external references below node, which is part of file
+// whose root is fileRoot, with the nodes they point at. References within the
+// spec itself are kept, references within external files are inlined as they
+// can't be resolved from the spec. Circular references are kept as they are.
+func (b *bundler) resolve(node *yaml.Node, file string, fileRoot *yaml.Node, stack []string) error {
+	if node.Kind == yaml.MappingNode {
+		if ref := mappingValue(node, "$ref"); ref != nil && ref.Kind == yaml.ScalarNode {
+			refFile, pointer, _ := strings.Cut(ref.Value, "#")
+			external := refFile != "" || len(stack) > 1
+			target := filepath.Clean(filepath.Join(filepath.Dir(file), filepath.FromSlash(refFile)))
+			// Files referenced by a git spec are read from the same ref
+			if ref, name, ok := parseGitSpec(file); ok {
+				target = gitSpecPath(ref, path.Join(path.Dir(name), refFile))
+			}
+			if refFile == "" {
+				target = file
+			}
+			key := target + "#" + pointer
+			if external && !strings.Contains(refFile, "://") && !slices.Contains(stack, key) {
+				return b.inline(node, file, fileRoot, target, pointer, append(stack, key))
+			}
+		}
+	}
+	for _, child := range node.Content {
+		if err := b.resolve(child, file, fileRoot, stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inline replaces a reference node in file with the node at pointer in target
+func (b *bundler) inline(node *yaml.Node, file string, fileRoot *yaml.Node, target, pointer string, stack []string) error {
+	root := fileRoot
+	if target != file {
+		var err error
+		if root, err = b.load(target); err != nil {
+			return err
+		}
+	}
+	resolved, err := resolvePointer(root, pointer)
+	if err != nil {
+		return fmt.Errorf("failed to resolve $ref %s#%s: %w", repoPath(target), pointer, err)
+	}
+
+	// Copy the target, so each place it's referenced from is resolved separately
+	inlined := copyNode(resolved, b.origins)
+	if err := b.resolve(inlined, target, root, stack); err != nil {
+		return err
+	}
+	*node = *inlined
+	b.origins[node] = b.origins[inlined]
+	b.inlined++
+	return nil
+}
+
+// load parses an external file of the spec once
+func (b *bundler) load(path string) (*yaml.Node, error) {
+	if root, ok := b.files[path]; ok {
+		return root, nil
+	}
+	content, err := readSpecFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read referenced file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse referenced file %s: %w", repoPath(path), err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("referenced file %s is empty", repoPath(path))
+	}
+	b.record(doc.Content[0], path, 0)
+	b.files[path] = doc.Content[0]
+	return doc.Content[0], nil
+}
+
+// copyNode deep copies a node, carrying over the origins
+func copyNode(node *yaml.Node, origins map[*yaml.Node]sourceOrigin) *yaml.Node {
+	copied := *node
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = copyNode(child, origins)
+	}
+	origins[&copied] = origins[node]
+	return &copied
+}
+
+// resolvePointer returns the node a JSON pointer such as /components/schemas/User
+// points at from root
+func resolvePointer(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	node := root
+	if pointer == "" || pointer == "/" {
+		return node, nil
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			next = mappingValue(node, token)
+		case yaml.SequenceNode:
+			var index int
+			if _, err := fmt.Sscanf(token, "%d", &index); err == nil && index >= 0 && index < len(node.Content) {
+				next = node.Content[index]
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("%s not found", pointer)
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// mapNodes pairs the nodes of the bundled tree with the encoded one, recording
+// the origin of each encoded line. Collections are located by their children,
+// as with normalize their first child needn't be the original first one.
+func (b *bundler) mapNodes(original, encoded *yaml.Node, sources *sourceMap) {
+	if origin, ok := b.origins[original]; ok && encoded.Line > 0 && len(original.Content) == 0 {
+		if _, mapped := sources.lines[encoded.Line]; !mapped {
+			sources.lines[encoded.Line] = origin
+			sources.columns[encoded.Line] = encoded.Column
+		}
+	}
+	for i := 0; i < len(original.Content) && i < len(encoded.Content); i++ {
+		b.mapNodes(original.Content[i], encoded.Content[i], sources)
+	}
+}
+
+// locate returns the origin of a 1-based bundled line and 0-based character.
+// Lines without a node of their own, such as the lines of block scalars, are
+// located relative to the closest line above that has one.
+func (m *sourceMap) locate(line, character int) sourceOrigin {
+	for mapped := line; mapped > 0; mapped-- {
+		origin, ok := m.lines[mapped]
+		if !ok {
+			continue
+		}
+		origin.Line += line - mapped
+		origin.Column = max(0, character+origin.Column-m.columns[mapped])
+		return origin
+	}
+	return sourceOrigin{Line: line, Column: character}
+}
+
+// mapFinding moves a finding on the bundled spec to the file and lines it
+// comes from
+func (m *sourceMap) mapFinding(finding *Finding) {
+	start := m.locate(finding.Range.Start.Line, finding.Range.Start.Character)
+	end := m.locate(finding.Range.End.Line, finding.Range.End.Character)
+	if end.File != start.File || end.Line < start.Line {
+		end = start
+	}
+	if start.File == "" {
+		return
+	}
+	if start.File != finding.File {
+		finding.Spec, finding.File = finding.File, start.File
+	}
+	finding.Source = repoPath(start.File)
+	finding.Range.Start.Line, finding.Range.Start.Character = start.Line, start.Column
+	finding.Range.End.Line, finding.Range.End.Character = end.Line, end.Column
+
+	if finding.Location.StartLine > 0 {
+		startLine, endLine := m.locate(finding.Location.StartLine, 0), m.locate(finding.Location.EndLine, 0)
+		if startLine.File == start.File {
+			finding.Location.StartLine = startLine.Line
+			finding.Location.EndLine = startLine.Line
+			if endLine.File == start.File && endLine.Line >= startLine.Line {
+				finding.Location.EndLine = endLine.Line
+			}
+		} else {
+			finding.Location = specLocation{StartLine: start.Line, EndLine: end.Line}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// checkRunName is the name of the check run the action reports to
+const checkRunName = "Governance"
+
+// fingerprintPrefix marks the fingerprint in the raw details of an annotation
+const fingerprintPrefix = "fingerprint: "
+
+// maxSummaryFindings caps the findings linked from the check run summary, which
+// GitHub limits to 65535 characters
+const maxSummaryFindings = 50
+
+// checkAnnotations converts findings into check run annotations
+func checkAnnotations(findings []Finding) []integrations.CheckAnnotation {
+	annotations := make([]integrations.CheckAnnotation, 0, len(findings))
+	for _, finding := range findings {
+		level := "notice"
+		switch finding.Severity {
+		case 0:
+			level = "failure"
+		case 1:
+			level = "warning"
+		}
+		startLine, endLine := finding.Location.StartLine, finding.Location.StartLine
+		if startLine == 0 {
+			startLine, endLine = 1, 1
+		}
+		annotations = append(annotations, integrations.CheckAnnotation{
+			Path:            repoPath(finding.File),
+			StartLine:       startLine,
+			EndLine:         endLine,
+			AnnotationLevel: level,
+			Title:           finding.Rule.Name,
+			Message:         finding.Message,
+			RawDetails:      fingerprintPrefix + finding.Fingerprint(),
+		})
+	}
+	return annotations
+}
+
+// annotationKey identifies an annotation across runs, using the finding
+// fingerprint when the annotation carries one
+func annotationKey(annotation integrations.CheckAnnotation) string {
+	if strings.HasPrefix(annotation.RawDetails, fingerprintPrefix) {
+		return annotation.RawDetails
+	}
+	return fmt.Sprintf("%s:%d:%d:%s:%s", annotation.Path, annotation.StartLine, annotation.EndLine, annotation.Title, annotation.Message)
+}
+
+// annotationDelta returns the annotations that are new since the previous run
+// and the previous ones that no longer apply
+func annotationDelta(previous, current []integrations.CheckAnnotation) (added, resolved []integrations.CheckAnnotation) {
+	previousKeys := map[string]bool{}
+	for _, annotation := range previous {
+		previousKeys[annotationKey(annotation)] = true
+	}
+	currentKeys := map[string]bool{}
+	for _, annotation := range current {
+		key := annotationKey(annotation)
+		currentKeys[key] = true
+		if !previousKeys[key] {
+			added = append(added, annotation)
+		}
+	}
+	for _, annotation := range previous {
+		if !currentKeys[annotationKey(annotation)] {
+			resolved = append(resolved, annotation)
+		}
+	}
+	return added, resolved
+}
+
+// checkRunSummary renders the Markdown summary of the check run
+func checkRunSummary(config *Configuration, report *analysisReport, resolved []integrations.CheckAnnotation) string {
+	findings := report.Findings
+	errorCount, warningCount := countSeverities(findings)
+	errorStyle, warningStyle := config.severityStyle(0), config.severityStyle(1)
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "%s **%d** %s, %s **%d** %s, **%d** total issues.\n",
+		errorStyle.Icon, errorCount, errorStyle.Label, warningStyle.Icon, warningCount, warningStyle.Label, len(findings))
+	if coverage := report.Coverage; coverage != nil && coverage.Operations > 0 {
+		fmt.Fprintf(&summary, "\n**%d** of **%d** operations governed (%d%%), **%d** skipped.\n",
+			coverage.governedCount(), coverage.Operations, coverage.percent(), len(coverage.Skipped))
+	}
+	if rules := report.Rules; rules != nil {
+		fmt.Fprintf(&summary, "\n✅ **%d** rules passed, ❌ **%d** failed.\n", len(rules.Passed), len(rules.Failed))
+	}
+	fmt.Fprintf(&summary, "\n_Run started %s_\n", formatTimestamp(report.Started))
+	if report.ReportURL != "" {
+		fmt.Fprintf(&summary, "\n[Full report](%s)\n", report.ReportURL)
+	}
+	if linked := linkedFindings(findings); len(linked) > 0 {
+		fmt.Fprintf(&summary, "\n**Findings:**\n\n")
+		for i, finding := range linked {
+			if i == maxSummaryFindings {
+				fmt.Fprintf(&summary, "- … and %d more\n", len(linked)-maxSummaryFindings)
+				break
+			}
+			style := config.severityStyle(finding.Severity)
+			fmt.Fprintf(&summary, "- %s `%s` %s ([%s:%d](%s))\n", style.Icon, finding.Rule.Name, finding.Message,
+				repoPath(finding.File), finding.Range.Start.Line, finding.Permalink)
+		}
+	}
+	if len(resolved) > 0 {
+		fmt.Fprintf(&summary, "\n**Resolved since the previous run:**\n\n")
+		for _, annotation := range resolved {
+			fmt.Fprintf(&summary, "- `%s` %s (%s:%d)\n", annotation.Title, annotation.Message, annotation.Path, annotation.StartLine)
+		}
+	}
+	return summary.String()
+}
+
+// checkRunConclusion maps the verdict of the run to a check run conclusion
+func checkRunConclusion(verdict string) string {
+	switch verdict {
+	case VerdictPass:
+		return "success"
+	case VerdictWarn:
+		return "neutral"
+	}
+	return "failure"
+}
+
+// publishCheckRun reports the findings as a GitHub check run. When the workflow
+// is re-run on the same commit the existing check run is updated with only the
+// annotations that weren't there before, so annotations don't stack up.
+// Failures are logged but never fail the run.
+func publishCheckRun(ctx context.Context, config *Configuration, ciContext map[string]string, report *analysisReport, logger *zap.Logger) {
+	if config.GitHubToken == "" {
+		logger.Warn("github_token is required to publish a check run")
+		return
+	}
+	sha := ciContext["commit"]
+	client := integrations.NewGitHubClient(config.GitHubToken, logger)
+	annotations := checkAnnotations(report.Findings)
+
+	existing, err := client.FindCheckRun(ctx, sha, checkRunName)
+	if err != nil {
+		logger.Warn("Failed to look up previous check run", zap.Error(err))
+	}
+
+	if existing == nil {
+		_, err := client.CreateCheckRun(ctx, integrations.CheckRun{
+			Name:       checkRunName,
+			HeadSHA:    sha,
+			Status:     "completed",
+			Conclusion: checkRunConclusion(report.Verdict),
+			Output: &integrations.CheckRunOutput{
+				Title:       "Governance Analysis Report",
+				Summary:     checkRunSummary(config, report, nil),
+				Annotations: annotations,
+			},
+		})
+		if err != nil {
+			logger.Warn("Failed to create check run", zap.Error(err))
+			report.reportingFailed("check_run", err)
+			return
+		}
+		logger.Info("Published check run", zap.Int("annotations", len(annotations)))
+		return
+	}
+
+	previous, err := client.ListCheckRunAnnotations(ctx, existing.ID)
+	if err != nil {
+		logger.Warn("Failed to list previous annotations", zap.Error(err))
+		report.reportingFailed("check_run", err)
+		return
+	}
+	added, resolved := annotationDelta(previous, annotations)
+
+	err = client.UpdateCheckRun(ctx, existing.ID, integrations.CheckRun{
+		Status:     "completed",
+		Conclusion: checkRunConclusion(report.Verdict),
+		Output: &integrations.CheckRunOutput{
+			Title:       "Governance Analysis Report",
+			Summary:     checkRunSummary(config, report, resolved),
+			Annotations: added,
+		},
+	})
+	if err != nil {
+		logger.Warn("Failed to update check run", zap.Error(err))
+		report.reportingFailed("check_run", err)
+		return
+	}
+	logger.Info("Updated check run", zap.Int("added_annotations", len(added)), zap.Int("resolved_annotations", len(resolved)))
+}
+
+// linkedFindings returns the findings that link to their lines
+func linkedFindings(findings []Finding) []Finding {
+	var linked []Finding
+	for _, finding := range findings {
+		if finding.Permalink != "" {
+			linked = append(linked, finding)
+		}
+	}
+	return linked
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckRunConclusion(t *testing.T) {
+	tests := []struct {
+		verdict string
+		want    string
+	}{
+		{VerdictPass, "success"},
+		{VerdictWarn, "neutral"},
+		{VerdictFail, "failure"},
+		{VerdictError, "failure"},
+		{"", "failure"},
+	}
+	for _, tt := range tests {
+		if got := checkRunConclusion(tt.verdict); got != tt.want {
+			t.Errorf("checkRunConclusion(%q) = %q, want %q", tt.verdict, got, tt.want)
+		}
+	}
+}
+
+// A trend gate fails the run without any finding over the thresholds, the
+// reports must still say so
+func TestReportsShowTheDecidedVerdict(t *testing.T) {
+	config := &Configuration{}
+	tests := []struct {
+		verdict string
+		want    string
+	}{
+		{VerdictPass, "**Passed**"},
+		{VerdictWarn, "not blocking in advisory mode"},
+		{VerdictFail, "❌ **Failed**"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.verdict, func(t *testing.T) {
+			report := &analysisReport{Verdict: tt.verdict, Coverage: &operationCoverage{}}
+			for name, body := range map[string]string{
+				"pull request comment": prCommentBody(config, report),
+				"step summary":         stepSummary(config, report),
+			} {
+				if !strings.Contains(body, tt.want) {
+					t.Errorf("%s doesn't contain %q:\n%s", name, tt.want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestGovernanceLabels(t *testing.T) {
+	errorFinding := testFinding("owasp-rate-limit", "paths", "/users")
+	warning := testFinding("info-contact", "info")
+	warning.Severity = 1
+
+	tests := []struct {
+		name     string
+		findings []Finding
+		verdict  string
+		add      []string
+		remove   []string
+	}{
+		{name: "passing", verdict: VerdictPass, add: []string{labelPassing}, remove: []string{labelErrors, labelOWASPViolation}},
+		{name: "warnings only", findings: []Finding{warning}, verdict: VerdictPass, add: []string{labelPassing}, remove: []string{labelErrors, labelOWASPViolation}},
+		{name: "errors", findings: []Finding{errorFinding}, verdict: VerdictFail, add: []string{labelErrors, labelOWASPViolation}, remove: []string{labelPassing}},
+		{name: "failed trend gate", findings: []Finding{warning}, verdict: VerdictFail, remove: []string{labelPassing, labelErrors, labelOWASPViolation}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			add, remove := governanceLabels(tt.findings, nil, tt.verdict)
+			if !slices.Equal(add, tt.add) {
+				t.Errorf("add = %v, want %v", add, tt.add)
+			}
+			if !slices.Equal(remove, tt.remove) {
+				t.Errorf("remove = %v, want %v", remove, tt.remove)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// codeownersPaths are where GitHub and GitLab look for the CODEOWNERS file,
+// the first existing one is used
+var codeownersPaths = []string{".github/CODEOWNERS", ".gitlab/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersRule assigns owners to the files matching a pattern
+type codeownersRule struct {
+	Pattern string
+	Owners  []string
+}
+
+// codeowners are the rules of a CODEOWNERS file, in file order
+type codeowners []codeownersRule
+
+// loadCodeowners reads the repository's CODEOWNERS file. A repository without
+// one has no owners.
+func loadCodeowners() (codeowners, string, error) {
+	for _, path := range codeownersPaths {
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, path, err
+		}
+		defer file.Close()
+		rules, err := parseCodeowners(file)
+		return rules, path, err
+	}
+	return nil, "", nil
+}
+
+// parseCodeowners parses the lines of a CODEOWNERS file. GitLab section
+// headers are skipped, their rules apply like any other.
+func parseCodeowners(r io.Reader) (codeowners, error) {
+	var rules codeowners
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		if comment := strings.Index(line, " #"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		rules = append(rules, codeownersRule{Pattern: fields[0], Owners: fields[1:]})
+	}
+	return rules, scanner.Err()
+}
+
+// owners returns the owners of a repository path. The last matching rule
+// wins, and may leave the path without owners.
+func (c codeowners) owners(path string) []string {
+	for i := len(c) - 1; i >= 0; i-- {
+		if codeownersMatch(c[i].Pattern, path) {
+			return c[i].Owners
+		}
+	}
+	return nil
+}
+
+// codeownersMatch reports whether a CODEOWNERS pattern matches a repository
+// path. Patterns without an inner slash match at any depth, and patterns naming
+// a directory match the files below it, except ones ending in a wildcard such
+// as docs/*, which only match the directory's own files.
+func codeownersMatch(pattern, path string) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if strings.Contains(pattern, "/") {
+		pattern = "/" + strings.TrimPrefix(pattern, "/")
+	} else {
+		pattern = "**/" + pattern
+	}
+	name := "/" + path
+	if !dirOnly && globMatch(pattern, name) {
+		return true
+	}
+	return !strings.HasSuffix(pattern, "*") && globMatch(pattern+"/**", name)
+}
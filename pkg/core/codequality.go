@@ -0,0 +1,87 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// defaultCodeQualityFile is where the GitLab Code Quality report is written by
+// default
+const defaultCodeQualityFile = "gl-code-quality-report.json"
+
+// codeQualityIssue is an issue of a GitLab Code Quality report
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+// codeQualitySeverity maps a finding severity to a Code Quality severity
+func codeQualitySeverity(severity int) string {
+	switch severity {
+	case 0:
+		return "major"
+	case 1:
+		return "minor"
+	default:
+		return "info"
+	}
+}
+
+// codeQualityReport converts the findings into a GitLab Code Quality report.
+// Code Quality has no suppressions, so excluded findings are left out, and
+// GitLab expects unique fingerprints, so repeated findings are reported once.
+func codeQualityReport(report *analysisReport) []codeQualityIssue {
+	issues := []codeQualityIssue{}
+	seen := map[string]bool{}
+	for _, finding := range report.Findings {
+		fingerprint := finding.Fingerprint()
+		if seen[fingerprint] {
+			continue
+		}
+		seen[fingerprint] = true
+
+		name := finding.Rule.Name
+		if name == "" {
+			name = finding.Code
+		}
+		region := findingRegion(finding)
+		lines := codeQualityLines{Begin: region.StartLine}
+		if region.EndLine > region.StartLine {
+			lines.End = region.EndLine
+		}
+		issues = append(issues, codeQualityIssue{
+			Description: finding.Message,
+			CheckName:   name,
+			Fingerprint: fingerprint,
+			Severity:    codeQualitySeverity(finding.Severity),
+			Location:    codeQualityLocation{Path: repoPath(finding.File), Lines: lines},
+		})
+	}
+	return issues
+}
+
+// writeCodeQuality writes the GitLab Code Quality report of the run to a file
+func writeCodeQuality(report *analysisReport, path string) error {
+	data, err := json.MarshalIndent(codeQualityReport(report), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal Code Quality report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write Code Quality report %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is the repository-level configuration file read when no path is given
+const defaultConfigFile = ".governance.yml"
+
+// FileConfig holds the settings read from the repository configuration file
+type FileConfig struct {
+	// GovernanceService, RuleID, RulesetVersion, APIPath, MaxErrors,
+	// MaxWarnings and OutputFormat are defaults for the inputs of the same
+	// name, which take precedence
+	GovernanceService string   `yaml:"governance_service"`
+	RuleID            string   `yaml:"rule_id"`
+	RulesetVersion    string   `yaml:"ruleset_version"`
+	APIPath           []string `yaml:"api_path"`
+	MaxErrors         *int     `yaml:"max_errors"`
+	MaxWarnings       *int     `yaml:"max_warnings"`
+	OutputFormat      []string `yaml:"output_format"`
+	// Policies select the enforcement mode per branch; the first matching policy wins
+	Policies []BranchPolicy `yaml:"policies"`
+	// Regions map data residency region names to governance service URLs
+	Regions map[string]string `yaml:"regions"`
+	// Rulesets select the ruleset per branch, e.g. a draft ruleset on develop and
+	// the published one on main; the first matching entry wins
+	Rulesets []BranchRuleset `yaml:"rulesets"`
+	// Profiles map environment names to rulesets and thresholds
+	Profiles map[string]Profile `yaml:"profiles"`
+	// Severities customize the label, icon and color of each severity level
+	Severities map[string]SeverityStyle `yaml:"severities"`
+	// SlackChannels map CODEOWNERS owners, e.g. "@org/payments", to the Slack
+	// webhook of their channel. ${VAR} references are expanded from the environment.
+	SlackChannels map[string]string `yaml:"slack_channels"`
+	// RuleLinks link rules to internal style guide pages; the first matching
+	// link wins
+	RuleLinks []RuleLink `yaml:"rule_links"`
+	// Prerequisites are what specs must declare before they're analyzed
+	Prerequisites *Prerequisites `yaml:"prerequisites"`
+	// Approvals check the approval annotations on operations
+	Approvals *Approvals `yaml:"approvals"`
+}
+
+// BranchPolicy binds a branch pattern, the event that triggered the run, or
+// both to an enforcement mode. The ~DEFAULT_BRANCH pattern matches the
+// repository's default branch.
+type BranchPolicy struct {
+	Branch string `yaml:"branch"`
+	Event  string `yaml:"event"`
+	Mode   string `yaml:"mode"`
+}
+
+// BranchRuleset binds a branch pattern to a ruleset and optionally a pinned version
+type BranchRuleset struct {
+	Branch         string `yaml:"branch"`
+	RuleID         string `yaml:"rule_id"`
+	RulesetVersion string `yaml:"ruleset_version"`
+}
+
+// loadFileConfig reads the configuration file. A missing default file is not an
+// error, but an explicitly configured file must exist.
+func loadFileConfig(path string) (*FileConfig, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return &FileConfig{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	if err := validateConfigSchema(content); err != nil {
+		return nil, fmt.Errorf("invalid config file %s:\n%w", path, err)
+	}
+	fileConfig := &FileConfig{}
+	if err := yaml.Unmarshal(content, fileConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return fileConfig, nil
+}
+
+// applyFileDefaults fills the settings the inputs left unset from the
+// configuration file, so the precedence is: command line flags, inputs and
+// environment variables, profiles and branch rulesets, then the file. The
+// settings taken from the file are recorded so profiles and branch rulesets
+// can still replace them.
+func (c *Configuration) applyFileDefaults(file *FileConfig) {
+	source := "the config file"
+	fromFile := func(setting string) {
+		c.override(setting, source)
+		if c.fileDefaults == nil {
+			c.fileDefaults = map[string]bool{}
+		}
+		c.fileDefaults[setting] = true
+	}
+	if c.GovernanceService == "" && file.GovernanceService != "" {
+		c.GovernanceService = file.GovernanceService
+		fromFile("governance_service")
+	}
+	if c.RuleID == "" && file.RuleID != "" {
+		c.RuleID = file.RuleID
+		fromFile("rule_id")
+	}
+	if c.RulesetVersion == "" && file.RulesetVersion != "" {
+		c.RulesetVersion = file.RulesetVersion
+		fromFile("ruleset_version")
+	}
+	if c.APIPath == "" && len(file.APIPath) > 0 {
+		c.APIPath = strings.Join(file.APIPath, ",")
+		fromFile("api_path")
+	}
+	if c.MaxErrors == nil && file.MaxErrors != nil {
+		c.MaxErrors = file.MaxErrors
+		fromFile("max_errors")
+	}
+	if c.MaxWarnings == nil && file.MaxWarnings != nil {
+		c.MaxWarnings = file.MaxWarnings
+		fromFile("max_warnings")
+	}
+	if len(c.OutputFormats) == 0 && len(file.OutputFormat) > 0 {
+		c.OutputFormats = file.OutputFormat
+		fromFile("output_format")
+	}
+}
+
+// unsetOrFromFile reports whether a setting is unset or only defaulted by the
+// configuration file, so a more specific source may set it
+func (c *Configuration) unsetOrFromFile(setting, value string) bool {
+	return value == "" || c.fileDefaults[setting]
+}
+
+// ValidateConfigFile checks a configuration file against the schema and the
+// settings the schema can't express, for pre-merge checks of config changes.
+// The default file is validated when path is empty.
+func ValidateConfigFile(path string) error {
+	if path == "" {
+		path = defaultConfigFile
+	}
+	fileConfig, err := loadFileConfig(path)
+	if err != nil {
+		return err
+	}
+	if err := validateSeverityStyles(fileConfig.Severities); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// ConfigSchema returns the JSON Schema of the configuration file
+func ConfigSchema() []byte {
+	return configSchemaJSON
+}
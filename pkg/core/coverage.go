@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// operationCoverage is how many operations of the analyzed specs the
+// governance gate covers, and which ones it skips and why
+type operationCoverage struct {
+	Operations int
+	Skipped    []skippedOperation
+	// governed are the keys of the operations the findings count on
+	governed map[string]bool
+	// Exempted counts the governed operations with exempted or ignored findings
+	Exempted int
+}
+
+// skippedOperation is an operation whose findings don't count, e.g. because
+// it's deprecated or outside the selected paths
+type skippedOperation struct {
+	File   string
+	Method string
+	Path   string
+	Reason string
+}
+
+// operationKey identifies an operation of a document of a spec file
+func operationKey(file string, document int, section, path, method string) string {
+	return strings.Join([]string{file, strconv.Itoa(document), section, path, method}, "\x00")
+}
+
+// addSpec adds the operations of a spec file's documents, skipping the
+// deprecated ones when they are excluded and the ones outside the selected
+// paths and tags
+func (c *operationCoverage) addSpec(config *Configuration, file string, docs map[int]specDocument, now time.Time) {
+	if c.governed == nil {
+		c.governed = map[string]bool{}
+	}
+	globs := pathGlobs(config.OnlyPaths)
+	scoped := len(config.OnlyPaths) > 0 || len(config.OnlyTags) > 0
+	for index, doc := range docs {
+		for _, section := range []string{"paths", "webhooks"} {
+			for path, methods := range doc.operations(section) {
+				for method, operation := range methods {
+					c.Operations++
+					reason := ""
+					if config.ExcludeDeprecated {
+						reason = deprecationReason(operation, now)
+					}
+					if reason == "" && scoped && !inScope([]string{section, path, method}, doc, globs, config.OnlyTags) {
+						reason = outOfScopeReason
+					}
+					if reason != "" {
+						c.Skipped = append(c.Skipped, skippedOperation{File: file, Method: strings.ToUpper(method), Path: path, Reason: reason})
+						continue
+					}
+					c.governed[operationKey(file, index, section, path, method)] = true
+				}
+			}
+		}
+	}
+}
+
+// countExempted counts the governed operations with excluded findings, such as
+// exempted ones, whose rules are partly waived
+func (c *operationCoverage) countExempted(excluded []Finding) {
+	exempted := map[string]bool{}
+	for _, finding := range excluded {
+		path := finding.Path
+		if len(path) < 3 {
+			continue
+		}
+		key := operationKey(finding.spec(), finding.Document, path[0], path[1], strings.ToLower(path[2]))
+		if c.governed[key] {
+			exempted[key] = true
+		}
+	}
+	c.Exempted = len(exempted)
+}
+
+// governedCount returns the number of operations the findings count on
+func (c *operationCoverage) governedCount() int {
+	return len(c.governed)
+}
+
+// percent returns the share of governed operations, 100 for specs without any
+func (c *operationCoverage) percent() int {
+	if c.Operations == 0 {
+		return 100
+	}
+	return c.governedCount() * 100 / c.Operations
+}
+
+// setCoverageOutputs sets the governed and skipped operation counts as outputs
+func setCoverageOutputs(config *Configuration, coverage *operationCoverage) {
+	setOutput(config, "governed_operation_count", fmt.Sprintf("%d", coverage.governedCount()))
+	setOutput(config, "skipped_operation_count", fmt.Sprintf("%d", len(coverage.Skipped)))
+}
+
+// printCoverage prints the coverage section of the console report, listing
+// the skipped operations
+func printCoverage(w io.Writer, config *Configuration, coverage *operationCoverage) {
+	if coverage == nil || coverage.Operations == 0 {
+		return
+	}
+	printHeading(w, config.text("report.coverage"))
+	fmt.Fprintln(w, "    "+config.text("report.coverage_line", coverage.governedCount(), coverage.Operations, coverage.percent()))
+	if coverage.Exempted > 0 {
+		fmt.Fprintln(w, "    "+config.text("report.coverage_exempted", coverage.Exempted))
+	}
+	skipped := coverage.Skipped
+	sort.SliceStable(skipped, func(i, j int) bool {
+		if skipped[i].File != skipped[j].File {
+			return skipped[i].File < skipped[j].File
+		}
+		if skipped[i].Path != skipped[j].Path {
+			return skipped[i].Path < skipped[j].Path
+		}
+		return skipped[i].Method < skipped[j].Method
+	})
+	// Name the spec file when several were analyzed
+	multiFile := slices.ContainsFunc(skipped, func(operation skippedOperation) bool { return operation.File != skipped[0].File })
+	for _, operation := range skipped {
+		if multiFile {
+			fmt.Fprintf(w, "    ⏭️ %s: %s %s (%s)\n", repoPath(operation.File), operation.Method, operation.Path, operation.Reason)
+			continue
+		}
+		fmt.Fprintf(w, "    ⏭️ %s %s (%s)\n", operation.Method, operation.Path, operation.Reason)
+	}
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secret values in the environment dump
+const redacted = "[REDACTED]"
+
+// secretMarkers identify variables holding credentials by name
+var secretMarkers = []string{"AUTH", "TOKEN", "SECRET", "PASSWORD", "PRIVATE", "KEY", "WEBHOOK"}
+
+// ciVariables are the CI variables the action reads its context from
+var ciVariables = []string{
+	"GITHUB_ACTIONS", "GITHUB_REPOSITORY", "GITHUB_EVENT_NAME", "GITHUB_SHA", "GITHUB_REF", "GITHUB_REF_NAME",
+	"GITHUB_HEAD_REF", "GITHUB_SERVER_URL", "GITHUB_WORKSPACE", "GITHUB_RUN_ID", "GITHUB_OUTPUT",
+	"GITLAB_CI", "CI_PROJECT_PATH", "CI_PROJECT_URL", "CI_PROJECT_DIR", "CI_COMMIT_SHA", "CI_COMMIT_BRANCH",
+	"CI_MERGE_REQUEST_IID", "CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", "CI_JOB_URL",
+}
+
+// inputsRead records the inputs the run read, for the environment dump
+var inputsRead = struct {
+	sync.Mutex
+	names map[string]bool
+}{names: map[string]bool{}}
+
+// recordInput remembers that an input was read
+func recordInput(name string) {
+	inputsRead.Lock()
+	defer inputsRead.Unlock()
+	inputsRead.names[name] = true
+}
+
+// inputFlags records the inputs set by command line flags, by input name, for
+// the environment dump
+var inputFlags = struct {
+	sync.Mutex
+	flags map[string]string
+}{flags: map[string]string{}}
+
+// recordInputFlag remembers that an input was set by a command line flag
+func recordInputFlag(name, flag string) {
+	inputFlags.Lock()
+	defer inputFlags.Unlock()
+	inputFlags.flags[name] = flag
+}
+
+// variableSource names where a variable was set, the command line flag for the
+// INPUT_ variables flags set
+func variableSource(variable string) string {
+	inputFlags.Lock()
+	defer inputFlags.Unlock()
+	if flag, ok := inputFlags.flags[strings.TrimPrefix(variable, "INPUT_")]; ok && strings.HasPrefix(variable, "INPUT_") {
+		return flag
+	}
+	return variable
+}
+
+// override records that a setting was changed after it was read from the
+// environment, e.g. by a profile, for the environment dump
+func (c *Configuration) override(setting, source string) {
+	if c.overrides == nil {
+		c.overrides = map[string]string{}
+	}
+	c.overrides[setting] = source
+}
+
+// printDebugEnv prints the governance variables with secrets redacted, how each
+// core setting was resolved through its fallback chain and what overrode it
+func printDebugEnv(w io.Writer, config *Configuration) {
+	settings := []struct {
+		name     string
+		chain    []string
+		resolved string
+	}{
+		{"governance_service", governanceServiceChain, config.GovernanceService},
+		{"governance_auth", governanceAuthChain, config.GovernanceAuth},
+		{"rule_id", ruleIDChain, config.RuleID},
+		{"api_path", apiPathChain, config.APIPath},
+		{"mocked", mockedChain, config.Mocked},
+	}
+
+	printHeading(w, "Settings")
+	chained := map[string]bool{}
+	for _, setting := range settings {
+		chained[setting.name] = true
+		fmt.Fprintf(w, "    %s = %s\n", setting.name, displayValue(setting.chain[0], setting.resolved))
+		chosen := false
+		for _, name := range setting.chain {
+			value := os.Getenv(name)
+			marker := " "
+			if value != "" && !chosen {
+				marker, chosen = "→", true
+			}
+			fmt.Fprintf(w, "      %s %-26s %s\n", marker, variableSource(name), displayValue(name, value))
+		}
+		if source, ok := config.overrides[setting.name]; ok {
+			fmt.Fprintf(w, "      → overridden by %s\n", source)
+		}
+	}
+	overridden := make([]string, 0, len(config.overrides))
+	for name := range config.overrides {
+		if !chained[name] {
+			overridden = append(overridden, name)
+		}
+	}
+	sort.Strings(overridden)
+	for _, name := range overridden {
+		fmt.Fprintf(w, "    %s overridden by %s\n", name, config.overrides[name])
+	}
+
+	// Inputs prefer the INPUT_ variable GitHub Actions sets over the plain name
+	printHeading(w, "Inputs")
+	inputsRead.Lock()
+	names := make([]string, 0, len(inputsRead.names))
+	for name := range inputsRead.names {
+		names = append(names, name)
+	}
+	inputsRead.Unlock()
+	sort.Strings(names)
+	for _, name := range names {
+		for _, variable := range []string{"INPUT_" + name, name} {
+			if value := os.Getenv(variable); value != "" {
+				fmt.Fprintf(w, "    %s = %s (from %s)\n", strings.ToLower(name), displayValue(variable, value), variableSource(variable))
+				break
+			}
+		}
+	}
+
+	printHeading(w, "CI")
+	for _, name := range ciVariables {
+		if value := os.Getenv(name); value != "" {
+			fmt.Fprintf(w, "    %s = %s\n", name, displayValue(name, value))
+		}
+	}
+	fmt.Fprintln(w, "---------------------------------------")
+}
+
+// displayValue redacts secret variables and credentials in URLs
+func displayValue(name, value string) string {
+	if value == "" {
+		return "(unset)"
+	}
+	upper := strings.ToUpper(name)
+	for _, marker := range secretMarkers {
+		if strings.Contains(upper, marker) {
+			return redacted
+		}
+	}
+	if parsed, err := url.Parse(value); err == nil && parsed.User != nil {
+		parsed.User = url.User("REDACTED")
+		return parsed.String()
+	}
+	return value
+}
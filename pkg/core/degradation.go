@@ -0,0 +1,56 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// reportingError is a failure to publish the results through an integration,
+// e.g. a rate limited or unavailable provider API. It degrades the reporting
+// but never the verdict.
+type reportingError struct {
+	Integration string // Input enabling the integration, e.g. check_run
+	Error       string
+}
+
+// reportingFailed records that publishing through an integration failed,
+// keeping the first failure of each integration
+func (r *analysisReport) reportingFailed(integration string, err error) {
+	if r == nil {
+		return
+	}
+	for _, failure := range r.ReportingErrors {
+		if failure.Integration == integration {
+			return
+		}
+	}
+	r.ReportingErrors = append(r.ReportingErrors, reportingError{Integration: integration, Error: err.Error()})
+}
+
+// setReportingOutputs sets the integrations that failed to publish as the
+// reporting_errors output, empty when reporting wasn't degraded
+func setReportingOutputs(config *Configuration, report *analysisReport, logger *zap.Logger) {
+	integrations := make([]string, 0, len(report.ReportingErrors))
+	for _, failure := range report.ReportingErrors {
+		integrations = append(integrations, failure.Integration)
+	}
+	if len(integrations) > 0 {
+		logger.Warn("Reporting degraded, the verdict stands", zap.Strings("integrations", integrations))
+	}
+	setOutput(config, "reporting_errors", strings.Join(integrations, ","))
+}
+
+// printReportingErrors prints the reporting errors section of the console report
+func printReportingErrors(w io.Writer, config *Configuration, failures []reportingError) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	printHeading(w, config.text("report.reporting_errors"))
+	for _, failure := range failures {
+		fmt.Fprintf(w, "    %s: %s\n", failure.Integration, failure.Error)
+	}
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// publishDeploymentGate gates the deployment of the analyzed commit on the
+// verdict. Runs for a custom deployment protection rule approve or reject the
+// waiting deployment. Otherwise the status of the deployment being run, or of
+// a deployment created in deployment_environment, is set to success or
+// failure. Failures are logged but never fail the run.
+func publishDeploymentGate(ctx context.Context, config *Configuration, ci string, ciContext map[string]string, result *RunResult, logger *zap.Logger) {
+	if config.GitHubToken == "" {
+		logger.Warn("github_token is required to gate deployments")
+		return
+	}
+	client := integrations.NewGitHubClient(config.GitHubToken, logger)
+	description := result.description()
+
+	if callbackURL := ciContext["deployment_callback_url"]; callbackURL != "" {
+		state := "rejected"
+		if result.proceeds() {
+			state = "approved"
+		}
+		if err := client.ReviewDeploymentProtectionRule(ctx, callbackURL, ciContext["environment"], state, description); err != nil {
+			logger.Warn("Failed to review the deployment protection rule", zap.Error(err))
+			result.report.reportingFailed("deployment_gate", err)
+			return
+		}
+		logger.Info("Reviewed deployment protection rule", zap.String("environment", ciContext["environment"]), zap.String("state", state))
+		return
+	}
+
+	deploymentID, environment := config.DeploymentID, config.DeployEnvironment
+	if deploymentID == "" {
+		deploymentID, environment = ciContext["deployment_id"], ciContext["environment"]
+	}
+	id, _ := strconv.ParseInt(deploymentID, 10, 64)
+	if id == 0 {
+		if config.DeployEnvironment == "" {
+			logger.Warn("Not running for a deployment, set deployment_environment to create one")
+			return
+		}
+		deployment, err := client.CreateDeployment(ctx, ciContext["commit"], config.DeployEnvironment, "Governance check")
+		if err != nil {
+			logger.Warn("Failed to create deployment", zap.String("environment", config.DeployEnvironment), zap.Error(err))
+			result.report.reportingFailed("deployment_gate", err)
+			return
+		}
+		id, environment = deployment.ID, config.DeployEnvironment
+	}
+
+	state := "failure"
+	if result.proceeds() {
+		state = "success"
+	}
+	status := integrations.DeploymentStatus{State: state, Description: description, LogURL: runURL(ci)}
+	if err := client.CreateDeploymentStatus(ctx, id, status); err != nil {
+		logger.Warn("Failed to set deployment status", zap.Int64("deployment_id", id), zap.Error(err))
+		result.report.reportingFailed("deployment_gate", err)
+		return
+	}
+	setOutput(config, "deployment_id", strconv.FormatInt(id, 10))
+	logger.Info("Set deployment status", zap.Int64("deployment_id", id), zap.String("environment", environment), zap.String("state", state))
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sunsetLayouts are the accepted x-sunset date formats: dates, RFC 3339
+// timestamps and HTTP dates as used by the Sunset header
+var sunsetLayouts = []string{"2006-01-02", time.RFC3339, time.RFC1123}
+
+// operationFor returns the operation a finding path points into, or nil when the
+// finding isn't inside an operation of a path or an OpenAPI 3.1 webhook
+func operationFor(doc specDocument, path []string) map[string]interface{} {
+	if len(path) < 3 || (path[0] != "paths" && path[0] != "webhooks") {
+		return nil
+	}
+	pathItem, ok := doc.object(path[0])[path[1]].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	operation, _ := pathItem[strings.ToLower(path[2])].(map[string]interface{})
+	return operation
+}
+
+// deprecationReason returns why an operation is considered deprecated, or "" if it isn't
+func deprecationReason(operation map[string]interface{}, now time.Time) string {
+	if deprecated, _ := operation["deprecated"].(bool); deprecated {
+		return "operation is deprecated"
+	}
+	if sunset, ok := parseSunset(operation["x-sunset"]); ok && sunset.Before(now) {
+		return fmt.Sprintf("operation was sunset on %s", sunset.Format("2006-01-02"))
+	}
+	return ""
+}
+
+// parseSunset parses an x-sunset value, which YAML may already have decoded as a timestamp
+func parseSunset(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range sunsetLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// excludeDeprecated splits findings into the ones to keep and the ones on
+// deprecated or sunset operations, which are about to be removed anyway. The
+// docs are the parsed documents of the spec file by index.
+func excludeDeprecated(findings []Finding, docs map[int]specDocument, now time.Time) (kept, excluded []Finding) {
+	for _, finding := range findings {
+		if operation := operationFor(docs[finding.Document], finding.Path); operation != nil {
+			if reason := deprecationReason(operation, now); reason != "" {
+				finding.ExclusionReason = reason
+				excluded = append(excluded, finding)
+				continue
+			}
+		}
+		kept = append(kept, finding)
+	}
+	return kept, excluded
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// maxSlowRules caps the rules listed in the diagnostics section
+const maxSlowRules = 5
+
+// ruleTime is the evaluation time of a rule
+type ruleTime struct {
+	Rule     string
+	Duration time.Duration
+}
+
+// slowestRules returns the rules that took longest to evaluate, slowest first
+func slowestRules(diagnostics *integrations.Diagnostics, limit int) []ruleTime {
+	times := make([]ruleTime, 0, len(diagnostics.RuleTimes))
+	for rule, duration := range diagnostics.RuleTimes {
+		times = append(times, ruleTime{Rule: rule, Duration: duration})
+	}
+	sort.Slice(times, func(i, j int) bool {
+		if times[i].Duration != times[j].Duration {
+			return times[i].Duration > times[j].Duration
+		}
+		return times[i].Rule < times[j].Rule
+	})
+	if len(times) > limit {
+		times = times[:limit]
+	}
+	return times
+}
+
+// printDiagnostics prints the evaluation time and the slowest rules the
+// governance service reported, for ruleset authors optimizing custom rules
+func printDiagnostics(w io.Writer, config *Configuration, diagnostics *integrations.Diagnostics) {
+	if diagnostics == nil {
+		return
+	}
+	printHeading(w, config.text("report.diagnostics"))
+	fmt.Fprintln(w, "    "+config.text("report.evaluation_time", diagnostics.EvaluationTime.Round(time.Millisecond)))
+	slowest := slowestRules(diagnostics, maxSlowRules)
+	if len(slowest) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "    "+config.text("report.slowest_rules"))
+	for _, slow := range slowest {
+		fmt.Fprintf(w, "    %10s  %s\n", slow.Duration.Round(time.Millisecond), slow.Rule)
+	}
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// diffBaseAuto compares against blame_base or the pull or merge request base
+const diffBaseAuto = "auto"
+
+// resolveDiffBase returns the revision diff_base compares the specs at, or ""
+// when unset or no base is known
+func resolveDiffBase(config *Configuration, ciContext map[string]string) string {
+	if config.DiffBase == diffBaseAuto {
+		return blameBase(config, ciContext)
+	}
+	return config.DiffBase
+}
+
+// baseAnalysis is the analysis of the specs at the base revision
+type baseAnalysis struct {
+	*concurrentAnalysis
+	base string
+	// unchanged are the specs the change didn't touch, by path. Their findings
+	// at the base are the head findings, so they aren't analyzed twice.
+	unchanged map[string]bool
+}
+
+// startBaseAnalysis analyzes the specs at the base revision in the background,
+// while the working tree is analyzed. Specs the base doesn't have are new, so
+// all their findings are. Git specs are skipped, they name their revision.
+func startBaseAnalysis(ctx context.Context, config *Configuration, client *integrations.GovernanceClient, targets []specTarget, base string, logger *zap.Logger) *baseAnalysis {
+	analysis := &baseAnalysis{base: base, unchanged: map[string]bool{}}
+	var baseTargets []specTarget
+	for _, target := range targets {
+		if _, _, ok := parseGitSpec(target.Path); ok {
+			continue
+		}
+		spec := gitSpecPath(base, repoPath(target.Path))
+		baseContent, err := readGitSpec(spec, config.MaxSpecSize)
+		if err != nil {
+			logger.Info("Spec is new since the base revision", zap.String("path", target.Path), zap.String("base", base))
+			continue
+		}
+		if content, err := readOASFile(target.Path, config.MaxSpecSize); err == nil && content == baseContent {
+			analysis.unchanged[target.Path] = true
+			continue
+		}
+		// The base findings aren't recorded on the API
+		baseTargets = append(baseTargets, specTarget{Path: spec, Format: target.Format})
+	}
+	logger.Info("Analyzing specs at the base revision", zap.String("base", base),
+		zap.Int("changed", len(baseTargets)), zap.Int("unchanged", len(analysis.unchanged)))
+	analysis.concurrentAnalysis = startAnalysis(ctx, config, client, baseTargets, logger)
+	return analysis
+}
+
+// compare correlates the base findings with the head findings by fingerprint.
+// Findings the head excludes, e.g. as exempted, aren't resolved. The
+// comparison never affects the verdict, failures are logged and reported as a
+// nil delta.
+func (a *baseAnalysis) compare(config *Configuration, client *integrations.GovernanceClient, report *analysisReport, logger *zap.Logger) *findingDelta {
+	findings, err := a.wait(client)
+	if err != nil {
+		logger.Warn("Failed to analyze the specs at the base revision, skipping the comparison", zap.String("base", a.base), zap.Error(err))
+		return nil
+	}
+	// Base findings are fingerprinted as if in the working tree
+	for i := range findings {
+		if _, file, ok := parseGitSpec(findings[i].File); ok {
+			findings[i].File = file
+		}
+		if _, file, ok := parseGitSpec(findings[i].Spec); ok {
+			findings[i].Spec = file
+		}
+	}
+	for _, head := range [][]Finding{report.Findings, report.Excluded} {
+		for _, finding := range head {
+			if a.unchanged[finding.spec()] {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	delta := diffFindings(findings, report.Findings)
+	delta.Resolved = withoutExcluded(delta.Resolved, report.Excluded)
+	setOutput(config, "diff_added_count", strconv.Itoa(len(delta.Added)))
+	setOutput(config, "diff_resolved_count", strconv.Itoa(len(delta.Resolved)))
+	return &delta
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// documentAll analyzes every document of a multi-document YAML file
+const documentAll = "all"
+
+// documentStart matches the --- line starting a YAML document, with any content
+// that follows it on the same line
+var documentStart = regexp.MustCompile(`^---(?:\s+(.*))?$`)
+
+// yamlDocument is one document of a spec file
+type yamlDocument struct {
+	Index   int // 1-based position in a multi-document file, 0 for single-document files
+	Offset  int // Lines before the document in the file
+	Content string
+}
+
+// splitDocuments splits a spec file into its YAML documents, leaving out empty
+// ones. A file with a single document is returned whole.
+func splitDocuments(content string) []yamlDocument {
+	var documents []yamlDocument
+	var current []string
+	offset := 0
+	flush := func() {
+		if text := strings.Join(current, "\n"); hasContent(text) {
+			documents = append(documents, yamlDocument{Index: len(documents) + 1, Offset: offset, Content: text})
+		}
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if match := documentStart.FindStringSubmatch(line); match != nil {
+			flush()
+			current, offset = nil, i+1
+			if match[1] != "" {
+				// Content after the marker stays on the document's first line
+				current, offset = []string{match[1]}, i
+			}
+			continue
+		}
+		if line == "..." {
+			flush()
+			current, offset = nil, i+1
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	if len(documents) <= 1 {
+		return []yamlDocument{{Content: content}}
+	}
+	return documents
+}
+
+// hasContent reports whether a document has anything besides blank lines and comments
+func hasContent(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+// selectDocuments returns the documents to analyze: all of them, or the one with
+// the 1-based index of the document input
+func selectDocuments(documents []yamlDocument, selector string) ([]yamlDocument, error) {
+	if selector == "" || selector == documentAll {
+		return documents, nil
+	}
+	index, _ := strconv.Atoi(selector)
+	if len(documents) == 1 && index == 1 {
+		return documents, nil
+	}
+	for _, document := range documents {
+		if document.Index == index {
+			return []yamlDocument{document}, nil
+		}
+	}
+	return nil, fmt.Errorf("document %d not found, the file has %d YAML documents", index, len(documents))
+}
+
+// validateDocumentSelector checks the document input is all or a 1-based index
+func validateDocumentSelector(selector string) error {
+	if selector == "" || selector == documentAll {
+		return nil
+	}
+	if index, err := strconv.Atoi(selector); err != nil || index < 1 {
+		return fmt.Errorf("document must be %s or the 1-based index of a YAML document, got %q", documentAll, selector)
+	}
+	return nil
+}
+
+// findings wraps the results of analyzing the document, moving their lines from
+// the document to the file
+func (d yamlDocument) findings(results []integrations.LintResult, source string) []Finding {
+	for i := range results {
+		// Services that don't echo the source still report findings for this spec
+		if results[i].Source == "" {
+			results[i].Source = source
+		}
+		results[i].Range.Start.Line += d.Offset
+		results[i].Range.End.Line += d.Offset
+	}
+	findings := newFindings(results, d.Content)
+	for i := range findings {
+		findings[i].Document = d.Index
+		if findings[i].Location.StartLine > 0 {
+			findings[i].Location.StartLine += d.Offset
+			findings[i].Location.EndLine += d.Offset
+		}
+	}
+	return findings
+}
+
+// parseSpecDocuments parses every document of a spec file by its index, leaving
+// out the ones that don't parse
+func parseSpecDocuments(content string) map[int]specDocument {
+	docs := map[int]specDocument{}
+	for _, document := range splitDocuments(content) {
+		if doc, err := parseSpec(document.Content); err == nil {
+			docs[document.Index] = doc
+		}
+	}
+	return docs
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileLockTimeout bounds the wait for another run writing the same file.
+// Older locks are left over from a killed run and are taken over.
+const fileLockTimeout = 10 * time.Second
+
+// fileLockRetry is the interval between attempts to take a lock
+const fileLockRetry = 20 * time.Millisecond
+
+// dotenvMu serializes the writes of this process, the lock file those of
+// concurrent runs sharing the file, e.g. one per spec in the same job
+var dotenvMu sync.Mutex
+
+// writeDotenv sets a variable in a dotenv file, replacing an earlier value of
+// the same name and keeping the others. The file is replaced atomically so
+// GitLab and concurrent runs never read a partially written file.
+func writeDotenv(path, name, value string) error {
+	dotenvMu.Lock()
+	defer dotenvMu.Unlock()
+
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// dotenv reports don't support multiline values
+	line := name + "=" + strings.ReplaceAll(value, "\n", " ")
+	var out bytes.Buffer
+	replaced := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		existing := scanner.Text()
+		if key, _, ok := strings.Cut(existing, "="); ok && strings.TrimSpace(key) == name {
+			if replaced {
+				continue
+			}
+			existing, replaced = line, true
+		}
+		out.WriteString(existing + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if !replaced {
+		out.WriteString(line + "\n")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// lockFile takes the lock file next to a file shared by concurrent runs and
+// returns the function releasing it
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(fileLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > fileLockTimeout {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s", lockPath)
+		}
+		time.Sleep(fileLockRetry)
+	}
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteDotenv(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   string
+		key, value string
+		want       string
+	}{
+		{name: "new file", key: "verdict", value: "pass", want: "verdict=pass\n"},
+		{name: "appends", existing: "errors=1\n", key: "verdict", value: "fail", want: "errors=1\nverdict=fail\n"},
+		{name: "replaces in place", existing: "verdict=pass\nerrors=0\n", key: "verdict", value: "fail", want: "verdict=fail\nerrors=0\n"},
+		{name: "drops duplicates", existing: "verdict=pass\nverdict = warn\nerrors=0\n", key: "verdict", value: "fail", want: "verdict=fail\nerrors=0\n"},
+		{name: "keeps other lines", existing: "# comment\nverdict_url=x\n", key: "verdict", value: "pass", want: "# comment\nverdict_url=x\nverdict=pass\n"},
+		{name: "flattens multiline values", key: "summary", value: "one\ntwo", want: "summary=one two\n"},
+		{name: "missing final newline", existing: "errors=1", key: "verdict", value: "pass", want: "errors=1\nverdict=pass\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "governance.env")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writeDotenv(path, tt.key, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+				t.Errorf("lock file left behind: %v", err)
+			}
+		})
+	}
+}
+
+// Runs sharing the file, e.g. one per spec in a job, must not lose each
+// other's variables
+func TestWriteDotenvConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "governance.env")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := writeDotenv(path, fmt.Sprintf("var_%02d", i), "value"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	sort.Strings(lines)
+	if len(lines) != 20 {
+		t.Fatalf("got %d variables, want 20:\n%s", len(lines), content)
+	}
+	for i, line := range lines {
+		if want := fmt.Sprintf("var_%02d=value", i); line != want {
+			t.Errorf("line %d: got %q, want %q", i, line, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// concurrentAnalysis is a second analysis of the specs running alongside the
+// main one, e.g. at the base revision or against the latest ruleset version,
+// so diff-based modes take about as long as a single analysis
+type concurrentAnalysis struct {
+	client   *integrations.GovernanceClient
+	done     chan struct{}
+	findings []Finding
+	err      error
+}
+
+// startAnalysis analyzes the targets in the background with a fork of the
+// client, excluding the findings the organization policy ignores and approved,
+// deprecated and out of scope findings like the main analysis. The first
+// failure ends it.
+func startAnalysis(ctx context.Context, config *Configuration, client *integrations.GovernanceClient, targets []specTarget, logger *zap.Logger) *concurrentAnalysis {
+	analysis := &concurrentAnalysis{client: client.Fork(), done: make(chan struct{})}
+	go func() {
+		defer close(analysis.done)
+		for _, target := range targets {
+			findings, content, err := analyzeTarget(ctx, config, analysis.client, target, logger)
+			if err != nil {
+				analysis.err = err
+				return
+			}
+			findings, _ = config.OrgPolicy.filter(findings)
+			docs := parseSpecDocuments(content)
+			_, findings, _ = collectApprovals(config, target.Path, findings, docs)
+			if config.ExcludeDeprecated && len(docs) > 0 {
+				findings, _ = excludeDeprecated(findings, docs, time.Now())
+			}
+			findings, _ = excludeOutOfScope(findings, docs, config.OnlyPaths, config.OnlyTags)
+			analysis.findings = append(analysis.findings, findings...)
+		}
+	}()
+	return analysis
+}
+
+// wait waits for the analysis to finish and adds its requests to the
+// statistics of the main client
+func (a *concurrentAnalysis) wait(client *integrations.GovernanceClient) ([]Finding, error) {
+	<-a.done
+	client.Join(a.client)
+	return a.findings, a.err
+}
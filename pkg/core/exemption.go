@@ -0,0 +1,192 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// exemptCommand matches the pull request comment requesting an exemption:
+// /governance exempt <fingerprint> <justification>
+var exemptCommand = regexp.MustCompile(`(?m)^/governance\s+exempt\s+([0-9a-f]{16})\s+(\S.*)$`)
+
+// exemptionCommand is a request to exempt a finding, from a comment or the command line
+type exemptionCommand struct {
+	Fingerprint   string
+	Justification string
+	RequestedBy   string
+	PullRequest   string
+}
+
+// parseExemptionCommand extracts the fingerprint and justification from a comment
+func parseExemptionCommand(body string) (fingerprint, justification string, ok bool) {
+	match := exemptCommand.FindStringSubmatch(body)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], strings.TrimSpace(match[2]), true
+}
+
+// exemptionFromComment returns the exemption requested by the pull request
+// comment that triggered the run, or nil if the run wasn't triggered by one
+func exemptionFromComment(ci string, ciContext map[string]string, logger *zap.Logger) *exemptionCommand {
+	if ci != "github" || ciContext["event"] != "issue_comment" {
+		return nil
+	}
+	comment, err := integrations.ReadGitHubComment(os.Getenv("GITHUB_EVENT_PATH"))
+	if err != nil {
+		logger.Warn("Failed to read the triggering comment", zap.Error(err))
+		return nil
+	}
+	fingerprint, justification, ok := parseExemptionCommand(comment.Body)
+	if !ok || comment.PullRequest == "" {
+		return nil
+	}
+	return &exemptionCommand{
+		Fingerprint:   fingerprint,
+		Justification: justification,
+		RequestedBy:   comment.Author,
+		PullRequest:   comment.PullRequest,
+	}
+}
+
+// requestExemption submits an exemption request for the finding with the
+// command's fingerprint. In mocked mode the request is only printed.
+func requestExemption(ctx context.Context, config *Configuration, client *integrations.GovernanceClient, ciContext map[string]string, command *exemptionCommand, findings []Finding, targets []specTarget, logger *zap.Logger) error {
+	var finding *Finding
+	for i := range findings {
+		if findings[i].Fingerprint() == command.Fingerprint {
+			finding = &findings[i]
+			break
+		}
+	}
+	if finding == nil {
+		return fmt.Errorf("no finding with fingerprint %s, it may have been fixed already", command.Fingerprint)
+	}
+
+	request := integrations.ExemptionRequest{
+		Fingerprint:   command.Fingerprint,
+		RulesetID:     config.RuleID,
+		Rule:          finding.Rule.Name,
+		Path:          finding.Path,
+		Message:       finding.Message,
+		File:          repoPath(finding.File),
+		Repository:    ciContext["repository"],
+		PullRequest:   command.PullRequest,
+		Justification: command.Justification,
+		RequestedBy:   command.RequestedBy,
+	}
+	for _, target := range targets {
+		if target.Path == finding.File {
+			request.APIID, request.APIName = target.APIID, target.APIName
+		}
+	}
+
+	if client == nil {
+		logger.Info("Mocked mode, not submitting the exemption request", zap.Any("request", request))
+		return nil
+	}
+	exemption, err := client.RequestExemption(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to request exemption: %w", err)
+	}
+	logger.Info("Requested exemption", zap.String("fingerprint", command.Fingerprint),
+		zap.String("rule", finding.Rule.Name), zap.String("exemption_id", exemption.ID), zap.String("status", exemption.Status))
+	setOutput(config, "exemption_id", exemption.ID)
+	setOutput(config, "exemption_status", exemption.Status)
+	return nil
+}
+
+// applyExemptions moves findings covered by an approved, unexpired exemption out
+// of the counted findings, returning the remaining and the exempted findings
+func applyExemptions(findings []Finding, exemptions []integrations.Exemption, now time.Time) (kept, exempted []Finding) {
+	byFingerprint := map[string]integrations.Exemption{}
+	for _, exemption := range exemptions {
+		if exemption.Status != integrations.ExemptionApproved || exemption.Fingerprint == "" {
+			continue
+		}
+		if exemption.ExpiresAt != nil && now.After(*exemption.ExpiresAt) {
+			continue
+		}
+		byFingerprint[exemption.Fingerprint] = exemption
+	}
+
+	for _, finding := range findings {
+		exemption, ok := byFingerprint[finding.Fingerprint()]
+		if !ok {
+			kept = append(kept, finding)
+			continue
+		}
+		finding.ExclusionReason = "exempted by " + exemption.ID
+		if exemption.ExpiresAt != nil {
+			finding.ExclusionReason += " until " + exemption.ExpiresAt.Format("2006-01-02")
+		}
+		exempted = append(exempted, finding)
+	}
+	return kept, exempted
+}
+
+// honorExemptions applies the approved exemptions from the governance service to
+// the report. Failures are logged but never fail the run.
+func honorExemptions(ctx context.Context, client *integrations.GovernanceClient, ciContext map[string]string, report *analysisReport, logger *zap.Logger) {
+	exemptions, err := client.ListApprovedExemptions(ctx, ciContext["repository"])
+	if err != nil {
+		logger.Warn("Failed to fetch approved exemptions, counting all findings", zap.Error(err))
+		return
+	}
+	var exempted []Finding
+	report.Findings, exempted = applyExemptions(report.Findings, exemptions, time.Now())
+	report.Excluded = append(report.Excluded, exempted...)
+	if len(exempted) > 0 {
+		logger.Info("Applied approved exemptions", zap.Int("exempted_count", len(exempted)))
+	}
+}
+
+// RunExemption requests an exemption for the finding with the given fingerprint
+// from the command line, analyzing the configured specs to look it up
+func RunExemption(logger *zap.Logger, fingerprint, justification string) error {
+	config, err := getConfiguration()
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	targets, err := resolveTargets(config)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	ctx := context.Background()
+	client, _, err := connectGovernance(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+	var findings []Finding
+	for _, target := range targets {
+		targetFindings, _, err := analyzeTarget(ctx, config, client, target, logger)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, targetFindings...)
+	}
+
+	ci := integrations.DetectCI()
+	ciContext := integrations.GetContext(ci)
+	command := &exemptionCommand{
+		Fingerprint:   fingerprint,
+		Justification: justification,
+		RequestedBy:   ciContext["actor"],
+		PullRequest:   ciContext["pull_request"],
+	}
+	if command.RequestedBy == "" {
+		command.RequestedBy = os.Getenv("USER")
+	}
+	return requestExemption(ctx, config, client, ciContext, command, findings, targets, logger)
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+func TestParseExemptionCommand(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		fingerprint, why string
+		ok               bool
+	}{
+		{name: "command", body: "/governance exempt fb05a525d82170ab Rate limits are enforced by the gateway", fingerprint: "fb05a525d82170ab", why: "Rate limits are enforced by the gateway", ok: true},
+		{name: "in a longer comment", body: "Thanks!\n/governance  exempt fb05a525d82170ab  internal API  \nCheers", fingerprint: "fb05a525d82170ab", why: "internal API", ok: true},
+		{name: "no justification", body: "/governance exempt fb05a525d82170ab"},
+		{name: "short fingerprint", body: "/governance exempt fb05a525 internal API"},
+		{name: "quoted", body: "> /governance exempt fb05a525d82170ab internal API"},
+		{name: "other comment", body: "LGTM"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fingerprint, why, ok := parseExemptionCommand(tt.body)
+			if fingerprint != tt.fingerprint || why != tt.why || ok != tt.ok {
+				t.Errorf("got %q, %q, %v, want %q, %q, %v", fingerprint, why, ok, tt.fingerprint, tt.why, tt.ok)
+			}
+		})
+	}
+}
+
+func TestApplyExemptions(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(24*time.Hour)
+	rateLimit := testFinding("owasp-rate-limit", "paths", "/users", "get")
+	errors401 := testFinding("owasp-define-error-responses-401", "paths", "/users", "get")
+	exemption := func(status string, fingerprint string, expires *time.Time) integrations.Exemption {
+		return integrations.Exemption{ID: "exm-1", Status: status, Fingerprint: fingerprint, ExpiresAt: expires}
+	}
+
+	tests := []struct {
+		name       string
+		exemptions []integrations.Exemption
+		exempted   []Finding
+		reason     string
+	}{
+		{name: "no exemptions"},
+		{name: "approved", exemptions: []integrations.Exemption{exemption(integrations.ExemptionApproved, rateLimit.Fingerprint(), nil)}, exempted: []Finding{rateLimit}, reason: "exempted by exm-1"},
+		{name: "until expiry", exemptions: []integrations.Exemption{exemption(integrations.ExemptionApproved, rateLimit.Fingerprint(), &future)}, exempted: []Finding{rateLimit}, reason: "exempted by exm-1 until 2024-06-02"},
+		{name: "expired", exemptions: []integrations.Exemption{exemption(integrations.ExemptionApproved, rateLimit.Fingerprint(), &past)}},
+		{name: "pending", exemptions: []integrations.Exemption{exemption("pending", rateLimit.Fingerprint(), nil)}},
+		{name: "rejected", exemptions: []integrations.Exemption{exemption("rejected", rateLimit.Fingerprint(), nil)}},
+		{name: "without fingerprint", exemptions: []integrations.Exemption{exemption(integrations.ExemptionApproved, "", nil)}},
+		{name: "other finding", exemptions: []integrations.Exemption{exemption(integrations.ExemptionApproved, "0000000000000000", nil)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := []Finding{rateLimit, errors401}
+			kept, exempted := applyExemptions(findings, tt.exemptions, now)
+			sameFindings(t, "exempted", exempted, tt.exempted)
+			if len(kept)+len(exempted) != len(findings) {
+				t.Errorf("kept %d and exempted %d of %d findings", len(kept), len(exempted), len(findings))
+			}
+			for _, finding := range exempted {
+				if finding.ExclusionReason != tt.reason {
+					t.Errorf("reason %q, want %q", finding.ExclusionReason, tt.reason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// Finding is a governance result enriched with context from the local checkout
+type Finding struct {
+	integrations.LintResult
+	// File is the spec file the finding belongs to
+	File string
+	// Spec is the spec analyzed when File is a file bundled into it
+	Spec string
+	// Document is the 1-based index of the YAML document in multi-document
+	// files, 0 otherwise
+	Document int
+	// Location is the line range in the spec file resolved from the result path
+	Location specLocation
+	// Tags are the OAS tags of the operation the finding is on
+	Tags []string
+	// Blame is the commit that introduced the finding, when it's on a changed line
+	Blame *integrations.BlameInfo
+	// Permalink links to the finding's lines in the repository at the analyzed
+	// commit, when running in CI
+	Permalink string
+	// ExclusionReason explains why the finding doesn't count towards the result
+	ExclusionReason string
+	// FirstSeen is when an earlier run in the history first reported the finding
+	FirstSeen time.Time
+}
+
+// newFindings wraps the service results, resolving each result path to a line
+// range in the spec content
+func newFindings(results []integrations.LintResult, content string) []Finding {
+	root, _ := parseSpecNode(content)
+
+	findings := make([]Finding, 0, len(results))
+	for _, result := range results {
+		finding := Finding{LintResult: result}
+		if root != nil {
+			finding.Location, _ = locatePath(root, result.Path)
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// spec returns the spec file the finding was analyzed as part of
+func (f Finding) spec() string {
+	if f.Spec != "" {
+		return f.Spec
+	}
+	return f.File
+}
+
+// Fingerprint identifies a finding across runs. It leaves out line numbers so
+// edits elsewhere in the spec don't change it.
+func (f Finding) Fingerprint() string {
+	fields := []string{
+		repoPath(f.File),
+		f.Rule.Name,
+		strings.Join(f.Path, "."),
+		f.Message,
+	}
+	// Tell apart the same finding in several documents of a multi-document file
+	if f.Document > 0 {
+		fields = append(fields, strconv.Itoa(f.Document))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// repoPath returns a spec path relative to the repository root with forward
+// slashes, as used by provider APIs. Git specs are returned as they are.
+func repoPath(path string) string {
+	if _, _, ok := parseGitSpec(path); ok {
+		return path
+	}
+	if filepath.IsAbs(path) {
+		for _, root := range []string{os.Getenv("GITHUB_WORKSPACE"), os.Getenv("CI_PROJECT_DIR")} {
+			if root == "" {
+				continue
+			}
+			if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+				break
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
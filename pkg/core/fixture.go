@@ -0,0 +1,461 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FixtureOptions are the options of the fixture generator
+type FixtureOptions struct {
+	Violations []string // Violations the fixture contains, none for a compliant spec
+	Format     string   // yaml or json
+	Output     string   // File the fixture is written to, stdout when empty
+}
+
+// fixtureViolation breaks a compliant spec so a rule fires on it
+type fixtureViolation struct {
+	Description string
+	apply       func(root *yaml.Node)
+}
+
+// fixtureViolations are the violations the generator knows, keyed by the
+// name passed to --violations
+var fixtureViolations = map[string]fixtureViolation{
+	"owasp-rate-limit": {
+		Description: "no 429 responses or RateLimit headers (OWASP API4)",
+		apply: func(root *yaml.Node) {
+			eachFixtureResponse(root, func(response *yaml.Node) {
+				deleteKey(response, "headers")
+			})
+			eachFixtureOperation(root, func(operation *yaml.Node) {
+				deleteKey(mappingValue(operation, "responses"), "429")
+			})
+			deleteKey(nodeAt(root, "components", "responses"), "TooManyRequests")
+		},
+	},
+	"missing-401": {
+		Description: "secured operations without a 401 response (OWASP API2)",
+		apply: func(root *yaml.Node) {
+			eachFixtureOperation(root, func(operation *yaml.Node) {
+				deleteKey(mappingValue(operation, "responses"), "401")
+			})
+			deleteKey(nodeAt(root, "components", "responses"), "Unauthorized")
+		},
+	},
+	"missing-security": {
+		Description: "operations without security requirements (OWASP API2)",
+		apply: func(root *yaml.Node) {
+			deleteKey(root, "security")
+		},
+	},
+	"http-server": {
+		Description: "server URL over plain HTTP (OWASP API8)",
+		apply: func(root *yaml.Node) {
+			servers := mappingValue(root, "servers")
+			for _, server := range servers.Content {
+				if url := mappingValue(server, "url"); url != nil {
+					url.Value = strings.Replace(url.Value, "https://", "http://", 1)
+				}
+			}
+		},
+	},
+	"unbounded-string": {
+		Description: "string properties without maxLength (OWASP API4)",
+		apply: func(root *yaml.Node) {
+			walkNodes(root, func(node *yaml.Node) {
+				if typ := mappingValue(node, "type"); typ != nil && typ.Value == "string" {
+					deleteKey(node, "maxLength")
+				}
+			})
+		},
+	},
+	"unbounded-array": {
+		Description: "array schemas without maxItems (OWASP API4)",
+		apply: func(root *yaml.Node) {
+			walkNodes(root, func(node *yaml.Node) {
+				if typ := mappingValue(node, "type"); typ != nil && typ.Value == "array" {
+					deleteKey(node, "maxItems")
+				}
+			})
+		},
+	},
+	"additional-properties": {
+		Description: "object schemas allowing additional properties (OWASP API3)",
+		apply: func(root *yaml.Node) {
+			walkNodes(root, func(node *yaml.Node) {
+				if value := mappingValue(node, "additionalProperties"); value != nil {
+					value.Value = "true"
+				}
+			})
+		},
+	},
+	"missing-description": {
+		Description: "operations without a description",
+		apply: func(root *yaml.Node) {
+			eachFixtureOperation(root, func(operation *yaml.Node) {
+				deleteKey(operation, "description")
+			})
+		},
+	},
+	"missing-operation-id": {
+		Description: "operations without an operationId",
+		apply: func(root *yaml.Node) {
+			eachFixtureOperation(root, func(operation *yaml.Node) {
+				deleteKey(operation, "operationId")
+			})
+		},
+	},
+	"missing-contact": {
+		Description: "info without a contact",
+		apply: func(root *yaml.Node) {
+			deleteKey(mappingValue(root, "info"), "contact")
+		},
+	},
+	"trailing-slash": {
+		Description: "paths ending with a slash",
+		apply: func(root *yaml.Node) {
+			paths := mappingValue(root, "paths")
+			for i := 0; i+1 < len(paths.Content); i += 2 {
+				paths.Content[i].Value += "/"
+			}
+		},
+	},
+}
+
+// fixtureSpec is the compliant spec the violations are applied to. It passes
+// the OWASP API Security Top 10 and the common documentation rules.
+const fixtureSpec = `openapi: 3.0.3
+info:
+  title: Fixture API
+  version: 1.0.0
+  description: Spec generated by governance-action generate-fixture for pipeline tests.
+  contact:
+    name: API Platform Team
+    email: api-platform@example.com
+    url: https://example.com/api-platform
+  license:
+    name: Apache 2.0
+    url: https://www.apache.org/licenses/LICENSE-2.0.html
+servers:
+  - url: https://api.example.com/v1
+    description: Production
+security:
+  - bearerAuth: []
+tags:
+  - name: users
+    description: User management
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      summary: List users
+      description: Returns a page of users.
+      tags:
+        - users
+      parameters:
+        - name: limit
+          in: query
+          description: Maximum number of users returned.
+          schema:
+            type: integer
+            format: int32
+            minimum: 1
+            maximum: 100
+      responses:
+        '200':
+          description: A page of users.
+          headers:
+            RateLimit-Limit:
+              description: Requests allowed in the current window.
+              schema:
+                type: integer
+            RateLimit-Remaining:
+              description: Requests left in the current window.
+              schema:
+                type: integer
+            RateLimit-Reset:
+              description: Seconds until the window resets.
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: array
+                maxItems: 100
+                items:
+                  $ref: '#/components/schemas/User'
+        '400':
+          $ref: '#/components/responses/BadRequest'
+        '401':
+          $ref: '#/components/responses/Unauthorized'
+        '429':
+          $ref: '#/components/responses/TooManyRequests'
+        '500':
+          $ref: '#/components/responses/InternalError'
+  /users/{id}:
+    get:
+      operationId: getUser
+      summary: Get a user
+      description: Returns a single user.
+      tags:
+        - users
+      parameters:
+        - name: id
+          in: path
+          required: true
+          description: ID of the user.
+          schema:
+            type: string
+            format: uuid
+            maxLength: 36
+      responses:
+        '200':
+          description: The user.
+          headers:
+            RateLimit-Limit:
+              description: Requests allowed in the current window.
+              schema:
+                type: integer
+            RateLimit-Remaining:
+              description: Requests left in the current window.
+              schema:
+                type: integer
+            RateLimit-Reset:
+              description: Seconds until the window resets.
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+        '400':
+          $ref: '#/components/responses/BadRequest'
+        '401':
+          $ref: '#/components/responses/Unauthorized'
+        '404':
+          $ref: '#/components/responses/NotFound'
+        '429':
+          $ref: '#/components/responses/TooManyRequests'
+        '500':
+          $ref: '#/components/responses/InternalError'
+components:
+  securitySchemes:
+    bearerAuth:
+      type: http
+      scheme: bearer
+      bearerFormat: JWT
+  responses:
+    BadRequest:
+      description: The request is invalid.
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Error'
+    Unauthorized:
+      description: The request isn't authenticated.
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Error'
+    NotFound:
+      description: The resource doesn't exist.
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Error'
+    TooManyRequests:
+      description: The rate limit is exceeded.
+      headers:
+        Retry-After:
+          description: Seconds to wait before retrying.
+          schema:
+            type: integer
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Error'
+    InternalError:
+      description: The server failed to handle the request.
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Error'
+  schemas:
+    User:
+      type: object
+      additionalProperties: false
+      required:
+        - id
+        - name
+      properties:
+        id:
+          type: string
+          format: uuid
+          maxLength: 36
+        name:
+          type: string
+          maxLength: 100
+        email:
+          type: string
+          format: email
+          maxLength: 254
+    Error:
+      type: object
+      additionalProperties: false
+      required:
+        - message
+      properties:
+        message:
+          type: string
+          maxLength: 500
+`
+
+// FixtureViolationNames returns the names of the known violations, sorted
+func FixtureViolationNames() []string {
+	names := make([]string, 0, len(fixtureViolations))
+	for name := range fixtureViolations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PrintFixtureViolations lists the known violations with what they break
+func PrintFixtureViolations(w io.Writer) {
+	names := FixtureViolationNames()
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+	for _, name := range names {
+		fmt.Fprintf(w, "%-*s  %s\n", width, name, fixtureViolations[name].Description)
+	}
+}
+
+// RunGenerateFixture writes a spec that deliberately violates the selected
+// rules, for end-to-end tests asserting that a pipeline blocks bad specs.
+// Without violations the spec is compliant, for the passing counterpart.
+func RunGenerateFixture(options FixtureOptions) error {
+	content, err := generateFixture(options.Violations, options.Format)
+	if err != nil {
+		return err
+	}
+	if options.Output == "" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	if err := os.WriteFile(options.Output, content, 0644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// generateFixture applies the violations to the compliant spec and encodes it
+func generateFixture(violations []string, format string) ([]byte, error) {
+	root, err := parseSpecNode(fixtureSpec)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range violations {
+		violation, ok := fixtureViolations[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown violation %q, known violations are: %s", name, strings.Join(FixtureViolationNames(), ", "))
+		}
+		violation.apply(root)
+	}
+
+	switch format {
+	case "", "yaml":
+		if len(violations) > 0 {
+			root.HeadComment = "Violates: " + strings.Join(violations, ", ")
+		}
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(root); err != nil {
+			return nil, fmt.Errorf("failed to encode fixture: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode fixture: %w", err)
+		}
+		return buf.Bytes(), nil
+	case "json":
+		var doc interface{}
+		if err := root.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to encode fixture: %w", err)
+		}
+		content, err := json.MarshalIndent(stringKeys(doc), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode fixture: %w", err)
+		}
+		return append(content, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unknown fixture format %q, use yaml or json", format)
+	}
+}
+
+// eachFixtureOperation calls fn with every operation of the spec
+func eachFixtureOperation(root *yaml.Node, fn func(operation *yaml.Node)) {
+	paths := mappingValue(root, "paths")
+	if paths == nil {
+		return
+	}
+	for i := 1; i < len(paths.Content); i += 2 {
+		for _, method := range httpMethods {
+			if operation := mappingValue(paths.Content[i], method); operation != nil {
+				fn(operation)
+			}
+		}
+	}
+}
+
+// eachFixtureResponse calls fn with every inline response of the operations
+func eachFixtureResponse(root *yaml.Node, fn func(response *yaml.Node)) {
+	eachFixtureOperation(root, func(operation *yaml.Node) {
+		responses := mappingValue(operation, "responses")
+		if responses == nil {
+			return
+		}
+		for i := 1; i < len(responses.Content); i += 2 {
+			fn(responses.Content[i])
+		}
+	})
+}
+
+// nodeAt returns the node at a path of mapping keys, or nil
+func nodeAt(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		node = mappingValue(node, key)
+	}
+	return node
+}
+
+// deleteKey removes a key from a mapping node
+func deleteKey(node *yaml.Node, key string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// walkNodes calls fn with every mapping node of the tree
+func walkNodes(node *yaml.Node, fn func(node *yaml.Node)) {
+	if node.Kind == yaml.MappingNode {
+		fn(node)
+	}
+	for _, child := range node.Content {
+		walkNodes(child, fn)
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// frameworkCount is the number of violations recorded against a compliance framework control
+type frameworkCount struct {
+	Framework  string
+	Violations int
+}
+
+// frameworkRollup counts violations per compliance framework control using the
+// framework tags the governance service attaches to each rule
+func frameworkRollup(findings []Finding, ruleset *integrations.Ruleset) []frameworkCount {
+	counts := map[string]int{}
+	for _, result := range findings {
+		rule := ruleset.Rule(result.Rule.Name)
+		if rule == nil {
+			continue
+		}
+		for _, framework := range rule.Frameworks {
+			counts[framework]++
+		}
+	}
+
+	rollup := make([]frameworkCount, 0, len(counts))
+	for framework, violations := range counts {
+		rollup = append(rollup, frameworkCount{Framework: framework, Violations: violations})
+	}
+	sort.Slice(rollup, func(i, j int) bool {
+		if rollup[i].Violations != rollup[j].Violations {
+			return rollup[i].Violations > rollup[j].Violations
+		}
+		return rollup[i].Framework < rollup[j].Framework
+	})
+	return rollup
+}
+
+// printFrameworkRollup prints the framework-level section of the console report
+func printFrameworkRollup(w io.Writer, config *Configuration, rollup []frameworkCount) {
+	if len(rollup) == 0 {
+		return
+	}
+	printHeading(w, config.text("report.frameworks"))
+	for _, fc := range rollup {
+		noun := config.text("report.violations")
+		if fc.Violations == 1 {
+			noun = config.text("report.violation")
+		}
+		fmt.Fprintf(w, "    %s: %d %s\n", fc.Framework, fc.Violations, noun)
+	}
+}
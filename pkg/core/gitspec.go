@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// gitSpecScheme prefixes specs read from a git ref of the checked out
+// repository rather than the working tree, as git://<ref>:<path>
+const gitSpecScheme = "git://"
+
+// parseGitSpec splits a git spec into its ref and its path relative to the
+// repository root
+func parseGitSpec(spec string) (ref, file string, ok bool) {
+	rest, found := strings.CutPrefix(spec, gitSpecScheme)
+	if !found {
+		return "", "", false
+	}
+	// Refs can't contain colons, so the first one ends the ref
+	ref, file, found = strings.Cut(rest, ":")
+	if !found || ref == "" || file == "" {
+		return "", "", false
+	}
+	return ref, file, true
+}
+
+// gitSpecPath returns the git spec of a file at a ref
+func gitSpecPath(ref, file string) string {
+	return gitSpecScheme + ref + ":" + file
+}
+
+// normalizeGitSpec cleans the path of a git spec, which is always relative to
+// the repository root, or returns an error for a malformed one
+func normalizeGitSpec(spec string) (string, error) {
+	ref, file, ok := parseGitSpec(spec)
+	if !ok {
+		return "", fmt.Errorf("api_path: %s isn't a git spec, expected git://<ref>:<path>", spec)
+	}
+	file = path.Clean(strings.TrimLeft(strings.ReplaceAll(file, `\`, "/"), "/"))
+	return gitSpecPath(ref, strings.TrimPrefix(file, "./")), nil
+}
+
+// globGitSpecs returns the git specs at the ref of a glob such as
+// git://main:apis/**/*.yaml, skipping what .governanceignore lists
+func globGitSpecs(spec string) ([]string, error) {
+	ref, pattern, ok := parseGitSpec(spec)
+	if !ok {
+		return nil, fmt.Errorf("api_path: %s isn't a git spec, expected git://<ref>:<path>", spec)
+	}
+	ignore, err := loadGovernanceIgnore()
+	if err != nil {
+		return nil, err
+	}
+	files, err := integrations.GitFiles(context.Background(), ref)
+	if err != nil {
+		return nil, fmt.Errorf("api_path: failed to list the files of %s: %w", ref, err)
+	}
+	pattern = path.Clean(strings.TrimLeft(strings.ReplaceAll(pattern, `\`, "/"), "/"))
+	var specs []string
+	for _, file := range files {
+		if slices.Contains(specExtensions, strings.ToLower(path.Ext(file))) && globMatch(pattern, file) && !ignore.ignored(file, false) {
+			specs = append(specs, gitSpecPath(ref, file))
+		}
+	}
+	sort.Strings(specs)
+	return specs, nil
+}
+
+// readSpecFile reads a spec or a file it references from the working tree, or
+// from its ref for git specs
+func readSpecFile(file string) ([]byte, error) {
+	if ref, name, ok := parseGitSpec(file); ok {
+		content, err := integrations.GitShow(context.Background(), ref, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at %s: %w", name, ref, err)
+		}
+		return content, nil
+	}
+	return os.ReadFile(file)
+}
+
+// readGitSpec reads a git spec, refusing specs larger than maxSize bytes
+// unless it's 0
+func readGitSpec(spec string, maxSize int64) (string, error) {
+	ref, file, _ := parseGitSpec(spec)
+	if maxSize > 0 {
+		size, err := integrations.GitFileSize(context.Background(), ref, file)
+		if err != nil {
+			return "", fmt.Errorf("spec %s not found at %s: %w", file, ref, err)
+		}
+		if size > maxSize {
+			return "", fmt.Errorf("file %s is %s, larger than the max_spec_size of %s; split the spec into smaller files or raise max_spec_size",
+				spec, formatByteSize(size), formatByteSize(maxSize))
+		}
+	}
+	content, err := readSpecFile(spec)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
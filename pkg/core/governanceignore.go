@@ -0,0 +1,108 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// governanceIgnoreFile lists the files discovery and api_path globs skip, in
+// the working directory like CODEOWNERS
+const governanceIgnoreFile = ".governanceignore"
+
+// ignoreRule is a pattern of the .governanceignore file
+type ignoreRule struct {
+	Pattern string
+	Negate  bool // Re-includes what earlier patterns excluded
+	DirOnly bool // Only matches directories
+}
+
+// governanceIgnore are the patterns of the .governanceignore file, in file order
+type governanceIgnore []ignoreRule
+
+// loadGovernanceIgnore reads the .governanceignore file. Without one nothing
+// is ignored.
+func loadGovernanceIgnore() (governanceIgnore, error) {
+	file, err := os.Open(governanceIgnoreFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", governanceIgnoreFile, err)
+	}
+	defer file.Close()
+	rules, err := parseGovernanceIgnore(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", governanceIgnoreFile, err)
+	}
+	return rules, nil
+}
+
+// parseGovernanceIgnore parses the lines of a .governanceignore file, which
+// follow .gitignore: # starts a comment, ! negates a pattern and a trailing
+// slash only matches directories
+func parseGovernanceIgnore(r io.Reader) (governanceIgnore, error) {
+	var rules governanceIgnore
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.Negate, line = true, line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.DirOnly, line = true, strings.TrimSuffix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.Pattern = line
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// ignored reports whether a repository path is ignored. As in .gitignore the
+// last matching pattern wins, and the files of an ignored directory can't be
+// re-included.
+func (g governanceIgnore) ignored(path string, dir bool) bool {
+	if len(g) == 0 {
+		return false
+	}
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if g.matches(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return g.matches(path, dir)
+}
+
+// matches reports whether the last pattern matching the path excludes it
+func (g governanceIgnore) matches(path string, dir bool) bool {
+	ignored := false
+	for _, rule := range g {
+		if rule.DirOnly && !dir {
+			continue
+		}
+		if ignoreMatch(rule.Pattern, path) {
+			ignored = !rule.Negate
+		}
+	}
+	return ignored
+}
+
+// ignoreMatch reports whether a .governanceignore pattern matches a repository
+// path. Patterns with a slash are relative to the repository root, patterns
+// without one match a name at any depth.
+func ignoreMatch(pattern, path string) bool {
+	if strings.Contains(pattern, "/") {
+		return globMatch(strings.TrimPrefix(pattern, "/"), path)
+	}
+	return globMatch("**/"+pattern, path)
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// isHistoryURL reports whether the history is kept in an object store rather
+// than in a local file
+func isHistoryURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// loadHistory reads the runs on a branch since a point in time from the
+// governance service, an object store or a local file
+func loadHistory(ctx context.Context, config *Configuration, client *integrations.GovernanceClient, repository, branch string, since time.Time) ([]integrations.HistoryEntry, error) {
+	switch {
+	case config.History == historyService:
+		if client == nil {
+			return nil, fmt.Errorf("the governance service history isn't available in mocked mode")
+		}
+		return client.GetHistory(ctx, repository, branch, since)
+	case isHistoryURL(config.History):
+		return integrations.FetchHistory(ctx, config.History, config.HistoryToken)
+	default:
+		return readLocalHistory(config.History, since)
+	}
+}
+
+// recordHistory adds the run to an object store or local history, dropping
+// runs older than the retention. The governance service records runs itself.
+func recordHistory(ctx context.Context, config *Configuration, history []integrations.HistoryEntry, current integrations.HistoryEntry, logger *zap.Logger) {
+	var err error
+	var runs int
+	switch {
+	case config.History == historyService:
+		return
+	case isHistoryURL(config.History):
+		history = pruneHistory(append(history, current), current.Time)
+		runs = len(history)
+		err = integrations.StoreHistory(ctx, config.History, config.HistoryToken, history)
+	default:
+		runs, err = appendLocalHistory(config.History, current)
+	}
+	if err != nil {
+		logger.Warn("Failed to record the run in the history", zap.Error(err))
+		return
+	}
+	logger.Info("Recorded the run in the history", zap.Int("runs", runs))
+}
+
+// pruneHistory drops the runs older than the retention and sorts the others
+// by time
+func pruneHistory(history []integrations.HistoryEntry, now time.Time) []integrations.HistoryEntry {
+	cutoff := now.Add(-historyRetention)
+	kept := make([]integrations.HistoryEntry, 0, len(history))
+	for _, entry := range history {
+		if !entry.Time.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Time.Before(kept[j].Time) })
+	return kept
+}
+
+// historyBucket holds the runs of a local history by time
+var historyBucket = []byte("runs")
+
+// historyKey orders the runs of a local history by time, with a sequence
+// number for runs recorded at the same time
+func historyKey(t time.Time, sequence uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], sequence)
+	return key
+}
+
+// openLocalHistory opens a local history store, waiting for concurrent runs
+// that have it open for writing
+func openLocalHistory(path string, readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: fileLockTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// readLocalHistory reads the runs since a point in time from a local history
+// store. Only those runs are read, however long the history. A missing file
+// has no runs yet.
+func readLocalHistory(path string, since time.Time) ([]integrations.HistoryEntry, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	db, err := openLocalHistory(path, true)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var entries []integrations.HistoryEntry
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket)
+		if bucket == nil {
+			return nil
+		}
+		cursor := bucket.Cursor()
+		for key, value := cursor.Seek(historyKey(since, 0)); key != nil; key, value = cursor.Next() {
+			var entry integrations.HistoryEntry
+			if err := json.Unmarshal(value, &entry); err != nil {
+				return fmt.Errorf("failed to parse history %s: %w", path, err)
+			}
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// appendLocalHistory adds a run to a local history store, e.g. one restored
+// from the CI cache, drops the runs older than the retention and returns the
+// number of runs kept. The store is locked while the run is added, so
+// concurrent runs don't lose each other's entries.
+func appendLocalHistory(path string, current integrations.HistoryEntry) (int, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return 0, fmt.Errorf("failed to create history directory: %w", err)
+		}
+	}
+	db, err := openLocalHistory(path, false)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	value, err := json.Marshal(current)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal history: %w", err)
+	}
+	runs := 0
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(historyBucket)
+		if err != nil {
+			return err
+		}
+		sequence, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(historyKey(current.Time, sequence), value); err != nil {
+			return err
+		}
+		cutoff := historyKey(current.Time.Add(-historyRetention), 0)
+		cursor := bucket.Cursor()
+		for key, _ := cursor.First(); key != nil && bytes.Compare(key, cutoff) < 0; key, _ = cursor.Next() {
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+		for key, _ := cursor.Seek(cutoff); key != nil; key, _ = cursor.Next() {
+			runs++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to record the run in %s: %w", path, err)
+	}
+	return runs, nil
+}
+
+// markPersistingFindings sets when each finding was first seen in an earlier
+// run on the branch and returns how many have been open since before the
+// window start
+func markPersistingFindings(findings []Finding, history []integrations.HistoryEntry, branch string, windowStart time.Time) int {
+	firstSeen := map[string]time.Time{}
+	for _, entry := range history {
+		if entry.Branch != branch {
+			continue
+		}
+		for _, fingerprint := range entry.Fingerprints {
+			if seen, ok := firstSeen[fingerprint]; !ok || entry.Time.Before(seen) {
+				firstSeen[fingerprint] = entry.Time
+			}
+		}
+	}
+
+	persisting := 0
+	for i := range findings {
+		seen, ok := firstSeen[findings[i].Fingerprint()]
+		if !ok {
+			continue
+		}
+		findings[i].FirstSeen = seen
+		if seen.Before(windowStart) {
+			persisting++
+		}
+	}
+	return persisting
+}
+
+// fingerprints returns the fingerprints of findings, for the history
+func fingerprints(findings []Finding) []string {
+	prints := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		prints = append(prints, finding.Fingerprint())
+	}
+	sort.Strings(prints)
+	return prints
+}
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// Exit codes of hook runs. Argo CD and Flux fail the sync on any non-zero
+// exit; the codes tell a Job's pod failure policy whether a retry can help.
+const (
+	HookExitPass  = 0 // The verdict is pass or warn
+	HookExitFail  = 1 // The verdict is fail, retrying won't change it
+	HookExitError = 2 // No verdict was reached, e.g. the service was down
+)
+
+// defaultTerminationLog is where Kubernetes reads a container's termination
+// message from, shown in the pod status and by Argo CD for failed hooks
+const defaultTerminationLog = "/dev/termination-log"
+
+// maxTerminationMessage is the size Kubernetes truncates termination messages to
+const maxTerminationMessage = 4096
+
+// HookOptions configure a run as a GitOps pre-sync hook
+type HookOptions struct {
+	Spec           string // Spec file or directory, e.g. a mounted ConfigMap
+	ConfigMap      string // ConfigMap holding the specs, as [namespace/]name
+	TerminationLog string // Termination message path of the container
+}
+
+// hookEvent is the outcome of a hook run in the shape of a Kubernetes event,
+// printed as a single JSON line for log collectors
+type hookEvent struct {
+	Type     string   `json:"type"`
+	Reason   string   `json:"reason"`
+	Message  string   `json:"message"`
+	Verdict  string   `json:"verdict"`
+	Errors   int      `json:"errors"`
+	Warnings int      `json:"warnings"`
+	Specs    []string `json:"specs"`
+}
+
+// RunHook runs the analysis as an Argo CD or Flux pre-sync hook, in a Job
+// rather than a CI pipeline. The specs are read from a file or directory, such
+// as a mounted ConfigMap, or from a ConfigMap through the Kubernetes API, and
+// api_path otherwise. The outcome is printed as an event line and written as
+// the container's termination message; HookExitCode maps it to the exit code.
+func RunHook(logger *zap.Logger, options HookOptions) (*RunResult, error) {
+	runOptions := RunOptions{}
+	specs, cleanup, err := hookSpecs(context.Background(), options, logger)
+	if err != nil {
+		result := &RunResult{Verdict: VerdictError, StartedAt: time.Now().UTC()}
+		result.finish()
+		publishHookEvent(options, result, err, logger)
+		return result, fmt.Errorf("configuration error: %w", err)
+	}
+	defer cleanup()
+	if len(specs) > 0 {
+		runOptions.APIPath = strings.Join(specs, ",")
+		runOptions.APIPathSource = "the hook specs"
+	}
+
+	result, err := RunAction(logger, runOptions)
+	publishHookEvent(options, result, err, logger)
+	return result, err
+}
+
+// HookExitCode returns the exit code of a hook run
+func HookExitCode(result *RunResult, err error) int {
+	switch {
+	case result.Verdict == VerdictFail:
+		return HookExitFail
+	case err != nil || result.Verdict == VerdictError:
+		return HookExitError
+	}
+	return HookExitPass
+}
+
+// hookSpecs returns the spec files of a hook run, none to analyze api_path,
+// and a function removing the files read from a ConfigMap
+func hookSpecs(ctx context.Context, options HookOptions, logger *zap.Logger) ([]string, func(), error) {
+	cleanup := func() {}
+	switch {
+	case options.ConfigMap != "":
+		return configMapSpecs(ctx, options.ConfigMap, logger)
+	case options.Spec == "":
+		return nil, cleanup, nil
+	}
+
+	info, err := os.Stat(options.Spec)
+	if err != nil || !info.IsDir() {
+		return []string{options.Spec}, cleanup, nil
+	}
+	// Mounted ConfigMaps link each key to a file in a hidden directory, which
+	// discovery skips while following the links
+	specs, err := discoverSpecs(options.Spec, 0)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	if len(specs) == 0 {
+		return nil, cleanup, fmt.Errorf("no specs found in %s", options.Spec)
+	}
+	return specs, cleanup, nil
+}
+
+// configMapSpecs writes the keys of a ConfigMap that hold specs to a temporary
+// directory, adding a .yaml extension to keys without a spec extension, and
+// returns their paths. The ConfigMap is read from the pod's namespace unless
+// named as namespace/name.
+func configMapSpecs(ctx context.Context, ref string, logger *zap.Logger) ([]string, func(), error) {
+	cleanup := func() {}
+	client, err := integrations.NewInClusterKubernetesClient(logger)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found {
+		namespace, name = client.Namespace, ref
+	}
+	data, err := client.ConfigMapData(ctx, namespace, name)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	dir, err := os.MkdirTemp("", "governance-"+name+"-")
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("failed to create directory for ConfigMap %s: %w", ref, err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var specs []string
+	for _, key := range keys {
+		if specFormat(data[key]) == "" {
+			continue
+		}
+		file := filepath.Join(dir, key)
+		if !slices.Contains(specExtensions, strings.ToLower(filepath.Ext(key))) {
+			file += ".yaml"
+		}
+		if err := os.WriteFile(file, []byte(data[key]), 0644); err != nil {
+			cleanup()
+			return nil, func() {}, fmt.Errorf("failed to write key %s of ConfigMap %s: %w", key, ref, err)
+		}
+		specs = append(specs, file)
+	}
+	if len(specs) == 0 {
+		cleanup()
+		return nil, func() {}, fmt.Errorf("ConfigMap %s/%s has no keys holding specs", namespace, name)
+	}
+	logger.Info("Read specs from ConfigMap", zap.String("namespace", namespace), zap.String("name", name), zap.Int("spec_count", len(specs)))
+	return specs, cleanup, nil
+}
+
+// publishHookEvent prints the outcome of a hook run as an event line and
+// writes it as the termination message, where the container has one
+func publishHookEvent(options HookOptions, result *RunResult, runErr error, logger *zap.Logger) {
+	event := hookEvent{
+		Type:     "Normal",
+		Reason:   "GovernancePassed",
+		Message:  result.description(),
+		Verdict:  result.Verdict,
+		Errors:   result.Errors,
+		Warnings: result.Warnings,
+		Specs:    make([]string, 0, len(result.Files)),
+	}
+	for _, file := range result.Files {
+		event.Specs = append(event.Specs, repoPath(file))
+	}
+	switch HookExitCode(result, runErr) {
+	case HookExitFail:
+		event.Type, event.Reason = "Warning", "GovernanceFailed"
+	case HookExitError:
+		event.Type, event.Reason = "Warning", "GovernanceError"
+		if runErr != nil {
+			event.Message = "Governance error: " + runErr.Error()
+		}
+	default:
+		if result.Verdict == VerdictWarn {
+			event.Reason = "GovernanceWarned"
+		}
+	}
+	if data, err := json.Marshal(event); err == nil {
+		fmt.Println(string(data))
+	}
+
+	path := options.TerminationLog
+	if path == "" {
+		path = defaultTerminationLog
+	}
+	// Only write where Kubernetes set up a termination log, never create one
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	message := event.Message
+	if len(message) > maxTerminationMessage {
+		message = message[:maxTerminationMessage]
+	}
+	if err := os.WriteFile(path, []byte(message), 0644); err != nil {
+		logger.Warn("Failed to write the termination message", zap.String("path", path), zap.Error(err))
+	}
+}
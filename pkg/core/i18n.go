@@ -0,0 +1,271 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// defaultLocale is used for missing translations
+const defaultLocale = "en"
+
+// catalogs hold the report texts per locale. Logs stay in English for operators.
+var catalogs = map[string]map[string]string{
+	"en": {
+		"severity.error":            "ERROR",
+		"severity.warning":          "WARNING",
+		"severity.info":             "INFO",
+		"verdict.pass":              "pass",
+		"verdict.warn":              "warn",
+		"verdict.fail":              "fail",
+		"verdict.error":             "error",
+		"report.title":              "Governance Analysis Report",
+		"report.run_times":          "Started %s, finished %s",
+		"report.location":           "Location: line %d, char %d - line %d, char %d",
+		"report.formatted_location": "In formatted JSON: line %d, char %d - line %d, char %d",
+		"report.snippet":            "OAS snippet",
+		"report.fingerprint":        "Fingerprint: %s",
+		"report.permalink":          "Link: %s",
+		"report.remediation":        "Remediation: %s",
+		"report.rule_link":          "Style guide: %s",
+		"report.introduced_by":      "Introduced by: %.7s %s <%s> %q",
+		"report.first_seen":         "Open since: %s",
+		"report.frameworks":         "Compliance Frameworks",
+		"report.tags":               "Findings by Tag",
+		"report.violation":          "violation",
+		"report.violations":         "violations",
+		"report.statistics":         "Spec Statistics",
+		"report.statistics_line":    "%d paths, %d operations, %d schemas, %d security schemes",
+		"report.rules":              "Rules",
+		"report.rules_line":         "%d rules passed, %d failed",
+		"report.coverage":           "Coverage",
+		"report.coverage_line":      "%d of %d operations governed (%d%%)",
+		"report.coverage_exempted":  "%d governed operations have exempted or ignored findings",
+		"report.diagnostics":        "Diagnostics",
+		"report.evaluation_time":    "Evaluation time: %s",
+		"report.slowest_rules":      "Slowest rules:",
+		"report.files":              "Files",
+		"report.file":               "File",
+		"report.errors":             "Errors",
+		"report.warnings":           "Warnings",
+		"report.verdict":            "Verdict",
+		"report.excluded":           "Excluded Findings",
+		"report.approvals":          "Approved Operations",
+		"report.approval_invalid":   "malformed approval: %s",
+		"report.upgrade":            "Ruleset Upgrade Preview (%s → latest)",
+		"report.upgrade_none":       "No change in findings",
+		"report.upgrade_counts":     "%d new, %d resolved",
+		"report.diff":               "Changes Since %s",
+		"report.reporting_errors":   "Reporting Errors",
+		"report.catalog":            "API Catalog Scan",
+		"report.ruleset_test":       "Ruleset Test",
+		"report.rule":               "Rule",
+		"report.not_fired":          "did not fire",
+		"report.rules_fired":        "%d of %d rules fired",
+		"report.score":              "Score",
+		"report.catalog_totals":     "%d specs, %d failing, %d errors, %d warnings",
+	},
+	"de": {
+		"severity.error":            "FEHLER",
+		"severity.warning":          "WARNUNG",
+		"severity.info":             "INFO",
+		"verdict.pass":              "bestanden",
+		"verdict.warn":              "Warnung",
+		"verdict.fail":              "nicht bestanden",
+		"verdict.error":             "Fehler",
+		"report.title":              "Governance-Analysebericht",
+		"report.run_times":          "Gestartet %s, beendet %s",
+		"report.location":           "Position: Zeile %d, Zeichen %d - Zeile %d, Zeichen %d",
+		"report.formatted_location": "Im formatierten JSON: Zeile %d, Zeichen %d - Zeile %d, Zeichen %d",
+		"report.snippet":            "OAS-Ausschnitt",
+		"report.fingerprint":        "Fingerabdruck: %s",
+		"report.permalink":          "Link: %s",
+		"report.remediation":        "Behebung: %s",
+		"report.rule_link":          "Styleguide: %s",
+		"report.introduced_by":      "Eingeführt durch: %.7s %s <%s> %q",
+		"report.first_seen":         "Offen seit: %s",
+		"report.frameworks":         "Compliance-Frameworks",
+		"report.tags":               "Befunde nach Tag",
+		"report.violation":          "Verstoß",
+		"report.violations":         "Verstöße",
+		"report.statistics":         "Spezifikationsstatistik",
+		"report.statistics_line":    "%d Pfade, %d Operationen, %d Schemas, %d Sicherheitsschemas",
+		"report.rules":              "Regeln",
+		"report.rules_line":         "%d Regeln bestanden, %d nicht bestanden",
+		"report.coverage":           "Abdeckung",
+		"report.coverage_line":      "%d von %d Operationen geprüft (%d %%)",
+		"report.coverage_exempted":  "%d geprüfte Operationen haben ausgenommene oder ignorierte Befunde",
+		"report.diagnostics":        "Diagnose",
+		"report.evaluation_time":    "Auswertungszeit: %s",
+		"report.slowest_rules":      "Langsamste Regeln:",
+		"report.files":              "Dateien",
+		"report.file":               "Datei",
+		"report.errors":             "Fehler",
+		"report.warnings":           "Warnungen",
+		"report.verdict":            "Ergebnis",
+		"report.excluded":           "Ausgeschlossene Befunde",
+		"report.approvals":          "Genehmigte Operationen",
+		"report.approval_invalid":   "fehlerhafte Genehmigung: %s",
+		"report.upgrade":            "Vorschau des Regelsatz-Upgrades (%s → neueste)",
+		"report.upgrade_none":       "Keine Änderung der Befunde",
+		"report.upgrade_counts":     "%d neu, %d behoben",
+		"report.diff":               "Änderungen seit %s",
+		"report.reporting_errors":   "Berichtsfehler",
+		"report.catalog":            "API-Katalog-Scan",
+		"report.ruleset_test":       "Regelwerk-Test",
+		"report.rule":               "Regel",
+		"report.not_fired":          "nicht ausgelöst",
+		"report.rules_fired":        "%d von %d Regeln ausgelöst",
+		"report.score":              "Score",
+		"report.catalog_totals":     "%d Spezifikationen, %d nicht bestanden, %d Fehler, %d Warnungen",
+	},
+	"fr": {
+		"severity.error":            "ERREUR",
+		"severity.warning":          "AVERTISSEMENT",
+		"severity.info":             "INFO",
+		"verdict.pass":              "réussi",
+		"verdict.warn":              "avertissement",
+		"verdict.fail":              "échec",
+		"verdict.error":             "erreur",
+		"report.title":              "Rapport d'analyse de gouvernance",
+		"report.run_times":          "Démarré le %s, terminé le %s",
+		"report.location":           "Emplacement : ligne %d, car. %d - ligne %d, car. %d",
+		"report.formatted_location": "Dans le JSON formaté : ligne %d, car. %d - ligne %d, car. %d",
+		"report.snippet":            "Extrait OAS",
+		"report.fingerprint":        "Empreinte : %s",
+		"report.permalink":          "Lien : %s",
+		"report.remediation":        "Correction : %s",
+		"report.rule_link":          "Guide de style : %s",
+		"report.introduced_by":      "Introduit par : %.7s %s <%s> %q",
+		"report.first_seen":         "Ouvert depuis : %s",
+		"report.frameworks":         "Référentiels de conformité",
+		"report.tags":               "Constats par tag",
+		"report.violation":          "violation",
+		"report.violations":         "violations",
+		"report.statistics":         "Statistiques de la spécification",
+		"report.statistics_line":    "%d chemins, %d opérations, %d schémas, %d schémas de sécurité",
+		"report.rules":              "Règles",
+		"report.rules_line":         "%d règles respectées, %d non respectées",
+		"report.coverage":           "Couverture",
+		"report.coverage_line":      "%d opérations sur %d contrôlées (%d %%)",
+		"report.coverage_exempted":  "%d opérations contrôlées ont des constats exemptés ou ignorés",
+		"report.diagnostics":        "Diagnostic",
+		"report.evaluation_time":    "Durée d'évaluation : %s",
+		"report.slowest_rules":      "Règles les plus lentes :",
+		"report.files":              "Fichiers",
+		"report.file":               "Fichier",
+		"report.errors":             "Erreurs",
+		"report.warnings":           "Avertissements",
+		"report.verdict":            "Verdict",
+		"report.excluded":           "Constats exclus",
+		"report.approvals":          "Opérations approuvées",
+		"report.approval_invalid":   "approbation mal formée : %s",
+		"report.upgrade":            "Aperçu de la mise à jour des règles (%s → dernière)",
+		"report.upgrade_none":       "Aucun changement des constats",
+		"report.upgrade_counts":     "%d nouveaux, %d résolus",
+		"report.diff":               "Changements depuis %s",
+		"report.reporting_errors":   "Erreurs de publication",
+		"report.catalog":            "Analyse du catalogue d'API",
+		"report.ruleset_test":       "Test du jeu de règles",
+		"report.rule":               "Règle",
+		"report.not_fired":          "non déclenchée",
+		"report.rules_fired":        "%d règles sur %d déclenchées",
+		"report.score":              "Score",
+		"report.catalog_totals":     "%d spécifications, %d en échec, %d erreurs, %d avertissements",
+	},
+	"es": {
+		"severity.error":            "ERROR",
+		"severity.warning":          "ADVERTENCIA",
+		"severity.info":             "INFO",
+		"verdict.pass":              "aprobado",
+		"verdict.warn":              "advertencia",
+		"verdict.fail":              "fallido",
+		"verdict.error":             "error",
+		"report.title":              "Informe de análisis de gobernanza",
+		"report.run_times":          "Iniciado %s, finalizado %s",
+		"report.location":           "Ubicación: línea %d, car. %d - línea %d, car. %d",
+		"report.formatted_location": "En el JSON formateado: línea %d, car. %d - línea %d, car. %d",
+		"report.snippet":            "Fragmento OAS",
+		"report.fingerprint":        "Huella: %s",
+		"report.permalink":          "Enlace: %s",
+		"report.remediation":        "Solución: %s",
+		"report.rule_link":          "Guía de estilo: %s",
+		"report.introduced_by":      "Introducido por: %.7s %s <%s> %q",
+		"report.first_seen":         "Abierto desde: %s",
+		"report.frameworks":         "Marcos de cumplimiento",
+		"report.tags":               "Hallazgos por etiqueta",
+		"report.violation":          "infracción",
+		"report.violations":         "infracciones",
+		"report.statistics":         "Estadísticas de la especificación",
+		"report.statistics_line":    "%d rutas, %d operaciones, %d esquemas, %d esquemas de seguridad",
+		"report.rules":              "Reglas",
+		"report.rules_line":         "%d reglas superadas, %d fallidas",
+		"report.coverage":           "Cobertura",
+		"report.coverage_line":      "%d de %d operaciones controladas (%d %%)",
+		"report.coverage_exempted":  "%d operaciones controladas tienen hallazgos exentos o ignorados",
+		"report.diagnostics":        "Diagnóstico",
+		"report.evaluation_time":    "Tiempo de evaluación: %s",
+		"report.slowest_rules":      "Reglas más lentas:",
+		"report.files":              "Archivos",
+		"report.file":               "Archivo",
+		"report.errors":             "Errores",
+		"report.warnings":           "Advertencias",
+		"report.verdict":            "Veredicto",
+		"report.excluded":           "Hallazgos excluidos",
+		"report.approvals":          "Operaciones aprobadas",
+		"report.approval_invalid":   "aprobación mal formada: %s",
+		"report.upgrade":            "Vista previa de la actualización de reglas (%s → última)",
+		"report.upgrade_none":       "Sin cambios en los hallazgos",
+		"report.upgrade_counts":     "%d nuevos, %d resueltos",
+		"report.diff":               "Cambios desde %s",
+		"report.reporting_errors":   "Errores de publicación",
+		"report.catalog":            "Análisis del catálogo de API",
+		"report.ruleset_test":       "Prueba del conjunto de reglas",
+		"report.rule":               "Regla",
+		"report.not_fired":          "no activada",
+		"report.rules_fired":        "%d de %d reglas activadas",
+		"report.score":              "Puntuación",
+		"report.catalog_totals":     "%d especificaciones, %d fallidas, %d errores, %d advertencias",
+	},
+}
+
+// normalizeLocale reduces a locale such as de-DE or de_DE.UTF-8 to its language
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_."); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
+
+// validateLocale checks that the report texts are available in the locale
+func validateLocale(locale string) error {
+	if _, ok := catalogs[normalizeLocale(locale)]; ok {
+		return nil
+	}
+	locales := make([]string, 0, len(catalogs))
+	for name := range catalogs {
+		locales = append(locales, name)
+	}
+	sort.Strings(locales)
+	return fmt.Errorf("locale must be one of: %s", strings.Join(locales, ", "))
+}
+
+// text returns the report text for key in the configured locale, formatted
+// with args, falling back to English
+func (c *Configuration) text(key string, args ...interface{}) string {
+	format, ok := catalogs[normalizeLocale(c.Locale)][key]
+	if !ok {
+		format = catalogs[defaultLocale][key]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// printHeading prints a section heading of the console report
+func printHeading(w io.Writer, title string) {
+	fmt.Fprintf(w, "---------------- %s ----------------\n", title)
+}
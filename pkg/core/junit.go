@@ -0,0 +1,130 @@
+package core
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultJUnitFile is where the JUnit report is written by default
+const defaultJUnitFile = "governance-junit.xml"
+
+// junitTestSuites is a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitMessage `xml:"failure"`
+	Skipped   *junitMessage `xml:"skipped"`
+	SystemOut *junitOutput  `xml:"system-out"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",cdata"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+// junitReport converts the findings into a JUnit report with a test suite per
+// analyzed spec and a test case per finding: errors fail, warnings are skipped
+// and info findings pass. Rules of the ruleset without findings in a spec are
+// passing test cases, so the report also shows what was checked.
+func junitReport(config *Configuration, report *analysisReport) junitTestSuites {
+	var specs []string
+	bySpec := map[string][]Finding{}
+	for _, spec := range report.Files {
+		specs = append(specs, spec)
+		bySpec[spec] = nil
+	}
+	for _, finding := range report.Findings {
+		spec := finding.spec()
+		if _, ok := bySpec[spec]; !ok {
+			specs = append(specs, spec)
+		}
+		bySpec[spec] = append(bySpec[spec], finding)
+	}
+
+	suites := junitTestSuites{Name: "governance"}
+	for _, spec := range specs {
+		suite := junitTestSuite{Name: repoPath(spec)}
+		failed := map[string]bool{}
+		for _, finding := range bySpec[spec] {
+			name := finding.Rule.Name
+			if name == "" {
+				name = finding.Code
+			}
+			failed[name] = true
+			location := fmt.Sprintf("%s:%d", repoPath(finding.File), finding.Range.Start.Line)
+			details := fmt.Sprintf("%s\n%s [%s]", finding.Message, location, strings.Join(finding.Path, "."))
+			if link := config.ruleLink(name); link != "" {
+				details += "\n" + config.text("report.rule_link", link)
+			}
+			testCase := junitTestCase{
+				Name:      fmt.Sprintf("%s at %s", name, location),
+				ClassName: name,
+				File:      repoPath(finding.File),
+				Line:      findingRegion(finding).StartLine,
+			}
+			switch finding.Severity {
+			case 0:
+				testCase.Failure = &junitMessage{Message: finding.Message, Type: severityName(0), Text: details}
+				suite.Failures++
+			case 1:
+				testCase.Skipped = &junitMessage{Message: finding.Message, Text: details}
+				suite.Skipped++
+			default:
+				testCase.SystemOut = &junitOutput{Text: details}
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		if report.Ruleset != nil {
+			for _, rule := range report.Ruleset.Rules {
+				if !failed[rule.Name] {
+					suite.Cases = append(suite.Cases, junitTestCase{Name: rule.Name, ClassName: rule.Name})
+				}
+			}
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+	return suites
+}
+
+// writeJUnit writes the JUnit report of the run to a file
+func writeJUnit(config *Configuration, report *analysisReport, path string) error {
+	data, err := xml.MarshalIndent(junitReport(config, report), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+	data = append([]byte(xml.Header), append(data, '\n')...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write JUnit report %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// Labels applied to pull and merge requests
+const (
+	labelPassing        = "governance:passing"
+	labelErrors         = "governance:errors"
+	labelOWASPViolation = "owasp-violation"
+)
+
+// governanceLabels derives the labels to add and remove from the results. A
+// run failing without errors, e.g. on a trend gate, isn't labeled passing.
+func governanceLabels(findings []Finding, ruleset *integrations.Ruleset, verdict string) (add, remove []string) {
+	errorCount, _ := countSeverities(findings)
+	switch {
+	case errorCount > 0:
+		add = append(add, labelErrors)
+		remove = append(remove, labelPassing)
+	case verdict == VerdictFail:
+		remove = append(remove, labelPassing, labelErrors)
+	default:
+		add = append(add, labelPassing)
+		remove = append(remove, labelErrors)
+	}
+
+	if hasOWASPViolation(findings, ruleset) {
+		add = append(add, labelOWASPViolation)
+	} else {
+		remove = append(remove, labelOWASPViolation)
+	}
+	return add, remove
+}
+
+// hasOWASPViolation reports whether any finding comes from an OWASP rule, either
+// by the rule's name or the frameworks attached to it
+func hasOWASPViolation(findings []Finding, ruleset *integrations.Ruleset) bool {
+	for _, result := range findings {
+		if strings.HasPrefix(result.Rule.Name, "owasp-") {
+			return true
+		}
+		if rule := ruleset.Rule(result.Rule.Name); rule != nil {
+			for _, framework := range rule.Frameworks {
+				if strings.HasPrefix(strings.ToUpper(framework), "OWASP") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// applyLabels labels the pull or merge request with the governance status. Failures
+// are logged but never fail the run.
+func applyLabels(ctx context.Context, config *Configuration, ci string, ciContext map[string]string, report *analysisReport, logger *zap.Logger) {
+	number, err := strconv.Atoi(ciContext["pull_request"])
+	if err != nil || !integrations.IsPullRequest(ciContext) {
+		logger.Info("Not running on a pull request, skipping labels", zap.String("event_type", ciContext["event_type"]))
+		return
+	}
+
+	add, remove := governanceLabels(report.Findings, report.Ruleset, report.Verdict)
+	logger.Info("Applying governance labels", zap.Strings("add", add), zap.Strings("remove", remove))
+
+	switch ci {
+	case "github":
+		if config.GitHubToken == "" {
+			logger.Warn("github_token is required to apply labels")
+			return
+		}
+		client := integrations.NewGitHubClient(config.GitHubToken, logger)
+		for _, label := range remove {
+			if err := client.RemoveLabel(ctx, number, label); err != nil {
+				logger.Warn("Failed to remove label", zap.String("label", label), zap.Error(err))
+				report.reportingFailed("apply_labels", err)
+			}
+		}
+		if err := client.AddLabels(ctx, number, add); err != nil {
+			logger.Warn("Failed to add labels", zap.Error(err))
+			report.reportingFailed("apply_labels", err)
+		}
+	case "gitlab":
+		if config.GitLabToken == "" {
+			logger.Warn("gitlab_token is required to apply labels")
+			return
+		}
+		client := integrations.NewGitLabClient(config.GitLabToken, logger)
+		if err := client.UpdateMergeRequestLabels(ctx, number, add, remove); err != nil {
+			logger.Warn("Failed to update merge request labels", zap.Error(err))
+			report.reportingFailed("apply_labels", err)
+		}
+	}
+}
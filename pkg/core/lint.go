@@ -0,0 +1,56 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// LintOptions configure an ad-hoc local run
+type LintOptions struct {
+	Files []string // Spec files, globs or git specs to analyze
+}
+
+// RunLint runs the analysis pipeline of a CI run on the files given on the
+// command line, for checking specs while editing them. The settings still come
+// from the flags, inputs and configuration file, but whatever the environment
+// the run publishes nothing and sets no outputs.
+func RunLint(logger *zap.Logger, options LintOptions) (*RunResult, error) {
+	return RunAction(logger, RunOptions{
+		APIPath:       strings.Join(options.Files, ","),
+		APIPathSource: "the lint arguments",
+		Local:         true,
+	})
+}
+
+// LintSummary returns the closing line of a lint run for the terminal
+func (r *RunResult) LintSummary(err error) string {
+	if r.Verdict == VerdictError {
+		if err == nil {
+			return "❌ No verdict reached"
+		}
+		return "❌ " + err.Error()
+	}
+	specs := fmt.Sprintf("%d specs", len(r.Files))
+	if len(r.Files) == 1 {
+		specs = "1 spec"
+	}
+	switch {
+	case r.Errors == 0 && r.Warnings == 0:
+		return fmt.Sprintf("✅ No findings in %s", specs)
+	case r.Verdict == VerdictFail:
+		return fmt.Sprintf("❌ %d errors, %d warnings in %s", r.Errors, r.Warnings, specs)
+	}
+	return fmt.Sprintf("⚠️ %d errors, %d warnings in %s, within the thresholds", r.Errors, r.Warnings, specs)
+}
+
+// localRun turns off everything a run publishes to the pull request, the
+// pipeline or chat, and the run history
+func (c *Configuration) localRun() {
+	c.Local = true
+	c.CheckRun, c.StepSummary, c.PRComment, c.ApplyLabels = false, false, false, false
+	c.MRDiscussions, c.DeploymentGate, c.StatusCheck = false, false, false
+	c.Reviewers, c.SlackWebhook, c.SlackChannels = nil, "", nil
+	c.History, c.TrendGate = "", nil
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest binds spec files to the governance service API records they describe
+type Manifest struct {
+	APIs []ManifestEntry `yaml:"apis"`
+}
+
+// ManifestEntry binds a spec file to an API record
+type ManifestEntry struct {
+	Path    string `yaml:"path"`
+	APIID   string `yaml:"api_id"`
+	APIName string `yaml:"api_name"`
+	Format  string `yaml:"format"`
+}
+
+// Spec format hints passed to the governance service instead of sniffing the content
+var specFormats = []string{"openapi3", "openapi31", "swagger2", "asyncapi"}
+
+// openAPIFormats are the spec formats discover looks for
+var openAPIFormats = []string{"openapi3", "openapi31", "swagger2"}
+
+// specTarget is a spec file to analyze and the API record it belongs to
+type specTarget struct {
+	Path    string
+	APIID   string
+	APIName string
+	Format  string
+}
+
+// loadManifest reads and validates a manifest file
+func loadManifest(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+
+	manifest := &Manifest{}
+	if err := yaml.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	for i, entry := range manifest.APIs {
+		if entry.Path == "" {
+			return nil, fmt.Errorf("manifest %s: apis[%d]: path is required", path, i)
+		}
+		if entry.Format != "" && !slices.Contains(specFormats, entry.Format) {
+			return nil, fmt.Errorf("manifest %s: apis[%d]: format must be one of: %s", path, i, strings.Join(specFormats, ", "))
+		}
+	}
+	return manifest, nil
+}
+
+// resolveTargets returns the specs to analyze, the files api_path names or
+// matches, or with discover and neither api_path nor a manifest the OpenAPI
+// and Swagger specs found in the checkout. With a manifest every listed spec is analyzed, unless api_path
+// narrows the run down to some of them; specs it names that the manifest
+// doesn't list are analyzed anonymously.
+func resolveTargets(config *Configuration) ([]specTarget, error) {
+	apiPaths, err := expandSpecPaths(config.APIPath)
+	if err != nil {
+		return nil, err
+	}
+	if config.Manifest == "" {
+		if len(apiPaths) == 0 && config.Discover {
+			if apiPaths, err = discoverSpecs(".", config.MaxSpecSize, openAPIFormats...); err != nil {
+				return nil, err
+			}
+			if len(apiPaths) == 0 {
+				return nil, fmt.Errorf("discover: no OpenAPI or Swagger specs found in the checkout")
+			}
+		}
+		if len(apiPaths) == 0 {
+			return nil, fmt.Errorf("api_path doesn't name any spec")
+		}
+		targets := make([]specTarget, 0, len(apiPaths))
+		for _, apiPath := range apiPaths {
+			targets = append(targets, specTarget{Path: apiPath})
+		}
+		return targets, nil
+	}
+
+	manifest, err := loadManifest(config.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var listed []specTarget
+	for _, entry := range manifest.APIs {
+		listed = append(listed, specTarget{Path: normalizeSpecPath(entry.Path), APIID: entry.APIID, APIName: entry.APIName, Format: entry.Format})
+	}
+	if len(apiPaths) == 0 {
+		if len(listed) == 0 {
+			return nil, fmt.Errorf("manifest %s doesn't list any APIs", config.Manifest)
+		}
+		return listed, nil
+	}
+
+	targets := make([]specTarget, 0, len(apiPaths))
+	for _, apiPath := range apiPaths {
+		i := slices.IndexFunc(listed, func(target specTarget) bool { return target.Path == apiPath })
+		if i < 0 {
+			// The spec isn't bound to an API record, analyze it anonymously
+			targets = append(targets, specTarget{Path: apiPath})
+			continue
+		}
+		targets = append(targets, listed[i])
+	}
+	return targets, nil
+}
@@ -0,0 +1,222 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Defaults of the Markdown report
+const (
+	defaultReportDir      = "governance-report"
+	defaultReportPageSize = 500
+)
+
+// Ways of splitting the Markdown report into pages
+const (
+	pagesByFile = "file"
+	pagesByTag  = "tag"
+)
+
+// reportPagings are the known ways of splitting the Markdown report
+var reportPagings = []string{pagesByFile, pagesByTag}
+
+// untaggedSection holds the findings outside tagged operations in a report
+// paged by tag
+const untaggedSection = "untagged"
+
+// reportIndex is the file name of the Markdown report's index page
+const reportIndex = "index.md"
+
+// reportPage is a page of the Markdown report
+type reportPage struct {
+	Section  string // Spec file or tag the findings are on
+	File     string // File name in the report directory
+	Part     int    // 1-based part of the section
+	Parts    int
+	Findings []Finding
+}
+
+// title returns the page title, numbering the parts of split sections
+func (p reportPage) title() string {
+	if p.Parts > 1 {
+		return fmt.Sprintf("%s (%d/%d)", p.Section, p.Part, p.Parts)
+	}
+	return p.Section
+}
+
+// reportSections groups the findings by spec file, in report order, or by tag,
+// alphabetically and the untagged findings last. Findings on operations with
+// several tags are in each of their sections.
+func reportSections(findings []Finding, paging string) ([]string, map[string][]Finding) {
+	var sections []string
+	bySection := map[string][]Finding{}
+	add := func(section string, finding Finding) {
+		if _, ok := bySection[section]; !ok {
+			sections = append(sections, section)
+		}
+		bySection[section] = append(bySection[section], finding)
+	}
+	for _, finding := range findings {
+		if paging == pagesByFile {
+			add(repoPath(finding.File), finding)
+			continue
+		}
+		for _, tag := range finding.Tags {
+			add(tag, finding)
+		}
+		if len(finding.Tags) == 0 {
+			add(untaggedSection, finding)
+		}
+	}
+	if paging == pagesByTag {
+		sort.SliceStable(sections, func(i, j int) bool {
+			if (sections[i] == untaggedSection) != (sections[j] == untaggedSection) {
+				return sections[j] == untaggedSection
+			}
+			return sections[i] < sections[j]
+		})
+	}
+	return sections, bySection
+}
+
+// pageSlugChars matches the runs of characters left out of page file names
+var pageSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// reportPages splits the findings into pages of at most pageSize findings per
+// section, the most severe findings of a section first
+func reportPages(findings []Finding, paging string, pageSize int) []reportPage {
+	sections, bySection := reportSections(findings, paging)
+	var pages []reportPage
+	used := map[string]bool{reportIndex: true}
+	for _, section := range sections {
+		sectionFindings := bySection[section]
+		sort.SliceStable(sectionFindings, func(i, j int) bool { return sectionFindings[i].Severity < sectionFindings[j].Severity })
+
+		slug := strings.Trim(pageSlugChars.ReplaceAllString(strings.ToLower(section), "-"), "-")
+		if slug == "" {
+			slug = "page"
+		}
+		parts := (len(sectionFindings) + pageSize - 1) / pageSize
+		for part := 1; part <= parts; part++ {
+			name := slug
+			if part > 1 {
+				name = fmt.Sprintf("%s-%d", slug, part)
+			}
+			// Sections with the same slug, e.g. a.yaml and a.json, get numbered files
+			file := name + ".md"
+			for n := 2; used[file]; n++ {
+				file = fmt.Sprintf("%s_%d.md", name, n)
+			}
+			used[file] = true
+
+			end := min(part*pageSize, len(sectionFindings))
+			pages = append(pages, reportPage{
+				Section:  section,
+				File:     file,
+				Part:     part,
+				Parts:    parts,
+				Findings: sectionFindings[(part-1)*pageSize : end],
+			})
+		}
+	}
+	return pages
+}
+
+// markdownIndex renders the index page of the Markdown report: the verdict,
+// the counts by severity and a table of the pages with their counts
+func markdownIndex(config *Configuration, report *analysisReport, pages []reportPage) string {
+	findings := report.Findings
+	var index strings.Builder
+	fmt.Fprintf(&index, "# %s\n\n%s\n\n", config.text("report.title"), verdictMarkdown(report.Verdict))
+
+	counts := map[string]int{}
+	for _, finding := range findings {
+		counts[severityName(finding.Severity)]++
+	}
+	fmt.Fprintf(&index, "| Severity | Findings |\n|---|---:|\n")
+	for _, level := range []int{0, 1, 2} {
+		style := config.severityStyle(level)
+		fmt.Fprintf(&index, "| %s %s | %d |\n", style.Icon, style.Label, counts[severityName(level)])
+	}
+	if len(report.Excluded) > 0 {
+		fmt.Fprintf(&index, "| Excluded | %d |\n", len(report.Excluded))
+	}
+	fmt.Fprintf(&index, "\n_Run started %s_\n", formatTimestamp(report.Started))
+	if len(pages) == 0 {
+		return index.String()
+	}
+
+	heading := "File"
+	if config.ReportPages == pagesByTag {
+		heading = "Tag"
+	}
+	errorStyle, warningStyle, infoStyle := config.severityStyle(0), config.severityStyle(1), config.severityStyle(2)
+	fmt.Fprintf(&index, "\n| %s | %s | %s | %s | Findings |\n|---|---:|---:|---:|---:|\n",
+		heading, errorStyle.Icon, warningStyle.Icon, infoStyle.Icon)
+	for _, page := range pages {
+		pageCounts := map[string]int{}
+		for _, finding := range page.Findings {
+			pageCounts[severityName(finding.Severity)]++
+		}
+		fmt.Fprintf(&index, "| [%s](%s) | %d | %d | %d | %d |\n", markdownCell(page.title()), page.File,
+			pageCounts[severityName(0)], pageCounts[severityName(1)], pageCounts[severityName(2)], len(page.Findings))
+	}
+	return index.String()
+}
+
+// markdownPage renders a page of the Markdown report, the findings with their
+// snippets in collapsible sections and links to the index and the
+// neighbouring pages
+func markdownPage(config *Configuration, pages []reportPage, i int, fileLines map[string][]string) string {
+	page := pages[i]
+	var content strings.Builder
+	fmt.Fprintf(&content, "# %s\n\n", page.title())
+	links := []string{fmt.Sprintf("[Index](%s)", reportIndex)}
+	if i > 0 {
+		links = append(links, fmt.Sprintf("[Previous](%s)", pages[i-1].File))
+	}
+	if i < len(pages)-1 {
+		links = append(links, fmt.Sprintf("[Next](%s)", pages[i+1].File))
+	}
+	fmt.Fprintf(&content, "%s\n\n", strings.Join(links, " · "))
+
+	for _, finding := range page.Findings {
+		if _, ok := fileLines[finding.File]; !ok {
+			data, _ := readSpecFile(finding.File)
+			fileLines[finding.File] = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		}
+		fmt.Fprintf(&content, "**%s**\n\n", config.ruleMarkdown(finding.Rule.Name))
+		writeSummaryFinding(&content, config, finding, fileLines[finding.File])
+	}
+	return content.String()
+}
+
+// writeMarkdownReport writes the Markdown report of the run to a directory: an
+// index and the findings paged by spec file or tag
+func writeMarkdownReport(config *Configuration, report *analysisReport, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create report directory %s: %w", dir, err)
+	}
+	pages := reportPages(report.Findings, config.ReportPages, config.ReportPageSize)
+	write := func(name, content string) error {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write Markdown report %s: %w", path, err)
+		}
+		return nil
+	}
+	if err := write(reportIndex, markdownIndex(config, report, pages)); err != nil {
+		return err
+	}
+	fileLines := map[string][]string{}
+	for i, page := range pages {
+		if err := write(page.File, markdownPage(config, pages, i, fileLines)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
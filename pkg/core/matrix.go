@@ -0,0 +1,59 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// FileResult is the outcome of a single spec in a multi-spec run
+type FileResult struct {
+	File     string `json:"file"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+	Verdict  string `json:"verdict"`
+}
+
+// fileMatrix returns the per-file outcomes, applying the thresholds to each spec
+func fileMatrix(config *Configuration, report *analysisReport) []FileResult {
+	byFile := map[string][]Finding{}
+	for _, finding := range report.Findings {
+		byFile[finding.spec()] = append(byFile[finding.spec()], finding)
+	}
+
+	matrix := make([]FileResult, 0, len(report.Files))
+	for _, file := range report.Files {
+		errorCount, warningCount := countSeverities(byFile[file])
+		verdict := VerdictPass
+		if checkThresholds(config, errorCount, warningCount) != nil {
+			verdict = VerdictFail
+			if config.Mode == ModeAdvisory {
+				verdict = VerdictWarn
+			}
+		}
+		matrix = append(matrix, FileResult{File: file, Errors: errorCount, Warnings: warningCount, Verdict: verdict})
+	}
+	return matrix
+}
+
+// setMatrixOutput sets the files output to the per-file matrix as compact JSON
+func setMatrixOutput(config *Configuration, matrix []FileResult) {
+	if files, err := json.Marshal(matrix); err == nil {
+		setOutput(config, "files", string(files))
+	}
+}
+
+// printFileMatrix prints the per-file section at the top of the console report
+func printFileMatrix(w io.Writer, config *Configuration, matrix []FileResult) {
+	width := len([]rune(config.text("report.file")))
+	for _, result := range matrix {
+		width = max(width, len([]rune(result.File)))
+	}
+
+	printHeading(w, config.text("report.files"))
+	fmt.Fprintf(w, "    %-*s  %6s  %8s  %s\n", width, config.text("report.file"), config.text("report.errors"), config.text("report.warnings"), config.text("report.verdict"))
+	for _, result := range matrix {
+		fmt.Fprintf(w, "    %-*s  %6d  %8d  %s\n", width, result.File, result.Errors, result.Warnings, config.text("verdict."+result.Verdict))
+	}
+	fmt.Fprintln(w, "---------------------------------------")
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// maxDiscussions caps the discussions started per run, so a spec with many
+// findings doesn't flood the merge request
+const maxDiscussions = 25
+
+// discussionMarker tags a discussion with the fingerprint of its finding, so
+// later runs skip findings already discussed and resolve the stale ones
+const discussionMarker = "<!-- governance-action:finding %s -->"
+
+// discussionFingerprint matches the marker of a finding discussion
+var discussionFingerprint = regexp.MustCompile(`<!-- governance-action:finding ([0-9a-f]+) -->`)
+
+// discussionBody renders a finding as the Markdown of a merge request
+// discussion. Discussions that couldn't be anchored to the diff name the
+// location instead.
+func discussionBody(config *Configuration, report *analysisReport, finding Finding, anchored bool) string {
+	style := config.severityStyle(finding.Severity)
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s **%s** %s: %s\n", style.Icon, style.Label, config.ruleMarkdown(finding.Rule.Name), finding.Message)
+	if !anchored {
+		location := fmt.Sprintf("`%s:%d`", repoPath(finding.File), finding.Range.Start.Line)
+		if finding.Permalink != "" {
+			location = fmt.Sprintf("[%s:%d](%s)", repoPath(finding.File), finding.Range.Start.Line, finding.Permalink)
+		}
+		fmt.Fprintf(&body, "\n%s\n", location)
+	}
+	if rule := report.Ruleset.Rule(finding.Rule.Name); rule != nil && rule.Remediation != "" {
+		fmt.Fprintf(&body, "\n%s\n", config.text("report.remediation", rule.Remediation))
+	}
+	fmt.Fprintf(&body, "\n"+discussionMarker+"\n", finding.Fingerprint())
+	return body.String()
+}
+
+// gitLabDiscussionClient returns the client discussions are posted with:
+// gitlab_token, or the pipeline's CI job token without one
+func gitLabDiscussionClient(config *Configuration, logger *zap.Logger) *integrations.GitLabClient {
+	if config.GitLabToken != "" {
+		return integrations.NewGitLabClient(config.GitLabToken, logger)
+	}
+	if jobToken := os.Getenv("CI_JOB_TOKEN"); jobToken != "" {
+		return integrations.NewGitLabJobTokenClient(jobToken, logger)
+	}
+	return nil
+}
+
+// publishMRDiscussions starts a merge request discussion on the spec line of
+// each finding not discussed by an earlier run. Findings on lines outside the
+// diff get a discussion naming their location. With resolve_stale_discussions
+// the discussions of findings no longer reported are resolved. Failures are
+// logged but never fail the run.
+func publishMRDiscussions(ctx context.Context, config *Configuration, ciContext map[string]string, report *analysisReport, logger *zap.Logger) {
+	iid, err := strconv.Atoi(ciContext["pull_request"])
+	if err != nil || !integrations.IsPullRequest(ciContext) {
+		logger.Info("Not running on a merge request, skipping discussions", zap.String("event_type", ciContext["event_type"]))
+		return
+	}
+	client := gitLabDiscussionClient(config, logger)
+	if client == nil {
+		logger.Warn("gitlab_token or CI_JOB_TOKEN is required to start merge request discussions")
+		return
+	}
+
+	refs, err := client.MergeRequestDiffRefs(ctx, iid)
+	if err != nil {
+		logger.Warn("Failed to get the merge request diff, skipping discussions", zap.Error(err))
+		report.reportingFailed("mr_discussions", err)
+		return
+	}
+	discussions, err := client.ListDiscussions(ctx, iid)
+	if err != nil {
+		logger.Warn("Failed to list merge request discussions, skipping discussions", zap.Error(err))
+		report.reportingFailed("mr_discussions", err)
+		return
+	}
+	existing := map[string]integrations.Discussion{}
+	for _, discussion := range discussions {
+		if len(discussion.Notes) == 0 {
+			continue
+		}
+		if match := discussionFingerprint.FindStringSubmatch(discussion.Notes[0].Body); match != nil {
+			existing[match[1]] = discussion
+		}
+	}
+
+	current := map[string]bool{}
+	for _, finding := range report.Findings {
+		current[finding.Fingerprint()] = true
+	}
+
+	started := 0
+	discussed := map[string]bool{}
+	for _, finding := range report.Findings {
+		fingerprint := finding.Fingerprint()
+		// Discussions resolved by reviewers stay resolved, and repeated
+		// findings share a discussion
+		if _, ok := existing[fingerprint]; ok || discussed[fingerprint] {
+			continue
+		}
+		discussed[fingerprint] = true
+		if started == maxDiscussions {
+			logger.Info("Discussion limit reached, leaving the remaining findings to the report", zap.Int("limit", maxDiscussions))
+			break
+		}
+		started++
+
+		path := repoPath(finding.File)
+		position := &integrations.DiscussionPosition{
+			PositionType: "text",
+			BaseSHA:      refs.BaseSHA,
+			HeadSHA:      refs.HeadSHA,
+			StartSHA:     refs.StartSHA,
+			OldPath:      path,
+			NewPath:      path,
+			NewLine:      findingRegion(finding).StartLine,
+		}
+		err := client.CreateDiscussion(ctx, iid, discussionBody(config, report, finding, true), position)
+		// GitLab rejects positions on lines outside the diff
+		var apiErr *integrations.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+			err = client.CreateDiscussion(ctx, iid, discussionBody(config, report, finding, false), nil)
+		}
+		if err != nil {
+			logger.Warn("Failed to start merge request discussion", zap.String("fingerprint", fingerprint), zap.Error(err))
+			report.reportingFailed("mr_discussions", err)
+		}
+	}
+
+	if !config.ResolveDiscussions {
+		return
+	}
+	for fingerprint, discussion := range existing {
+		if current[fingerprint] || discussion.Resolved() {
+			continue
+		}
+		if err := client.ResolveDiscussion(ctx, iid, discussion.ID, true); err != nil {
+			logger.Warn("Failed to resolve stale discussion", zap.String("fingerprint", fingerprint), zap.Error(err))
+			report.reportingFailed("mr_discussions", err)
+		}
+	}
+}
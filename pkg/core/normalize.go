@@ -0,0 +1,86 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// normalizeSpec rewrites spec content into a canonical form, so cosmetic edits
+// such as reordered keys, comments, quoting or trailing whitespace send the same
+// content and get the same ranges back. Only the layout changes: nothing is
+// added, so the findings on what the spec leaves out are still reported.
+func normalizeSpec(content string) (string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		return "", fmt.Errorf("failed to parse spec: %w", err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return "", fmt.Errorf("spec is empty")
+	}
+
+	canonicalize(&root)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return "", fmt.Errorf("failed to encode spec: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode spec: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// canonicalize sorts mapping keys and drops comments, quoting styles and
+// trailing whitespace in multi-line strings. Aliases are expanded first, as
+// sorting could move an alias before its anchor. The expansion shares the
+// anchored node's children, which sort the same wherever they appear.
+func canonicalize(node *yaml.Node) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		*node = *node.Alias
+	}
+	node.Anchor = ""
+	node.HeadComment, node.LineComment, node.FootComment = "", "", ""
+	switch node.Kind {
+	case yaml.MappingNode:
+		pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+		}
+		sort.SliceStable(pairs, func(i, j int) bool { return pairs[i][0].Value < pairs[j][0].Value })
+		node.Content = node.Content[:0]
+		for _, pair := range pairs {
+			node.Content = append(node.Content, pair[0], pair[1])
+		}
+	case yaml.ScalarNode:
+		node.Style = 0
+		if node.ShortTag() == "!!str" && strings.Contains(node.Value, "\n") {
+			lines := strings.Split(node.Value, "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight(line, " \t")
+			}
+			node.Value = strings.Join(lines, "\n")
+		}
+	}
+	for _, child := range node.Content {
+		canonicalize(child)
+	}
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNormalizeSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "sorts keys",
+			content: "paths: {}\ninfo: {version: \"1\", title: t}\nopenapi: 3.0.3\n",
+			want:    "info: {title: t, version: \"1\"}\nopenapi: 3.0.3\npaths: {}\n",
+		},
+		{
+			name:    "drops comments and quoting",
+			content: "# spec\nopenapi: '3.0.3' # version\ninfo:\n  title: \"t\"\n",
+			want:    "info:\n  title: t\nopenapi: 3.0.3\n",
+		},
+		{
+			name:    "trims trailing whitespace in multi-line strings",
+			content: "info:\n  description: |\n    first  \n    second\t\n",
+			want:    "info:\n  description: |\n    first\n    second\n",
+		},
+		{
+			name:    "expands an alias sorted before its anchor",
+			content: "x-resp: &r\n  description: ok\ncomponents:\n  responses:\n    Ok: *r\n",
+			want:    "components:\n  responses:\n    Ok:\n      description: ok\nx-resp:\n  description: ok\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeSpec(tt.content)
+			if err != nil {
+				t.Fatalf("normalizeSpec: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSpecKeepsContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "plain", content: "openapi: 3.0.3\ninfo: {title: t, version: \"1\"}\npaths: {}\n"},
+		{name: "alias before anchor once sorted", content: "z: &r {description: ok}\na: *r\n"},
+		{name: "nested anchors", content: "z: &outer\n  y: &inner [1, 2]\n  b: *inner\na: *outer\n"},
+		{name: "merge key", content: "z: &base {description: ok, summary: s}\na:\n  <<: *base\n  description: other\n"},
+		{name: "merge sequence", content: "z: &x {a: 1}\ny: &y {a: 2, b: 2}\nc:\n  <<: [*x, *y]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeSpec(tt.content)
+			if err != nil {
+				t.Fatalf("normalizeSpec: %v", err)
+			}
+			var before, after interface{}
+			if err := yaml.Unmarshal([]byte(tt.content), &before); err != nil {
+				t.Fatalf("parsing the input: %v", err)
+			}
+			if err := yaml.Unmarshal([]byte(got), &after); err != nil {
+				t.Fatalf("normalized spec doesn't parse: %v\n%s", err, got)
+			}
+			if !reflect.DeepEqual(before, after) {
+				t.Errorf("normalized spec changed the content: got %v, want %v", after, before)
+			}
+		})
+	}
+}
+
+func TestNormalizeSpecErrors(t *testing.T) {
+	for _, content := range []string{"", "key: [unclosed"} {
+		if _, err := normalizeSpec(content); err == nil {
+			t.Errorf("normalizeSpec(%q): expected an error", content)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// policyBundleService fetches the organization policy bundle from the
+// governance service rather than a URL
+const policyBundleService = "service"
+
+// orgPolicySource describes settings taken from the policy bundle
+const orgPolicySource = "the organization policy bundle"
+
+// severityLevels maps severity names to the levels the service reports
+var severityLevels = map[string]int{
+	severityError:   0,
+	severityWarning: 1,
+	severityInfo:    2,
+}
+
+// OrgPolicy is the policy bundle an organization distributes centrally, so
+// policy changes reach every repository without editing their configuration
+type OrgPolicy struct {
+	// MaxErrors and MaxWarnings cap the thresholds, repositories can only
+	// configure stricter ones
+	MaxErrors   *int `yaml:"max_errors"`
+	MaxWarnings *int `yaml:"max_warnings"`
+	// Ignore lists the findings that never count towards the result
+	Ignore []PolicyIgnore `yaml:"ignore"`
+	// Severities remap the severity of rules, by rule name
+	Severities map[string]string `yaml:"severities"`
+	// Rulesets are the rulesets repositories must be analyzed with. Runs without
+	// a ruleset use the first one.
+	Rulesets []string `yaml:"rulesets"`
+}
+
+// PolicyIgnore ignores the findings of a rule, optionally only in the spec
+// files matching a glob
+type PolicyIgnore struct {
+	Rule   string `yaml:"rule"`
+	File   string `yaml:"file"`
+	Reason string `yaml:"reason"`
+}
+
+// loadOrgPolicy fetches and parses the organization policy bundle from a URL
+// or the governance service. A service without a bundle has no policy.
+func loadOrgPolicy(ctx context.Context, config *Configuration, logger *zap.Logger) (*OrgPolicy, error) {
+	var content []byte
+	var err error
+	if config.PolicyBundle == policyBundleService {
+		client := integrations.NewGovernanceClient(config.GovernanceService, config.GovernanceAuth, logger)
+		client.SetTenant(config.OrgID, config.TeamID)
+		client.SetTimeout(config.Timeout)
+		content, err = client.GetPolicyBundle(ctx)
+	} else {
+		content, err = integrations.FetchPolicyBundle(ctx, config.PolicyBundle, config.PolicyBundleToken)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch policy bundle: %w", err)
+	}
+	if content == nil {
+		logger.Info("Governance service has no policy bundle for the organization")
+		return nil, nil
+	}
+
+	// JSON bundles are valid YAML
+	policy := &OrgPolicy{}
+	if err := yaml.Unmarshal(content, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy bundle: %w", err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy bundle: %w", err)
+	}
+	return policy, nil
+}
+
+// enforceOrgPolicy loads the configured policy bundle and applies it to the
+// configuration, keeping it for the findings
+func enforceOrgPolicy(ctx context.Context, config *Configuration, logger *zap.Logger) error {
+	if config.PolicyBundle == "" {
+		return nil
+	}
+	policy, err := loadOrgPolicy(ctx, config, logger)
+	if err != nil || policy == nil {
+		return err
+	}
+	if err := policy.apply(config); err != nil {
+		return err
+	}
+	config.OrgPolicy = policy
+	logger.Info("Applied organization policy bundle", zap.String("source", config.PolicyBundle),
+		zap.Int("ignores", len(policy.Ignore)), zap.Int("severity_overrides", len(policy.Severities)))
+	return nil
+}
+
+// validate checks the severity names and ignore entries of the bundle
+func (p *OrgPolicy) validate() error {
+	for rule, severity := range p.Severities {
+		if _, ok := severityLevels[severity]; !ok {
+			return fmt.Errorf("severities: %s: unknown severity %s, must be one of: %s, %s, %s", rule, severity, severityError, severityWarning, severityInfo)
+		}
+	}
+	for _, ignore := range p.Ignore {
+		if ignore.Rule == "" {
+			return fmt.Errorf("ignore: rule is required")
+		}
+	}
+	return nil
+}
+
+// apply enforces the bundle's thresholds and rulesets on the configuration
+func (p *OrgPolicy) apply(config *Configuration) error {
+	if capped := capThreshold(config.MaxErrors, p.MaxErrors); capped != config.MaxErrors {
+		config.MaxErrors = capped
+		config.override("max_errors", orgPolicySource)
+	}
+	if capped := capThreshold(config.MaxWarnings, p.MaxWarnings); capped != config.MaxWarnings {
+		config.MaxWarnings = capped
+		config.override("max_warnings", orgPolicySource)
+	}
+
+	if len(p.Rulesets) == 0 {
+		return nil
+	}
+	if config.RulesetFile != "" {
+		return fmt.Errorf("ruleset_file is not allowed by the organization policy, use one of: %s", strings.Join(p.Rulesets, ", "))
+	}
+	if config.RuleID == "" {
+		config.RuleID = p.Rulesets[0]
+		config.override("rule_id", orgPolicySource)
+		return nil
+	}
+	if !slices.Contains(p.Rulesets, config.RuleID) {
+		return fmt.Errorf("ruleset %s is not allowed by the organization policy, use one of: %s", config.RuleID, strings.Join(p.Rulesets, ", "))
+	}
+	return nil
+}
+
+// capThreshold returns the stricter of the configured and the policy threshold
+func capThreshold(configured, policy *int) *int {
+	if policy == nil || (configured != nil && *configured <= *policy) {
+		return configured
+	}
+	return policy
+}
+
+// filter remaps the severity of findings and splits off the ignored ones
+func (p *OrgPolicy) filter(findings []Finding) (kept, ignored []Finding) {
+	if p == nil {
+		return findings, nil
+	}
+	for _, finding := range findings {
+		if severity, ok := p.Severities[finding.Rule.Name]; ok {
+			finding.Severity = severityLevels[severity]
+		}
+		if ignore := p.ignores(finding); ignore != nil {
+			finding.ExclusionReason = "ignored by organization policy"
+			if ignore.Reason != "" {
+				finding.ExclusionReason += ": " + ignore.Reason
+			}
+			ignored = append(ignored, finding)
+			continue
+		}
+		kept = append(kept, finding)
+	}
+	return kept, ignored
+}
+
+// ignores returns the ignore entry matching a finding, or nil
+func (p *OrgPolicy) ignores(finding Finding) *PolicyIgnore {
+	for i, ignore := range p.Ignore {
+		if !globMatch(ignore.Rule, finding.Rule.Name) {
+			continue
+		}
+		if ignore.File != "" && !globMatch(ignore.File, repoPath(finding.File)) {
+			continue
+		}
+		return &p.Ignore[i]
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// blobURL returns the base URL of the repository files at the analyzed commit,
+// or "" when not running in CI
+func blobURL(ci string, ciContext map[string]string) string {
+	commit := ciContext["commit"]
+	if commit == "" {
+		return ""
+	}
+	switch ci {
+	case "github":
+		server, repository := os.Getenv("GITHUB_SERVER_URL"), ciContext["repository"]
+		if server == "" || repository == "" {
+			return ""
+		}
+		return server + "/" + repository + "/blob/" + commit
+	case "gitlab":
+		project := os.Getenv("CI_PROJECT_URL")
+		if project == "" {
+			return ""
+		}
+		return project + "/-/blob/" + commit
+	}
+	return ""
+}
+
+// permalink returns the URL of a finding's lines at the analyzed commit. GitHub
+// anchors line ranges as #L3-L7, GitLab as #L3-7. Files outside the repository
+// have no permalink, and findings in git specs link to the file at their ref.
+func permalink(ci, base string, finding Finding) string {
+	file := repoPath(finding.File)
+	// Git specs link to their ref rather than the analyzed commit, remote
+	// tracking branches to the branch of the same name
+	if ref, name, ok := parseGitSpec(file); ok && base != "" {
+		for _, prefix := range []string{"refs/remotes/", "origin/", "refs/heads/", "refs/tags/"} {
+			ref = strings.TrimPrefix(ref, prefix)
+		}
+		base, file = base[:strings.LastIndex(base, "/")+1]+ref, name
+	}
+	if base == "" || file == "" || filepath.IsAbs(file) {
+		return ""
+	}
+	start, end := int(finding.Range.Start.Line), int(finding.Range.End.Line)
+	if start < 1 {
+		start, end = finding.Location.StartLine, finding.Location.EndLine
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(file, "/") {
+		segments = append(segments, url.PathEscape(segment))
+	}
+	link := base + "/" + strings.Join(segments, "/")
+	if start < 1 {
+		return link
+	}
+	link += "#L" + strconv.Itoa(start)
+	if end > start {
+		if ci == "github" {
+			link += "-L" + strconv.Itoa(end)
+		} else {
+			link += "-" + strconv.Itoa(end)
+		}
+	}
+	return link
+}
+
+// linkFindings sets the permalink of each finding
+func linkFindings(ci string, ciContext map[string]string, findings []Finding) {
+	base := blobURL(ci, ciContext)
+	if base == "" {
+		return
+	}
+	for i := range findings {
+		findings[i].Permalink = permalink(ci, base, findings[i])
+	}
+}
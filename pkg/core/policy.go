@@ -0,0 +1,68 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// Enforcement modes
+const (
+	ModeEnforce  = "enforce"  // Errors fail the run
+	ModeAdvisory = "advisory" // Findings are reported but never fail the run
+)
+
+// validateMode checks that mode is a known enforcement mode
+func validateMode(mode string) error {
+	if mode != ModeEnforce && mode != ModeAdvisory {
+		return fmt.Errorf("mode must be one of: %s, %s", ModeEnforce, ModeAdvisory)
+	}
+	return nil
+}
+
+// resolveRuleset returns the first branch ruleset matching the branch, or nil
+func resolveRuleset(rulesets []BranchRuleset, branch string) *BranchRuleset {
+	if branch == "" {
+		return nil
+	}
+	for i, ruleset := range rulesets {
+		if globMatch(ruleset.Branch, branch) {
+			return &rulesets[i]
+		}
+	}
+	return nil
+}
+
+// defaultBranchPattern matches the repository's default branch in policies
+const defaultBranchPattern = "~DEFAULT_BRANCH"
+
+// resolveMode determines the enforcement mode for the run. An explicit mode
+// takes precedence, then the first policy matching the branch and event, then
+// enforce.
+func resolveMode(explicit string, policies []BranchPolicy, ciContext map[string]string) string {
+	if explicit != "" {
+		return explicit
+	}
+	for _, policy := range policies {
+		if policy.matches(ciContext) {
+			return policy.Mode
+		}
+	}
+	return ModeEnforce
+}
+
+// matches reports whether the policy applies to the run's branch and event
+func (p BranchPolicy) matches(ciContext map[string]string) bool {
+	if p.Event != "" && p.Event != ciContext["event_type"] {
+		return false
+	}
+	switch {
+	case p.Branch == "":
+		return p.Event != ""
+	case p.Branch == defaultBranchPattern:
+		return integrations.OnDefaultBranch(ciContext)
+	case ciContext["branch"] == "":
+		return false
+	}
+	return globMatch(p.Branch, ciContext["branch"])
+}
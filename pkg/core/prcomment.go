@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// prCommentMarker identifies the action's report comment, so later runs update
+// it instead of adding a comment per push
+const prCommentMarker = "<!-- governance-action:report -->"
+
+// maxCommentFindings caps the findings listed in the pull request comment
+const maxCommentFindings = 10
+
+// prCommentBody renders the report as the Markdown of a pull request comment:
+// the verdict, a summary table of the counts and the most severe findings
+func prCommentBody(config *Configuration, report *analysisReport) string {
+	findings := report.Findings
+	errorCount, warningCount := countSeverities(findings)
+	errorStyle, warningStyle := config.severityStyle(0), config.severityStyle(1)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\n## Governance Analysis Report\n\n%s\n\n", prCommentMarker, verdictMarkdown(report.Verdict))
+
+	fmt.Fprintf(&body, "| | Count |\n|---|---:|\n")
+	fmt.Fprintf(&body, "| %s %s | %d |\n", errorStyle.Icon, errorStyle.Label, errorCount)
+	fmt.Fprintf(&body, "| %s %s | %d |\n", warningStyle.Icon, warningStyle.Label, warningCount)
+	fmt.Fprintf(&body, "| Total issues | %d |\n", len(findings))
+	if len(report.Excluded) > 0 {
+		fmt.Fprintf(&body, "| Excluded | %d |\n", len(report.Excluded))
+	}
+	if coverage := report.Coverage; coverage != nil && coverage.Operations > 0 {
+		fmt.Fprintf(&body, "| Operations governed | %d of %d (%d%%) |\n",
+			coverage.governedCount(), coverage.Operations, coverage.percent())
+	}
+	if rules := report.Rules; rules != nil {
+		fmt.Fprintf(&body, "| Rules passed | %d of %d |\n", len(rules.Passed), len(rules.Passed)+len(rules.Failed))
+	}
+
+	if len(findings) > 0 {
+		top := make([]Finding, len(findings))
+		copy(top, findings)
+		sort.SliceStable(top, func(i, j int) bool { return top[i].Severity < top[j].Severity })
+
+		fmt.Fprintf(&body, "\n### Top findings\n\n| | Rule | Message | Location |\n|---|---|---|---|\n")
+		for i, finding := range top {
+			if i == maxCommentFindings {
+				break
+			}
+			style := config.severityStyle(finding.Severity)
+			location := fmt.Sprintf("%s:%d", repoPath(finding.File), finding.Range.Start.Line)
+			if finding.Permalink != "" {
+				location = fmt.Sprintf("[%s](%s)", location, finding.Permalink)
+			}
+			fmt.Fprintf(&body, "| %s | %s | %s | %s |\n", style.Icon, config.ruleMarkdown(finding.Rule.Name), markdownCell(finding.Message), location)
+		}
+		if len(top) > maxCommentFindings {
+			fmt.Fprintf(&body, "\n_… and %d more_\n", len(top)-maxCommentFindings)
+		}
+	}
+
+	if report.ReportURL != "" {
+		fmt.Fprintf(&body, "\n[Full report](%s)\n", report.ReportURL)
+	}
+	fmt.Fprintf(&body, "\n_Run started %s_\n", formatTimestamp(report.Started))
+	return body.String()
+}
+
+// verdictMarkdown renders the verdict of the run as a Markdown line
+func verdictMarkdown(verdict string) string {
+	switch verdict {
+	case VerdictPass:
+		return "✅ **Passed** the governance checks."
+	case VerdictWarn:
+		return "⚠️ **Failed** the governance checks, not blocking in advisory mode."
+	default:
+		return "❌ **Failed** the governance checks."
+	}
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// publishPRComment posts the report as a comment on the pull request, updating
+// the comment of a previous run if there is one. Failures are logged but never
+// fail the run.
+func publishPRComment(ctx context.Context, config *Configuration, ciContext map[string]string, report *analysisReport, logger *zap.Logger) {
+	number, err := strconv.Atoi(ciContext["pull_request"])
+	if err != nil || !integrations.IsPullRequest(ciContext) {
+		logger.Info("Not running on a pull request, skipping the report comment", zap.String("event_type", ciContext["event_type"]))
+		return
+	}
+	if config.GitHubToken == "" {
+		logger.Warn("github_token is required to comment on the pull request")
+		return
+	}
+	client := integrations.NewGitHubClient(config.GitHubToken, logger)
+	body := prCommentBody(config, report)
+
+	existing, err := client.FindComment(ctx, number, prCommentMarker)
+	if err != nil {
+		logger.Warn("Failed to look up previous report comment", zap.Error(err))
+	}
+	if existing != nil {
+		err = client.UpdateComment(ctx, existing.ID, body)
+	} else {
+		err = client.CreateComment(ctx, number, body)
+	}
+	if err != nil {
+		logger.Warn("Failed to comment on the pull request", zap.Error(err))
+		report.reportingFailed("pr_comment", err)
+	}
+}
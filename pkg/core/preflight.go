@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// integrationPermission is a permission an enabled integration needs
+type integrationPermission struct {
+	integration string
+	permission  string
+}
+
+// requiredPermissions lists the provider permissions of the enabled
+// integrations, in the provider's terms
+func requiredPermissions(config *Configuration, ci string) []integrationPermission {
+	var required []integrationPermission
+	switch ci {
+	case "github":
+		if config.ApplyLabels {
+			required = append(required, integrationPermission{"labels", "pull-requests: write"})
+		}
+		if len(config.Reviewers) > 0 {
+			required = append(required, integrationPermission{"reviewers", "pull-requests: write"})
+		}
+		if config.CheckRun {
+			required = append(required, integrationPermission{"check run", "checks: write"})
+		}
+		if config.PRComment {
+			required = append(required, integrationPermission{"pr comment", "pull-requests: write"})
+		}
+		if config.DeploymentGate {
+			required = append(required, integrationPermission{"deployment gate", "deployments: write"})
+		}
+	case "gitlab":
+		if config.ApplyLabels {
+			required = append(required, integrationPermission{"labels", "api"})
+		}
+		if len(config.Reviewers) > 0 {
+			required = append(required, integrationPermission{"reviewers", "api"})
+		}
+		if config.MRDiscussions {
+			required = append(required, integrationPermission{"mr discussions", "api"})
+		}
+		if config.StatusCheck {
+			required = append(required, integrationPermission{"status check", "api"})
+		}
+	}
+	return required
+}
+
+// preflightIntegrations checks the provider token has the permissions the
+// enabled integrations need before the analysis, and disables the ones it
+// lacks permissions for with a message naming the permission, rather than
+// having them fail with a bare 403 at the end of the run. Permissions that
+// can't be looked up are left to the requests themselves.
+func preflightIntegrations(ctx context.Context, config *Configuration, ci string, logger *zap.Logger) {
+	required := requiredPermissions(config, ci)
+	if len(required) == 0 {
+		return
+	}
+	permissions := make([]string, 0, len(required))
+	for _, r := range required {
+		permissions = append(permissions, r.permission)
+	}
+
+	var missing []integrations.MissingPermission
+	var err error
+	switch {
+	case ci == "github" && config.GitHubToken != "":
+		missing, err = integrations.NewGitHubClient(config.GitHubToken, logger).MissingPermissions(ctx, permissions)
+	case ci == "gitlab" && config.GitLabToken != "":
+		missing, err = integrations.NewGitLabClient(config.GitLabToken, logger).MissingPermissions(ctx, permissions)
+	default:
+		return
+	}
+	if err != nil {
+		logger.Warn("Failed to check token permissions", zap.Error(err))
+		return
+	}
+
+	for _, m := range missing {
+		for _, r := range required {
+			if r.permission != m.Permission {
+				continue
+			}
+			logger.Warn("Token is missing `"+m.Permission+"`, disabling "+r.integration,
+				zap.String("integration", r.integration), zap.String("reason", m.Reason))
+			switch r.integration {
+			case "labels":
+				config.ApplyLabels = false
+			case "reviewers":
+				config.Reviewers = nil
+			case "check run":
+				config.CheckRun = false
+			case "pr comment":
+				config.PRComment = false
+			case "deployment gate":
+				config.DeploymentGate = false
+			case "mr discussions":
+				config.MRDiscussions = false
+			case "status check":
+				config.StatusCheck = false
+			}
+		}
+	}
+}
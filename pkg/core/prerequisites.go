@@ -0,0 +1,88 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// Prerequisites are what a spec must declare before the governance service
+// analyzes it, checked locally
+type Prerequisites struct {
+	// Required are dotted paths the spec must have, e.g. info.contact
+	Required []string `yaml:"required"`
+	// SecurityScheme requires at least one security scheme
+	SecurityScheme bool `yaml:"security_scheme"`
+	// Severity of the findings for missing prerequisites, error by default
+	Severity string `yaml:"severity"`
+}
+
+// securitySchemePrerequisite names the security scheme prerequisite in findings
+const securitySchemePrerequisite = "security-scheme"
+
+// level returns the severity level of the prerequisite findings
+func (p *Prerequisites) level() int {
+	if level, ok := severityLevels[p.Severity]; ok {
+		return level
+	}
+	return severityLevels[severityError]
+}
+
+// check returns a finding result for each prerequisite the document is
+// missing, located at the deepest part of the path it has
+func (p *Prerequisites) check(doc specDocument, content string) []integrations.LintResult {
+	if p == nil {
+		return nil
+	}
+	root, _ := parseSpecNode(content)
+	result := func(name string, path []string, message string) integrations.LintResult {
+		line := 1
+		if root != nil {
+			if location, ok := locatePath(root, path); ok {
+				line = location.StartLine
+			}
+		}
+		rule := "prerequisite-" + strings.ReplaceAll(name, ".", "-")
+		return integrations.LintResult{
+			Code:     rule,
+			Path:     path,
+			Message:  message,
+			Severity: p.level(),
+			Range:    integrations.LintRange{Start: integrations.LintLocation{Line: line}, End: integrations.LintLocation{Line: line}},
+			Rule:     integrations.RuleReference{Name: rule},
+		}
+	}
+
+	var results []integrations.LintResult
+	for _, required := range p.Required {
+		path := strings.Split(required, ".")
+		if !hasPath(doc, path) {
+			results = append(results, result(required, path, fmt.Sprintf("Spec must declare %s before governance analysis", required)))
+		}
+	}
+	if p.SecurityScheme && len(doc.object("components", "securitySchemes")) == 0 && len(doc.object("securityDefinitions")) == 0 {
+		path := []string{"components", "securitySchemes"}
+		if doc["swagger"] != nil {
+			path = []string{"securityDefinitions"}
+		}
+		results = append(results, result(securitySchemePrerequisite, path, "Spec must declare at least one security scheme before governance analysis"))
+	}
+	return results
+}
+
+// hasPath reports whether the document has a value other than null or an
+// empty string at a path of object keys
+func hasPath(doc specDocument, path []string) bool {
+	var current interface{} = map[string]interface{}(doc)
+	for _, key := range path {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if current, ok = object[key]; !ok {
+			return false
+		}
+	}
+	return current != nil && current != ""
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// prettyIndent is the indentation of the formatted view of minified JSON specs
+const prettyIndent = "  "
+
+// jsonView is a pretty-printed view of a minified JSON spec, mapping positions
+// in the original content to the formatted lines, so findings reported as
+// "line 1, char 51234" can be shown where a reader can find them
+type jsonView struct {
+	lines      []string
+	original   []int // Byte offset of each line of the original content
+	formatted  []int // Byte offset of each formatted line
+	offsets    []int // Formatted byte offset of each original byte offset
+	source     string
+	prettified string
+}
+
+// minifiedJSON returns the formatted view of content if it's JSON with lines
+// wider than width, or nil. A width of 0 disables the view.
+func minifiedJSON(content string, width int) *jsonView {
+	if width <= 0 || !json.Valid([]byte(content)) {
+		return nil
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if utf8.RuneCountInString(line) > width {
+			return newJSONView(content)
+		}
+	}
+	return nil
+}
+
+// newJSONView formats JSON content like json.Indent while recording where
+// each byte of the original ends up
+func newJSONView(content string) *jsonView {
+	var out strings.Builder
+	offsets := make([]int, len(content)+1)
+	depth := 0
+	inString, escaped := false, false
+	var last byte // Last structural character written
+
+	newline := func() {
+		out.WriteByte('\n')
+		out.WriteString(strings.Repeat(prettyIndent, depth))
+	}
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		offsets[i] = out.Len()
+		if inString {
+			out.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '"':
+			inString = true
+			out.WriteByte(c)
+		case '{', '[':
+			out.WriteByte(c)
+			depth++
+			// Empty objects and arrays stay on one line
+			if next := nextSignificant(content, i+1); next != '}' && next != ']' {
+				newline()
+			}
+		case '}', ']':
+			depth--
+			if last != '{' && last != '[' {
+				newline()
+			}
+			offsets[i] = out.Len()
+			out.WriteByte(c)
+		case ',':
+			out.WriteByte(c)
+			newline()
+		case ':':
+			out.WriteString(": ")
+		default:
+			out.WriteByte(c)
+		}
+		last = c
+	}
+	offsets[len(content)] = out.Len()
+
+	prettified := out.String()
+	return &jsonView{
+		lines:      strings.Split(prettified, "\n"),
+		original:   lineOffsets(content),
+		formatted:  lineOffsets(prettified),
+		offsets:    offsets,
+		source:     content,
+		prettified: prettified,
+	}
+}
+
+// nextSignificant returns the next non-whitespace byte from i, or 0
+func nextSignificant(content string, i int) byte {
+	for ; i < len(content); i++ {
+		if c := content[i]; c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return c
+		}
+	}
+	return 0
+}
+
+// lineOffsets returns the byte offset each line of content starts at
+func lineOffsets(content string) []int {
+	offsets := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// mapRange translates a range in the original content to the formatted view
+func (v *jsonView) mapRange(r integrations.LintRange) integrations.LintRange {
+	return integrations.LintRange{Start: v.mapLocation(r.Start), End: v.mapLocation(r.End)}
+}
+
+// mapLocation translates a 1-based line and character column of the original
+// content to the formatted view
+func (v *jsonView) mapLocation(location integrations.LintLocation) integrations.LintLocation {
+	line := min(max(location.Line, 1), len(v.original))
+	offset := runeOffset(v.source, v.original[line-1], location.Character)
+	formatted := v.offsets[offset]
+
+	// Find the formatted line holding the offset
+	formattedLine := 1
+	for formattedLine < len(v.formatted) && v.formatted[formattedLine] <= formatted {
+		formattedLine++
+	}
+	start := v.formatted[formattedLine-1]
+	return integrations.LintLocation{
+		Line:      formattedLine,
+		Character: utf8.RuneCountInString(v.prettified[start:formatted]),
+	}
+}
+
+// runeOffset returns the byte offset of the character column from a line start,
+// stopping at the end of the line
+func runeOffset(content string, lineStart, column int) int {
+	offset := lineStart
+	for n := 0; n < column && offset < len(content) && content[offset] != '\n'; n++ {
+		_, size := utf8.DecodeRuneInString(content[offset:])
+		offset += size
+	}
+	return offset
+}
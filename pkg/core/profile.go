@@ -0,0 +1,101 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile holds the ruleset and thresholds for an environment such as internal,
+// partner or public
+type Profile struct {
+	RuleID      string   `yaml:"rule_id"`
+	MaxErrors   *int     `yaml:"max_errors"`
+	MaxWarnings *int     `yaml:"max_warnings"`
+	Branches    []string `yaml:"branches"`
+}
+
+// selectProfile picks the profile by name, or else by the first profile whose
+// branch patterns match the branch. Profiles are tried in name order so the
+// selection is deterministic.
+func selectProfile(name string, profiles map[string]Profile, branch string) (string, *Profile, error) {
+	if name != "" {
+		profile, ok := profiles[name]
+		if !ok {
+			known := make([]string, 0, len(profiles))
+			for profileName := range profiles {
+				known = append(known, profileName)
+			}
+			sort.Strings(known)
+			return "", nil, fmt.Errorf("profile %s is not defined, known profiles: %s", name, strings.Join(known, ", "))
+		}
+		return name, &profile, nil
+	}
+
+	if branch == "" {
+		return "", nil, nil
+	}
+	names := make([]string, 0, len(profiles))
+	for profileName := range profiles {
+		names = append(names, profileName)
+	}
+	sort.Strings(names)
+	for _, profileName := range names {
+		profile := profiles[profileName]
+		for _, pattern := range profile.Branches {
+			if globMatch(pattern, branch) {
+				return profileName, &profile, nil
+			}
+		}
+	}
+	return "", nil, nil
+}
+
+// applyProfile fills in the settings that weren't configured explicitly from the profile
+func (c *Configuration) applyProfile(name string, profile *Profile) {
+	source := "profile " + name
+	if c.unsetOrFromFile("rule_id", c.RuleID) && profile.RuleID != "" {
+		c.RuleID = profile.RuleID
+		c.override("rule_id", source)
+	}
+	if (c.MaxErrors == nil || c.fileDefaults["max_errors"]) && profile.MaxErrors != nil {
+		c.MaxErrors = profile.MaxErrors
+		c.override("max_errors", source)
+	}
+	if (c.MaxWarnings == nil || c.fileDefaults["max_warnings"]) && profile.MaxWarnings != nil {
+		c.MaxWarnings = profile.MaxWarnings
+		c.override("max_warnings", source)
+	}
+}
+
+// checkThresholds returns an error when the counts exceed the configured
+// thresholds. Errors default to a threshold of zero, warnings are unlimited.
+func checkThresholds(config *Configuration, errorCount, warningCount int) error {
+	switch exceededThreshold(config, errorCount, warningCount) {
+	case ReasonErrors:
+		return fmt.Errorf("governance analysis failed with %d errors and %d warnings (max errors: %d)", errorCount, warningCount, config.maxErrors())
+	case ReasonWarnings:
+		return fmt.Errorf("governance analysis failed with %d errors and %d warnings (max warnings: %d)", errorCount, warningCount, *config.MaxWarnings)
+	}
+	return nil
+}
+
+// exceededThreshold returns which threshold the counts exceed, ReasonErrors or
+// ReasonWarnings, or ReasonNone
+func exceededThreshold(config *Configuration, errorCount, warningCount int) string {
+	if errorCount > config.maxErrors() {
+		return ReasonErrors
+	}
+	if config.MaxWarnings != nil && warningCount > *config.MaxWarnings {
+		return ReasonWarnings
+	}
+	return ReasonNone
+}
+
+// maxErrors returns the error threshold, zero unless configured
+func (c *Configuration) maxErrors() int {
+	if c.MaxErrors != nil {
+		return *c.MaxErrors
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// resolveRegionEndpoint resolves the governance service URL for a named region.
+// When a service URL is configured as well it must point at the region's host,
+// so a stray URL can't route specs outside the expected data residency region.
+func resolveRegionEndpoint(region, service string, regions map[string]string) (string, error) {
+	endpoint, ok := regions[region]
+	if !ok {
+		known := make([]string, 0, len(regions))
+		for name := range regions {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+		if len(known) == 0 {
+			return "", fmt.Errorf("region %s is not defined, add it to the regions section of the config file", region)
+		}
+		return "", fmt.Errorf("region %s is not defined, known regions: %s", region, strings.Join(known, ", "))
+	}
+
+	if service == "" {
+		return endpoint, nil
+	}
+
+	serviceHost, err := urlHost(service)
+	if err != nil {
+		return "", fmt.Errorf("invalid governance_service: %w", err)
+	}
+	regionHost, err := urlHost(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint for region %s: %w", region, err)
+	}
+	if !strings.EqualFold(serviceHost, regionHost) {
+		return "", fmt.Errorf("governance_service host %s does not match region %s (%s)", serviceHost, region, regionHost)
+	}
+	return service, nil
+}
+
+// urlHost returns the host of an absolute URL
+func urlHost(raw string) (string, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("%s is not an absolute URL", raw)
+	}
+	return parsed.Host, nil
+}
@@ -0,0 +1,224 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Output formats of the run's reports
+const (
+	formatConsole     = "console"
+	formatSARIF       = "sarif"
+	formatCodeQuality = "codequality"
+	formatMarkdown    = "markdown"
+	formatJUnit       = "junit"
+	formatJSON        = "json"
+	formatAdmission   = "admission"
+)
+
+// outputFormats are the known output formats, in the order of the registry
+var outputFormats = []string{formatConsole, formatSARIF, formatCodeQuality, formatMarkdown, formatJUnit, formatJSON, formatAdmission}
+
+// defaultResultsFile is where the json format writes the results file by default
+const defaultResultsFile = "governance-results.json"
+
+// reporter writes the report of a run in one output format
+type reporter interface {
+	// label names the report in the log
+	label() string
+	// output is the output variable set to the report file, if any
+	output() string
+	// defaultPath is where the report is written unless the output format
+	// names a path, empty for reports that aren't written to a file
+	defaultPath(config *Configuration) string
+	// file is the file a report written to path starts at, e.g. the index
+	// of a report directory
+	file(path string) string
+	// write writes the report to path
+	write(config *Configuration, report *analysisReport, result *RunResult, path string) error
+}
+
+// reporters is the registry of the output formats
+var reporters = map[string]reporter{
+	formatConsole:     consoleReporter{},
+	formatSARIF:       sarifReporter{},
+	formatCodeQuality: codeQualityReporter{},
+	formatMarkdown:    markdownReporter{},
+	formatJUnit:       junitReporter{},
+	formatJSON:        jsonReporter{},
+	formatAdmission:   admissionReporter{},
+}
+
+// selectedReporter is a reporter of output_format with the path it writes to
+type selectedReporter struct {
+	reporter
+	Format string
+	Path   string
+}
+
+// parseOutputFormat splits an output_format entry such as sarif:out/gov.sarif
+// into the format and the path, empty for the default path
+func parseOutputFormat(entry string) (string, string, error) {
+	format, path, hasPath := strings.Cut(entry, ":")
+	switch {
+	case reporters[format] == nil:
+		return "", "", fmt.Errorf("output_format: unknown format %s, must be one of: %s", format, strings.Join(outputFormats, ", "))
+	case hasPath && path == "":
+		return "", "", fmt.Errorf("output_format: %s has no path after the colon", format)
+	case hasPath && format == formatConsole:
+		return "", "", fmt.Errorf("output_format: the console report can't be written to a file")
+	}
+	return format, path, nil
+}
+
+// selectReporters returns the reporters of the output formats in the order
+// they're listed. The console report is always printed, last unless listed.
+func selectReporters(config *Configuration) []selectedReporter {
+	var selected []selectedReporter
+	console := false
+	for _, entry := range config.OutputFormats {
+		format, path, err := parseOutputFormat(entry)
+		if err != nil {
+			continue // Rejected by Validate
+		}
+		r := reporters[format]
+		if path == "" {
+			path = r.defaultPath(config)
+		}
+		console = console || format == formatConsole
+		selected = append(selected, selectedReporter{reporter: r, Format: format, Path: path})
+	}
+	if !console {
+		selected = append(selected, selectedReporter{reporter: reporters[formatConsole], Format: formatConsole})
+	}
+	return selected
+}
+
+// reportFiles returns the files the selected reporters write, for linking the
+// report files before they're written
+func reportFiles(config *Configuration) []string {
+	var files []string
+	for _, r := range selectReporters(config) {
+		if r.Path != "" {
+			files = append(files, r.file(r.Path))
+		}
+	}
+	return files
+}
+
+// writeReports writes the report in each output format, creating the
+// directories of the report files, and sets the output variables of the files
+func writeReports(config *Configuration, report *analysisReport, result *RunResult, logger *zap.Logger) error {
+	for _, r := range selectReporters(config) {
+		if r.Path != "" {
+			if err := os.MkdirAll(filepath.Dir(r.Path), 0755); err != nil {
+				return fmt.Errorf("failed to create directory of %s report %s: %w", r.label(), r.Path, err)
+			}
+		}
+		if err := r.write(config, report, result, r.Path); err != nil {
+			return err
+		}
+		if r.Path == "" {
+			continue
+		}
+		file := r.file(r.Path)
+		if r.output() != "" {
+			setOutput(config, r.output(), file)
+		}
+		logger.Info(fmt.Sprintf("Wrote %s report", r.label()), zap.String("path", file))
+	}
+	return nil
+}
+
+// consoleReporter prints the report to the job log, or the console writer of
+// the run
+type consoleReporter struct{}
+
+func (consoleReporter) label() string                            { return "console" }
+func (consoleReporter) output() string                           { return "" }
+func (consoleReporter) defaultPath(config *Configuration) string { return "" }
+func (consoleReporter) file(path string) string                  { return path }
+
+func (consoleReporter) write(config *Configuration, report *analysisReport, result *RunResult, path string) error {
+	printReport(config.Console, config, report)
+	return nil
+}
+
+// sarifReporter writes the SARIF report for code scanning
+type sarifReporter struct{}
+
+func (sarifReporter) label() string                            { return "SARIF" }
+func (sarifReporter) output() string                           { return "sarif_file" }
+func (sarifReporter) defaultPath(config *Configuration) string { return config.SarifFile }
+func (sarifReporter) file(path string) string                  { return path }
+
+func (sarifReporter) write(config *Configuration, report *analysisReport, result *RunResult, path string) error {
+	return writeSARIF(config, report, path)
+}
+
+// codeQualityReporter writes the GitLab Code Quality report
+type codeQualityReporter struct{}
+
+func (codeQualityReporter) label() string                            { return "Code Quality" }
+func (codeQualityReporter) output() string                           { return "code_quality_file" }
+func (codeQualityReporter) defaultPath(config *Configuration) string { return config.CodeQualityFile }
+func (codeQualityReporter) file(path string) string                  { return path }
+
+func (codeQualityReporter) write(config *Configuration, report *analysisReport, result *RunResult, path string) error {
+	return writeCodeQuality(report, path)
+}
+
+// markdownReporter writes the paged Markdown report to a directory
+type markdownReporter struct{}
+
+func (markdownReporter) label() string                            { return "Markdown" }
+func (markdownReporter) output() string                           { return "markdown_report" }
+func (markdownReporter) defaultPath(config *Configuration) string { return config.ReportDir }
+func (markdownReporter) file(path string) string                  { return filepath.Join(path, reportIndex) }
+
+func (markdownReporter) write(config *Configuration, report *analysisReport, result *RunResult, path string) error {
+	return writeMarkdownReport(config, report, path)
+}
+
+// junitReporter writes the JUnit report for test report views
+type junitReporter struct{}
+
+func (junitReporter) label() string                            { return "JUnit" }
+func (junitReporter) output() string                           { return "junit_file" }
+func (junitReporter) defaultPath(config *Configuration) string { return config.JUnitFile }
+func (junitReporter) file(path string) string                  { return path }
+
+func (junitReporter) write(config *Configuration, report *analysisReport, result *RunResult, path string) error {
+	return writeJUnit(config, report, path)
+}
+
+// jsonReporter writes the results file, as --output-file does. It's written
+// with the verdict, before the run ends, so it has no error.
+type jsonReporter struct{}
+
+func (jsonReporter) label() string                            { return "JSON" }
+func (jsonReporter) output() string                           { return "results_file" }
+func (jsonReporter) defaultPath(config *Configuration) string { return defaultResultsFile }
+func (jsonReporter) file(path string) string                  { return path }
+
+func (jsonReporter) write(config *Configuration, report *analysisReport, result *RunResult, path string) error {
+	finished := *result
+	finished.finish()
+	return WriteResultsFile(path, &finished, nil)
+}
+
+// admissionReporter writes the verdict object for admission policies
+type admissionReporter struct{}
+
+func (admissionReporter) label() string                            { return "admission verdict" }
+func (admissionReporter) output() string                           { return "admission_file" }
+func (admissionReporter) defaultPath(config *Configuration) string { return defaultAdmissionFile }
+func (admissionReporter) file(path string) string                  { return path }
+
+func (admissionReporter) write(config *Configuration, report *analysisReport, result *RunResult, path string) error {
+	return writeAdmissionVerdict(config, report, result, path)
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// Run verdicts
+const (
+	VerdictPass  = "pass"  // Thresholds were met
+	VerdictWarn  = "warn"  // Thresholds were exceeded in advisory mode
+	VerdictFail  = "fail"  // Thresholds were exceeded
+	VerdictError = "error" // The run failed before reaching a verdict
+)
+
+// Reasons for a run's verdict
+const (
+	ReasonNone     = "none"     // No gate failed
+	ReasonErrors   = "errors"   // The errors exceeded max_errors
+	ReasonWarnings = "warnings" // The warnings exceeded max_warnings
+	ReasonTrend    = "trend"    // The trend gate failed
+	ReasonError    = "error"    // The run failed, e.g. the service was unreachable
+)
+
+// RunResult is the outcome of a governance run
+type RunResult struct {
+	Verdict  string   `json:"verdict"`
+	Reason   string   `json:"reason,omitempty"`
+	Mode     string   `json:"mode,omitempty"`
+	Profile  string   `json:"profile,omitempty"`
+	Files    []string `json:"files,omitempty"`
+	Errors   int      `json:"errors"`
+	Warnings int      `json:"warnings"`
+	Total    int      `json:"total"`
+	Excluded int      `json:"excluded"`
+	// RulesPassed and RulesFailed count the rules of the ruleset with report_passed_rules
+	RulesPassed *int `json:"rules_passed,omitempty"`
+	RulesFailed *int `json:"rules_failed,omitempty"`
+	// FileResults are the per-file outcomes of multi-spec runs
+	FileResults []FileResult `json:"file_results,omitempty"`
+	// RetriesUsed is how often governance service requests were retried after
+	// transient failures, RetryWait the time spent backing off
+	RetriesUsed int           `json:"retries_used"`
+	RetryWait   time.Duration `json:"-"`
+	// Performance of the analysis and of the governance service
+	AnalysisDurationMS int64 `json:"analysis_duration_ms"`
+	UploadSizeBytes    int64 `json:"upload_size_bytes"`
+	ServiceLatencyMS   int64 `json:"service_latency_ms"`
+	// EvaluationTimeMS is the evaluation time the service reported, if any
+	EvaluationTimeMS int64 `json:"evaluation_time_ms,omitempty"`
+	// StartedAt and FinishedAt are in the configured timezone, for correlating
+	// runs with deployment windows
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Artifacts are the files the run wrote, e.g. the GitLab dotenv report
+	Artifacts []string `json:"artifacts,omitempty"`
+	// ReportURL links to the full report once uploaded, or to the CI run
+	ReportURL  string        `json:"report_url,omitempty"`
+	Duration   time.Duration `json:"-"`
+	DurationMS int64         `json:"duration_ms"`
+
+	// The CI context and report of the run, for the results file
+	ci        string
+	ciContext map[string]string
+	report    *analysisReport
+}
+
+// record fills in the counts and verdict of an analyzed report
+func (r *RunResult) record(config *Configuration, report *analysisReport) {
+	r.Mode = config.Mode
+	r.Profile = config.Profile
+	r.Files = report.Files
+	r.Errors, r.Warnings = countSeverities(report.Findings)
+	r.Total = len(report.Findings)
+	r.Excluded = len(report.Excluded)
+	if len(report.Files) > 1 {
+		r.FileResults = fileMatrix(config, report)
+	}
+	if report.Diagnostics != nil {
+		r.EvaluationTimeMS = report.Diagnostics.EvaluationTime.Milliseconds()
+	}
+	if rules := report.Rules; rules != nil {
+		passed, failed := len(rules.Passed), len(rules.Failed)
+		r.RulesPassed, r.RulesFailed = &passed, &failed
+	}
+
+	r.FinishedAt = time.Now().In(r.StartedAt.Location())
+	r.Verdict = VerdictPass
+	if r.Reason = exceededThreshold(config, r.Errors, r.Warnings); r.Reason != ReasonNone {
+		r.Verdict = VerdictFail
+		if config.Mode == ModeAdvisory {
+			r.Verdict = VerdictWarn
+		}
+	}
+
+	if os.Getenv("GITLAB_CI") == "true" {
+		r.Artifacts = append(r.Artifacts, gitLabOutputFile())
+	}
+}
+
+// description describes the verdict in a deployment status or a hook event,
+// e.g. "Governance fail: 3 errors, 7 warnings"
+func (r *RunResult) description() string {
+	return fmt.Sprintf("Governance %s: %d errors, %d warnings", r.Verdict, r.Errors, r.Warnings)
+}
+
+// proceeds reports whether the verdict lets the change proceed where it's
+// gated on the run. Advisory runs warn but don't block.
+func (r *RunResult) proceeds() bool {
+	return r.Verdict == VerdictPass || r.Verdict == VerdictWarn
+}
+
+// recordServiceStats records the retries, upload size and latency of the
+// governance service requests and sets them as outputs
+func (r *RunResult) recordServiceStats(config *Configuration, client *integrations.GovernanceClient, logger *zap.Logger) {
+	if client != nil {
+		retries, requests := client.RetryStats(), client.RequestStats()
+		r.RetriesUsed, r.RetryWait = retries.Retries, retries.Waited
+		r.UploadSizeBytes, r.ServiceLatencyMS = requests.UploadBytes, requests.Latency.Milliseconds()
+	}
+	if r.RetriesUsed > 0 {
+		logger.Warn("Governance service requests needed retries",
+			zap.Int("retries_used", r.RetriesUsed), zap.Duration("retry_wait", r.RetryWait))
+	}
+	logger.Info("Analysis performance", zap.Int64("analysis_duration_ms", r.AnalysisDurationMS),
+		zap.Int64("upload_size_bytes", r.UploadSizeBytes), zap.Int64("service_latency_ms", r.ServiceLatencyMS))
+
+	setOutput(config, "retries_used", strconv.Itoa(r.RetriesUsed))
+	setOutput(config, "analysis_duration_ms", strconv.FormatInt(r.AnalysisDurationMS, 10))
+	setOutput(config, "upload_size_bytes", strconv.FormatInt(r.UploadSizeBytes, 10))
+	setOutput(config, "service_latency_ms", strconv.FormatInt(r.ServiceLatencyMS, 10))
+}
+
+// finish records when the run ended, unless a verdict was reached, and how long it took
+func (r *RunResult) finish() {
+	if r.FinishedAt.IsZero() {
+		r.FinishedAt = time.Now().In(r.StartedAt.Location())
+	}
+	r.Duration = time.Since(r.StartedAt)
+	r.DurationMS = r.Duration.Milliseconds()
+}
+
+// ResultLine is the structured line the run ends with, for wrapper scripts and
+// log-based alerting to grep, e.g.
+// "RESULT: fail reason=errors errors=3 warnings=7 score=72". err is the error
+// the run ended with, if any. Runs that didn't reach a verdict have no score.
+func (r *RunResult) ResultLine(err error) string {
+	reason := r.Reason
+	switch {
+	case err != nil && (reason == "" || reason == ReasonNone):
+		reason = ReasonError
+	case reason == "":
+		reason = ReasonNone
+	}
+	line := fmt.Sprintf("RESULT: %s reason=%s errors=%d warnings=%d", r.Verdict, reason, r.Errors, r.Warnings)
+	if r.Verdict != VerdictError {
+		line += fmt.Sprintf(" score=%d", summarize(r).Score)
+	}
+	return line
+}
+
+// formatTimestamp formats a report timestamp with its timezone
+func formatTimestamp(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05 MST")
+}
+
+// runStatus is the JSON schema of the status file
+type runStatus struct {
+	*RunResult
+	Error string `json:"error,omitempty"`
+}
+
+// WriteStatusFile writes the verdict and counts of a run as JSON, along with the
+// error that failed the run, for pipelines that gate on the result themselves
+func WriteStatusFile(path string, result *RunResult, runErr error) error {
+	status := runStatus{RunResult: result}
+	if runErr != nil {
+		status.Error = runErr.Error()
+	}
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal status: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write status file %s: %w", path, err)
+	}
+	return nil
+}
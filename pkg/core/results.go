@@ -0,0 +1,203 @@
+package core
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// resultsSchemaVersion is the version of the results file schema. It only
+// changes when fields are removed or change meaning; new fields keep it.
+const resultsSchemaVersion = 1
+
+// resultsSchemaURL is where the JSON Schema of the results file is published
+const resultsSchemaURL = "https://raw.githubusercontent.com/TykTechnologies/governance-action/main/pkg/core/results.schema.json"
+
+// resultsSchemaJSON is the JSON Schema of the results file
+//
+//go:embed results.schema.json
+var resultsSchemaJSON []byte
+
+// ResultsSchema returns the JSON Schema of the results file
+func ResultsSchema() []byte {
+	return resultsSchemaJSON
+}
+
+// runResultsFile is the JSON schema of the results file
+type runResultsFile struct {
+	Schema        string         `json:"$schema"`
+	SchemaVersion int            `json:"schema_version"`
+	Version       string         `json:"version"`
+	Verdict       string         `json:"verdict"`
+	Reason        string         `json:"reason"`
+	Error         string         `json:"error,omitempty"`
+	Mode          string         `json:"mode,omitempty"`
+	Profile       string         `json:"profile,omitempty"`
+	Counts        resultsCounts  `json:"counts"`
+	CI            resultsCI      `json:"ci"`
+	Timing        resultsTiming  `json:"timing"`
+	Files         []string       `json:"files"`
+	Findings      []resultsEntry `json:"findings"`
+	Excluded      []resultsEntry `json:"excluded"`
+	// Approvals are the approvals annotated on the operations
+	Approvals []operationApproval `json:"approvals"`
+}
+
+// resultsCounts are the findings by severity. Runs that didn't reach a
+// verdict have no score.
+type resultsCounts struct {
+	Errors   int  `json:"errors"`
+	Warnings int  `json:"warnings"`
+	Info     int  `json:"info"`
+	Total    int  `json:"total"`
+	Excluded int  `json:"excluded"`
+	Score    *int `json:"score,omitempty"`
+}
+
+// resultsCI is the CI platform and context the run was in
+type resultsCI struct {
+	Platform string            `json:"platform"`
+	Context  map[string]string `json:"context"`
+}
+
+// resultsTiming is when the run started and ended and where the time went
+type resultsTiming struct {
+	StartedAt          time.Time `json:"started_at"`
+	FinishedAt         time.Time `json:"finished_at"`
+	DurationMS         int64     `json:"duration_ms"`
+	AnalysisDurationMS int64     `json:"analysis_duration_ms"`
+	ServiceLatencyMS   int64     `json:"service_latency_ms"`
+	EvaluationTimeMS   int64     `json:"evaluation_time_ms,omitempty"`
+	RetriesUsed        int       `json:"retries_used"`
+}
+
+// resultsEntry is a finding of the results file
+type resultsEntry struct {
+	Fingerprint     string     `json:"fingerprint"`
+	Rule            string     `json:"rule"`
+	Severity        string     `json:"severity"`
+	Message         string     `json:"message"`
+	File            string     `json:"file"`
+	Spec            string     `json:"spec,omitempty"`
+	Document        int        `json:"document,omitempty"`
+	Line            int        `json:"line"`
+	EndLine         int        `json:"end_line"`
+	Path            []string   `json:"path"`
+	Tags            []string   `json:"tags,omitempty"`
+	Permalink       string     `json:"permalink,omitempty"`
+	FirstSeen       *time.Time `json:"first_seen,omitempty"`
+	ExclusionReason string     `json:"exclusion_reason,omitempty"`
+}
+
+// resultsEntries converts findings into results file entries
+func resultsEntries(findings []Finding) []resultsEntry {
+	entries := make([]resultsEntry, 0, len(findings))
+	for _, finding := range findings {
+		rule := finding.Rule.Name
+		if rule == "" {
+			rule = finding.Code
+		}
+		region := findingRegion(finding)
+		entry := resultsEntry{
+			Fingerprint:     finding.Fingerprint(),
+			Rule:            rule,
+			Severity:        severityName(finding.Severity),
+			Message:         finding.Message,
+			File:            repoPath(finding.File),
+			Document:        finding.Document,
+			Line:            region.StartLine,
+			EndLine:         max(region.EndLine, region.StartLine),
+			Path:            finding.Path,
+			Tags:            finding.Tags,
+			Permalink:       finding.Permalink,
+			ExclusionReason: finding.ExclusionReason,
+		}
+		if entry.Path == nil {
+			entry.Path = []string{}
+		}
+		if finding.Spec != "" {
+			entry.Spec = repoPath(finding.Spec)
+		}
+		if !finding.FirstSeen.IsZero() {
+			firstSeen := finding.FirstSeen
+			entry.FirstSeen = &firstSeen
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// WriteResultsFile writes the full result set of a run as JSON following the
+// versioned results schema: the verdict, counts, CI context, timing and every
+// finding, for downstream jobs and scripts. runErr is the error the run ended
+// with, if any. Runs that didn't reach a verdict have no findings.
+func WriteResultsFile(path string, result *RunResult, runErr error) error {
+	results := runResultsFile{
+		Schema:        resultsSchemaURL,
+		SchemaVersion: resultsSchemaVersion,
+		Version:       Version,
+		Verdict:       result.Verdict,
+		Reason:        result.Reason,
+		Mode:          result.Mode,
+		Profile:       result.Profile,
+		Counts: resultsCounts{
+			Errors:   result.Errors,
+			Warnings: result.Warnings,
+			Info:     result.Total - result.Errors - result.Warnings,
+			Total:    result.Total,
+			Excluded: result.Excluded,
+		},
+		CI: resultsCI{Platform: result.ci, Context: result.ciContext},
+		Timing: resultsTiming{
+			StartedAt:          result.StartedAt,
+			FinishedAt:         result.FinishedAt,
+			DurationMS:         result.DurationMS,
+			AnalysisDurationMS: result.AnalysisDurationMS,
+			ServiceLatencyMS:   result.ServiceLatencyMS,
+			EvaluationTimeMS:   result.EvaluationTimeMS,
+			RetriesUsed:        result.RetriesUsed,
+		},
+		Files:     make([]string, 0, len(result.Files)),
+		Findings:  []resultsEntry{},
+		Excluded:  []resultsEntry{},
+		Approvals: []operationApproval{},
+	}
+	if results.CI.Platform == "" {
+		results.CI.Platform = integrations.DetectCI()
+		results.CI.Context = integrations.GetContext(results.CI.Platform)
+	}
+	for _, file := range result.Files {
+		results.Files = append(results.Files, repoPath(file))
+	}
+	switch {
+	case runErr != nil && (results.Reason == "" || results.Reason == ReasonNone):
+		results.Reason = ReasonError
+	case results.Reason == "":
+		results.Reason = ReasonNone
+	}
+	if runErr != nil {
+		results.Error = runErr.Error()
+	}
+	if result.Verdict != VerdictError {
+		score := summarize(result).Score
+		results.Counts.Score = &score
+		if report := result.report; report != nil {
+			results.Findings = resultsEntries(report.Findings)
+			results.Excluded = resultsEntries(report.Excluded)
+			results.Approvals = append(results.Approvals, report.Approvals...)
+		}
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write results file %s: %w", path, err)
+	}
+	return nil
+}
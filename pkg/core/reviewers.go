@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// splitReviewers separates "@org/team" team handles from "@user" user handles
+func splitReviewers(reviewers []string) (users, teams []string) {
+	for _, reviewer := range reviewers {
+		reviewer = strings.TrimPrefix(strings.TrimSpace(reviewer), "@")
+		if reviewer == "" {
+			continue
+		}
+		if _, team, ok := strings.Cut(reviewer, "/"); ok {
+			teams = append(teams, team)
+		} else {
+			users = append(users, reviewer)
+		}
+	}
+	return users, teams
+}
+
+// requestReviewers asks the configured reviewers to review the pull or merge
+// request when error findings are present. Failures are logged but never fail the run.
+func requestReviewers(ctx context.Context, config *Configuration, ci string, ciContext map[string]string, report *analysisReport, logger *zap.Logger) {
+	if errorCount, _ := countSeverities(report.Findings); errorCount == 0 {
+		return
+	}
+	number, err := strconv.Atoi(ciContext["pull_request"])
+	if err != nil || !integrations.IsPullRequest(ciContext) {
+		logger.Info("Not running on a pull request, skipping reviewer assignment", zap.String("event_type", ciContext["event_type"]))
+		return
+	}
+
+	users, teams := splitReviewers(config.Reviewers)
+	logger.Info("Requesting governance review", zap.Strings("users", users), zap.Strings("teams", teams))
+
+	switch ci {
+	case "github":
+		if config.GitHubToken == "" {
+			logger.Warn("github_token is required to request reviewers")
+			return
+		}
+		client := integrations.NewGitHubClient(config.GitHubToken, logger)
+		if err := client.RequestReviewers(ctx, number, users, teams); err != nil {
+			logger.Warn("Failed to request reviewers", zap.Error(err))
+			report.reportingFailed("reviewers", err)
+		}
+	case "gitlab":
+		if config.GitLabToken == "" {
+			logger.Warn("gitlab_token is required to request reviewers")
+			return
+		}
+		if len(teams) > 0 {
+			logger.Warn("GitLab merge requests can't have group reviewers, ignoring teams", zap.Strings("teams", teams))
+		}
+		if len(users) == 0 {
+			return
+		}
+		client := integrations.NewGitLabClient(config.GitLabToken, logger)
+		if err := client.AddMergeRequestReviewers(ctx, number, users); err != nil {
+			logger.Warn("Failed to add merge request reviewers", zap.Error(err))
+			report.reportingFailed("reviewers", err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RuleLink links the rules matching a glob, e.g. internal-*, to their page in
+// an internal style guide. {rule} in the URL is replaced with the rule name.
+type RuleLink struct {
+	Rule string `yaml:"rule"`
+	URL  string `yaml:"url"`
+}
+
+// ruleLink returns the style guide URL of a rule from the first matching rule
+// link, or "" when none matches
+func (c *Configuration) ruleLink(name string) string {
+	for _, link := range c.RuleLinks {
+		if globMatch(link.Rule, name) {
+			return strings.ReplaceAll(link.URL, "{rule}", url.PathEscape(name))
+		}
+	}
+	return ""
+}
+
+// ruleMarkdown renders a rule name as Markdown code, linked to its style guide
+// page when there is one
+func (c *Configuration) ruleMarkdown(name string) string {
+	if link := c.ruleLink(name); link != "" {
+		return fmt.Sprintf("[`%s`](%s)", name, link)
+	}
+	return fmt.Sprintf("`%s`", name)
+}
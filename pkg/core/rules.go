@@ -0,0 +1,63 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// ruleSummary is which rules of the ruleset passed and which failed, so
+// reports show what was checked rather than only the failures
+type ruleSummary struct {
+	Passed []string
+	Failed []string
+}
+
+// summarizeRules splits the rules into passed and failed ones. A rule fails
+// when a counted finding violates it, rules whose findings were all excluded
+// pass. Failed rules the ruleset metadata doesn't list are still counted.
+func summarizeRules(findings []Finding, ruleset *integrations.Ruleset) *ruleSummary {
+	if ruleset == nil {
+		return nil
+	}
+	failed := map[string]bool{}
+	for _, finding := range findings {
+		failed[finding.Rule.Name] = true
+	}
+
+	summary := &ruleSummary{}
+	for _, rule := range ruleset.Rules {
+		if !failed[rule.Name] {
+			summary.Passed = append(summary.Passed, rule.Name)
+		}
+	}
+	for name := range failed {
+		summary.Failed = append(summary.Failed, name)
+	}
+	sort.Strings(summary.Passed)
+	sort.Strings(summary.Failed)
+	return summary
+}
+
+// setRuleOutputs sets the number of passed and failed rules as outputs
+func setRuleOutputs(config *Configuration, summary *ruleSummary) {
+	setOutput(config, "rules_passed", fmt.Sprintf("%d", len(summary.Passed)))
+	setOutput(config, "rules_failed", fmt.Sprintf("%d", len(summary.Failed)))
+}
+
+// printRuleSummary prints the rules section of the console report
+func printRuleSummary(w io.Writer, config *Configuration, summary *ruleSummary) {
+	if summary == nil {
+		return
+	}
+	printHeading(w, config.text("report.rules"))
+	fmt.Fprintln(w, "    "+config.text("report.rules_line", len(summary.Passed), len(summary.Failed)))
+	for _, name := range summary.Failed {
+		fmt.Fprintf(w, "    ❌ %s\n", name)
+	}
+	for _, name := range summary.Passed {
+		fmt.Fprintf(w, "    ✅ %s\n", name)
+	}
+}
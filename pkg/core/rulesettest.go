@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// RulesetTestOptions are the options of the ruleset test command
+type RulesetTestOptions struct {
+	// RequireAll fails the test when a rule of the ruleset fires on none of
+	// the specs, e.g. when sample specs are meant to exercise every rule
+	RequireAll bool
+}
+
+// rulesetTestRow is how often a rule fired on each of the sample specs
+type rulesetTestRow struct {
+	Rule   string
+	Counts []int
+}
+
+// fired returns whether the rule fired on any of the specs
+func (r rulesetTestRow) fired() bool {
+	for _, count := range r.Counts {
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RunRulesetTest evaluates a local ruleset against sample specs and reports
+// which rules fired on which spec, for developing rulesets before they are
+// published. The findings don't go through thresholds, so only failing
+// requests and, with RequireAll, rules that never fired fail the test.
+func RunRulesetTest(logger *zap.Logger, rulesetPath string, specs []string, options RulesetTestOptions) error {
+	config, err := getConfiguration()
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	if config.InlineRuleset, err = integrations.LoadInlineRuleset(rulesetPath); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	config.RulesetFile = rulesetPath
+	config.RulesetVersion = ""
+	config.APIPath = specs[0]
+	config.Manifest = ""
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	ctx := context.Background()
+	client, _, err := connectGovernance(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+
+	rows := map[string]*rulesetTestRow{}
+	for _, rule := range config.InlineRuleset.Metadata.Rules {
+		rows[rule.Name] = &rulesetTestRow{Rule: rule.Name, Counts: make([]int, len(specs))}
+	}
+	for i, spec := range specs {
+		findings, _, err := analyzeTarget(ctx, config, client, specTarget{Path: spec}, logger)
+		if err != nil {
+			return err
+		}
+		logger.Info("Evaluated ruleset", zap.String("spec", spec), zap.Int("finding_count", len(findings)))
+		for _, finding := range findings {
+			// Rules of extended rulesets aren't listed in the file
+			row := rows[finding.Code]
+			if row == nil {
+				row = &rulesetTestRow{Rule: finding.Code, Counts: make([]int, len(specs))}
+				rows[finding.Code] = row
+			}
+			row.Counts[i]++
+		}
+	}
+
+	sorted := make([]rulesetTestRow, 0, len(rows))
+	for _, row := range rows {
+		sorted = append(sorted, *row)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Rule < sorted[j].Rule })
+	printRulesetTest(config, config.InlineRuleset.Name, specs, sorted)
+
+	var unfired []string
+	for _, row := range sorted {
+		if !row.fired() {
+			unfired = append(unfired, row.Rule)
+		}
+	}
+	if options.RequireAll && len(unfired) > 0 {
+		return fmt.Errorf("rules fired on none of the specs: %s", strings.Join(unfired, ", "))
+	}
+	return nil
+}
+
+// printRulesetTest prints how often each rule fired on each spec
+func printRulesetTest(config *Configuration, name string, specs []string, rows []rulesetTestRow) {
+	width := len([]rune(config.text("report.rule")))
+	for _, row := range rows {
+		width = max(width, len([]rune(row.Rule)))
+	}
+	columns := make([]string, len(specs))
+	for i, spec := range specs {
+		columns[i] = repoPath(spec)
+	}
+
+	fmt.Printf("\n================ %s: %s ================\n", config.text("report.ruleset_test"), name)
+	fmt.Printf("    %-*s", width, config.text("report.rule"))
+	for _, column := range columns {
+		fmt.Printf("  %s", column)
+	}
+	fmt.Println()
+	fired := 0
+	for _, row := range rows {
+		fmt.Printf("    %-*s", width, row.Rule)
+		for i, count := range row.Counts {
+			fmt.Printf("  %*d", len([]rune(columns[i])), count)
+		}
+		if row.fired() {
+			fired++
+		} else {
+			fmt.Printf("  (%s)", config.text("report.not_fired"))
+		}
+		fmt.Println()
+	}
+	fmt.Println("    " + config.text("report.rules_fired", fired, len(rows)))
+	fmt.Println("===========================================================")
+}
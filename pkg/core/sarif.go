@@ -0,0 +1,206 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// defaultSarifFile is where the SARIF report is written by default
+const defaultSarifFile = "governance.sarif"
+
+// sarifSchema is the JSON schema of SARIF 2.1.0 reports
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifFingerprint names the finding fingerprint among the partial
+// fingerprints, so code scanning tracks findings across commits
+const sarifFingerprint = "governanceFingerprint/v1"
+
+// sarifLog is a SARIF 2.1.0 report
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     *sarifMessage      `json:"shortDescription,omitempty"`
+	Help                 *sarifMessage      `json:"help,omitempty"`
+	HelpURI              string             `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           *sarifProperties   `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifProperties struct {
+	Tags []string `json:"tags"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// sarifLevel maps a finding severity to a SARIF result level
+func sarifLevel(severity int) string {
+	switch severity {
+	case 0:
+		return "error"
+	case 1:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// findingRegion returns the region of a finding in its spec file. Ranges are
+// reported with 1-based lines and 0-based characters, SARIF columns are
+// 1-based. Findings without a range fall back to the lines resolved from their
+// path, or the start of the file.
+func findingRegion(finding Finding) sarifRegion {
+	r := finding.Range
+	if r.Start.Line >= 1 {
+		region := sarifRegion{StartLine: int(r.Start.Line), StartColumn: int(r.Start.Character) + 1}
+		if r.End.Line >= r.Start.Line {
+			region.EndLine, region.EndColumn = int(r.End.Line), int(r.End.Character)+1
+		}
+		return region
+	}
+	if finding.Location.StartLine > 0 {
+		return sarifRegion{StartLine: finding.Location.StartLine, EndLine: finding.Location.EndLine}
+	}
+	return sarifRegion{StartLine: 1}
+}
+
+// sarifReport converts the findings into a SARIF report for code scanning.
+// Excluded findings, e.g. exempted ones, are reported as suppressed.
+func sarifReport(config *Configuration, report *analysisReport) sarifLog {
+	driver := sarifDriver{
+		Name:           "governance-action",
+		Version:        Version,
+		InformationURI: "https://github.com/TykTechnologies/governance-action",
+		Rules:          []sarifRule{},
+	}
+	ruleIndex := map[string]int{}
+	results := []sarifResult{}
+
+	add := func(finding Finding, suppressed bool) {
+		name := finding.Rule.Name
+		if name == "" {
+			name = finding.Code
+		}
+		index, ok := ruleIndex[name]
+		if !ok {
+			rule := sarifRule{ID: name, HelpURI: config.ruleLink(name), DefaultConfiguration: sarifConfiguration{Level: sarifLevel(finding.Severity)}}
+			if metadata := report.Ruleset.Rule(name); metadata != nil {
+				if metadata.Description != "" {
+					rule.ShortDescription = &sarifMessage{Text: metadata.Description}
+				}
+				if metadata.Remediation != "" {
+					rule.Help = &sarifMessage{Text: metadata.Remediation}
+				}
+				if len(metadata.Frameworks) > 0 {
+					rule.Properties = &sarifProperties{Tags: metadata.Frameworks}
+				}
+			}
+			index = len(driver.Rules)
+			ruleIndex[name] = index
+			driver.Rules = append(driver.Rules, rule)
+		}
+
+		result := sarifResult{
+			RuleID:    name,
+			RuleIndex: index,
+			Level:     sarifLevel(finding.Severity),
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: repoPath(finding.File), URIBaseID: "%SRCROOT%"},
+				Region:           findingRegion(finding),
+			}}},
+			PartialFingerprints: map[string]string{sarifFingerprint: finding.Fingerprint()},
+		}
+		if suppressed {
+			result.Suppressions = []sarifSuppression{{Kind: "external", Justification: finding.ExclusionReason}}
+		}
+		results = append(results, result)
+	}
+	for _, finding := range report.Findings {
+		add(finding, false)
+	}
+	for _, finding := range report.Excluded {
+		add(finding, true)
+	}
+
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
+
+// writeSARIF writes the SARIF report of the run to a file
+func writeSARIF(config *Configuration, report *analysisReport, path string) error {
+	data, err := json.MarshalIndent(sarifReport(config, report), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write SARIF report %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// scanMetricsJob is the Pushgateway job the scan metrics are pushed under
+const scanMetricsJob = "governance_scan"
+
+// skippedScanDirs are never walked for specs
+var skippedScanDirs = []string{".git", "node_modules", "vendor"}
+
+// ScanOptions configure a catalog scan
+type ScanOptions struct {
+	Root       string // Directory walked for specs
+	Report     string // Path of the JSON catalog report
+	MetricsURL string // Prometheus Pushgateway the metrics are pushed to
+
+	// GitHubOrg and GitLabGroup scan the repositories of an organization or
+	// group through the provider's search API instead of the directory
+	GitHubOrg   string
+	GitLabGroup string
+}
+
+// specProvider discovers and fetches specs in the repositories of a provider
+type specProvider interface {
+	SearchSpecs(ctx context.Context, owner string) ([]integrations.RemoteFile, error)
+	FetchFile(ctx context.Context, file integrations.RemoteFile) (string, error)
+}
+
+// CatalogEntry is the outcome of a single spec in a catalog scan
+type CatalogEntry struct {
+	Repository string     `json:"repository,omitempty"`
+	File       string     `json:"file"`
+	Format     string     `json:"format,omitempty"`
+	Verdict    string     `json:"verdict"`
+	Errors     int        `json:"errors"`
+	Warnings   int        `json:"warnings"`
+	Info       int        `json:"info"`
+	Excluded   int        `json:"excluded"`
+	Score      int        `json:"score"`
+	Stats      *SpecStats `json:"stats,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// CatalogReport is the consolidated result of a catalog scan
+type CatalogReport struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	RuleID      string         `json:"rule_id"`
+	Owner       string         `json:"owner,omitempty"` // Organization or group scanned, if any
+	Specs       []CatalogEntry `json:"specs"`
+	Failing     int            `json:"failing"`
+	Errors      int            `json:"errors"`
+	Warnings    int            `json:"warnings"`
+}
+
+// RunScan analyzes every spec in a directory tree and writes a catalog report,
+// for scheduled pipelines rather than pull request gates. Specs that fail or
+// can't be analyzed are reported, but don't fail the scan.
+func RunScan(logger *zap.Logger, options ScanOptions) (*CatalogReport, error) {
+	config, err := getConfiguration()
+	if err != nil {
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+	config.APIPath = options.Root
+	config.Manifest = ""
+	if err := enforceOrgPolicy(context.Background(), config, logger); err != nil {
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	config.Mode = resolveMode(config.Mode, config.Policies, nil)
+
+	provider, owner, err := scanProvider(config, options, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	catalog := &CatalogReport{GeneratedAt: time.Now().In(config.Timezone), RuleID: config.RuleID, Owner: owner}
+	if provider != nil {
+		if err := scanRemote(ctx, config, provider, owner, catalog, logger); err != nil {
+			return nil, err
+		}
+	} else {
+		specs, err := discoverSpecs(options.Root, config.MaxSpecSize)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Discovered specs", zap.String("root", options.Root), zap.Int("spec_count", len(specs)))
+
+		client, _, err := connectGovernance(ctx, config, logger)
+		if err != nil {
+			return nil, err
+		}
+		repository := integrations.GetContext(integrations.DetectCI())["repository"]
+		for _, spec := range specs {
+			entry := scanSpec(ctx, config, client, spec, logger)
+			entry.Repository = repository
+			catalog.add(entry)
+		}
+	}
+
+	printCatalog(config, catalog)
+	if options.Report != "" {
+		if err := writeCatalog(options.Report, catalog); err != nil {
+			return catalog, err
+		}
+		logger.Info("Wrote catalog report", zap.String("path", options.Report))
+	}
+	setOutput(config, "spec_count", strconv.Itoa(len(catalog.Specs)))
+	setOutput(config, "failing_spec_count", strconv.Itoa(catalog.Failing))
+
+	if options.MetricsURL != "" {
+		if err := integrations.PushMetrics(ctx, options.MetricsURL, scanMetricsJob, catalogMetrics(catalog)); err != nil {
+			logger.Warn("Failed to push scan metrics", zap.Error(err))
+		} else {
+			logger.Info("Pushed scan metrics", zap.String("url", options.MetricsURL))
+		}
+	}
+	return catalog, nil
+}
+
+// scanProvider returns the provider and organization or group to scan, or nil
+// when the directory is scanned
+func scanProvider(config *Configuration, options ScanOptions, logger *zap.Logger) (specProvider, string, error) {
+	switch {
+	case options.GitHubOrg != "" && options.GitLabGroup != "":
+		return nil, "", fmt.Errorf("--github-org and --gitlab-group are mutually exclusive")
+	case options.GitHubOrg != "":
+		if config.GitHubToken == "" {
+			return nil, "", fmt.Errorf("github_token is required to scan a GitHub organization")
+		}
+		return integrations.NewGitHubClient(config.GitHubToken, logger), options.GitHubOrg, nil
+	case options.GitLabGroup != "":
+		if config.GitLabToken == "" {
+			return nil, "", fmt.Errorf("gitlab_token is required to scan a GitLab group")
+		}
+		return integrations.NewGitLabClient(config.GitLabToken, logger), options.GitLabGroup, nil
+	}
+	return nil, "", nil
+}
+
+// scanRemote analyzes the specs found in the repositories of an organization
+// or group. Each file is fetched into a temporary directory, so it's analyzed
+// exactly like a local spec. Files that can't be fetched are reported like
+// specs that can't be analyzed.
+func scanRemote(ctx context.Context, config *Configuration, provider specProvider, owner string, catalog *CatalogReport, logger *zap.Logger) error {
+	candidates, err := provider.SearchSpecs(ctx, owner)
+	if err != nil {
+		return err
+	}
+	logger.Info("Found candidate specs", zap.String("owner", owner), zap.Int("candidate_count", len(candidates)))
+
+	client, _, err := connectGovernance(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "governance-scan-")
+	if err != nil {
+		return fmt.Errorf("failed to create scan directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, file := range candidates {
+		entry := CatalogEntry{Repository: file.Repository, File: file.Path, Verdict: VerdictError}
+		content, err := provider.FetchFile(ctx, file)
+		if err != nil {
+			logger.Warn("Failed to fetch spec", zap.String("repository", file.Repository), zap.String("path", file.Path), zap.Error(err))
+			entry.Error = err.Error()
+			catalog.add(entry)
+			continue
+		}
+		// Search hits merely mention a keyword, only actual specs are analyzed
+		if specFormat(content) == "" {
+			continue
+		}
+
+		local := filepath.Join(dir, filepath.FromSlash(file.Repository), filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+			return fmt.Errorf("failed to create scan directory: %w", err)
+		}
+		if err := os.WriteFile(local, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", local, err)
+		}
+		entry = scanSpec(ctx, config, client, local, logger)
+		entry.Repository, entry.File = file.Repository, file.Path
+		catalog.add(entry)
+	}
+	return nil
+}
+
+// discoverSpecs walks a directory tree for OpenAPI, Swagger and AsyncAPI specs,
+// recognized by their version field, skipping hidden and dependency directories
+// and what .governanceignore lists. With formats, only specs of those formats
+// are returned.
+func discoverSpecs(root string, maxSize int64, formats ...string) ([]string, error) {
+	ignore, err := loadGovernanceIgnore()
+	if err != nil {
+		return nil, err
+	}
+	var specs []string
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != root && (strings.HasPrefix(name, ".") || slices.Contains(skippedScanDirs, name) || ignore.ignored(repoPath(path), true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !slices.Contains(specExtensions, strings.ToLower(filepath.Ext(path))) || ignore.ignored(repoPath(path), false) {
+			return nil
+		}
+		if info, err := entry.Info(); err != nil || (maxSize > 0 && info.Size() > maxSize) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if format := specFormat(string(content)); format != "" && (len(formats) == 0 || slices.Contains(formats, format)) {
+			specs = append(specs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	return specs, nil
+}
+
+// specFormat returns the format of the first document of content that is a
+// spec, or "" if none is
+func specFormat(content string) string {
+	for _, document := range splitDocuments(content) {
+		if doc, err := parseSpec(document.Content); err == nil {
+			if format := doc.format(); format != "" {
+				return format
+			}
+		}
+	}
+	return ""
+}
+
+// scanSpec analyzes a single spec of the catalog
+func scanSpec(ctx context.Context, config *Configuration, client *integrations.GovernanceClient, path string, logger *zap.Logger) CatalogEntry {
+	entry := CatalogEntry{File: repoPath(path), Verdict: VerdictError}
+	findings, content, err := analyzeTarget(ctx, config, client, specTarget{Path: path}, logger)
+	if err != nil {
+		logger.Warn("Failed to analyze spec", zap.String("path", path), zap.Error(err))
+		entry.Error = err.Error()
+		return entry
+	}
+	entry.Format = specFormat(content)
+
+	findings, ignored := config.OrgPolicy.filter(findings)
+	entry.Excluded = len(ignored)
+
+	docs := parseSpecDocuments(content)
+	for _, doc := range docs {
+		entry.Stats = entry.Stats.add(specStats(doc))
+	}
+	if config.ExcludeDeprecated && len(docs) > 0 {
+		var excluded []Finding
+		findings, excluded = excludeDeprecated(findings, docs, time.Now())
+		entry.Excluded += len(excluded)
+	}
+
+	entry.Errors, entry.Warnings = countSeverities(findings)
+	entry.Info = len(findings) - entry.Errors - entry.Warnings
+	entry.Score = governanceScore(entry.Errors, entry.Warnings, entry.Info)
+	entry.Verdict = VerdictPass
+	if checkThresholds(config, entry.Errors, entry.Warnings) != nil {
+		entry.Verdict = VerdictFail
+	}
+	return entry
+}
+
+// add records a spec in the catalog totals
+func (c *CatalogReport) add(entry CatalogEntry) {
+	c.Specs = append(c.Specs, entry)
+	c.Errors += entry.Errors
+	c.Warnings += entry.Warnings
+	if entry.Verdict != VerdictPass {
+		c.Failing++
+	}
+}
+
+// printCatalog prints the catalog section of the console report
+func printCatalog(config *Configuration, catalog *CatalogReport) {
+	// Specs of an organization or group are told apart by their repository
+	name := func(entry CatalogEntry) string {
+		if catalog.Owner != "" {
+			return entry.Repository + "/" + entry.File
+		}
+		return entry.File
+	}
+	width := len([]rune(config.text("report.file")))
+	for _, entry := range catalog.Specs {
+		width = max(width, len([]rune(name(entry))))
+	}
+
+	fmt.Printf("\n================ %s ================\n", config.text("report.catalog"))
+	fmt.Printf("    %-*s  %6s  %8s  %5s  %s\n", width, config.text("report.file"), config.text("report.errors"),
+		config.text("report.warnings"), config.text("report.score"), config.text("report.verdict"))
+	for _, entry := range catalog.Specs {
+		fmt.Printf("    %-*s  %6d  %8d  %5d  %s\n", width, name(entry), entry.Errors, entry.Warnings, entry.Score, config.text("verdict."+entry.Verdict))
+	}
+	fmt.Println("    " + config.text("report.catalog_totals", len(catalog.Specs), catalog.Failing, catalog.Errors, catalog.Warnings))
+	fmt.Println("===========================================================")
+}
+
+// writeCatalog writes the catalog report as JSON
+func writeCatalog(path string, catalog *CatalogReport) error {
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal catalog: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write catalog report %s: %w", path, err)
+	}
+	return nil
+}
+
+// catalogMetrics converts the catalog into gauges, grouped by metric name
+func catalogMetrics(catalog *CatalogReport) []integrations.Metric {
+	metrics := []integrations.Metric{
+		{Name: "governance_catalog_specs", Help: "Specs found by the catalog scan.", Value: float64(len(catalog.Specs))},
+		{Name: "governance_catalog_failing_specs", Help: "Specs that fail the thresholds or couldn't be analyzed.", Value: float64(catalog.Failing)},
+		{Name: "governance_catalog_scan_timestamp_seconds", Help: "Time of the catalog scan.", Value: float64(catalog.GeneratedAt.Unix())},
+	}
+	for _, severity := range []string{severityError, severityWarning, severityInfo} {
+		for _, entry := range catalog.Specs {
+			count := map[string]int{severityError: entry.Errors, severityWarning: entry.Warnings, severityInfo: entry.Info}[severity]
+			metrics = append(metrics, integrations.Metric{
+				Name: "governance_spec_findings", Help: "Findings per spec and severity.",
+				Labels: map[string]string{"repository": entry.Repository, "spec": entry.File, "severity": severity},
+				Value:  float64(count),
+			})
+		}
+	}
+	for _, entry := range catalog.Specs {
+		metrics = append(metrics, integrations.Metric{
+			Name: "governance_spec_score", Help: "Governance score per spec, from 100 down to 0.",
+			Labels: map[string]string{"repository": entry.Repository, "spec": entry.File},
+			Value:  float64(entry.Score),
+		})
+	}
+	return metrics
+}
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// configSchemaJSON is the JSON Schema of the configuration file, shipped for
+// editors and checked at startup
+//
+//go:embed config.schema.json
+var configSchemaJSON []byte
+
+// configSchemaURL is the $id of the configuration schema
+const configSchemaURL = "https://raw.githubusercontent.com/TykTechnologies/governance-action/main/pkg/core/config.schema.json"
+
+var (
+	configSchemaOnce     sync.Once
+	configSchema         *jsonschema.Schema
+	configSchemaDocument interface{}
+	configSchemaErr      error
+)
+
+// compileConfigSchema compiles the embedded schema once, keeping its document
+// to look up the settings a mapping accepts
+func compileConfigSchema() (*jsonschema.Schema, interface{}, error) {
+	configSchemaOnce.Do(func() {
+		if configSchemaErr = json.Unmarshal(configSchemaJSON, &configSchemaDocument); configSchemaErr != nil {
+			return
+		}
+		compiler := jsonschema.NewCompiler()
+		compiler.Draft = jsonschema.Draft2020
+		if configSchemaErr = compiler.AddResource(configSchemaURL, bytes.NewReader(configSchemaJSON)); configSchemaErr != nil {
+			return
+		}
+		configSchema, configSchemaErr = compiler.Compile(configSchemaURL)
+	})
+	if configSchemaErr != nil {
+		return nil, nil, fmt.Errorf("invalid configuration schema: %w", configSchemaErr)
+	}
+	return configSchema, configSchemaDocument, nil
+}
+
+// schemaError is a violation of the schema at a path such as .profiles.public.max_errors
+type schemaError struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (e schemaError) Error() string {
+	return fmt.Sprintf("`%s` %s (line %d)", e.Path, e.Message, e.Line)
+}
+
+// validateConfigSchema checks the configuration file content against the
+// schema. Violations are reported with their path and line, e.g.
+// "`.profiles.public.max_errors` must be an integer (line 7)".
+func validateConfigSchema(content []byte) error {
+	schema, document, err := compileConfigSchema()
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+
+	err = schema.Validate(yamlValue(root))
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	var violations []schemaError
+	for _, leaf := range schemaLeaves(validationErr) {
+		violations = append(violations, describeViolation(leaf, root, document)...)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Line < violations[j].Line })
+	errs := make([]error, len(violations))
+	for i, violation := range violations {
+		errs[i] = violation
+	}
+	return errors.Join(errs...)
+}
+
+// yamlValue converts a YAML node into the JSON value the validator expects.
+// Empty settings are treated as unset and dropped from their mapping.
+func yamlValue(node *yaml.Node) interface{} {
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.MappingNode:
+		value := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if item := resolveAlias(node.Content[i+1]); item.Kind == yaml.ScalarNode && item.Tag == "!!null" {
+				continue
+			}
+			value[node.Content[i].Value] = yamlValue(node.Content[i+1])
+		}
+		return value
+	case yaml.SequenceNode:
+		value := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			value[i] = yamlValue(item)
+		}
+		return value
+	}
+	switch node.Tag {
+	case "!!null":
+		return nil
+	case "!!bool":
+		var value bool
+		if node.Decode(&value) == nil {
+			return value
+		}
+	case "!!int":
+		var value int64
+		if node.Decode(&value) == nil {
+			return value
+		}
+	case "!!float":
+		var value float64
+		if node.Decode(&value) == nil {
+			return value
+		}
+	}
+	return node.Value
+}
+
+// resolveAlias returns the node an alias such as *defaults refers to
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		return node.Alias
+	}
+	return node
+}
+
+// schemaLeaves returns the violations without causes, the ones naming a
+// keyword that failed
+func schemaLeaves(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, schemaLeaves(cause)...)
+	}
+	return leaves
+}
+
+// describeViolation locates a violation in the file and words it like the
+// rest of the configuration errors
+func describeViolation(leaf *jsonschema.ValidationError, root *yaml.Node, document interface{}) []schemaError {
+	node, path := locateInstance(root, leaf.InstanceLocation)
+	fail := func(node *yaml.Node, path, message string) schemaError {
+		if path == "" {
+			path = "."
+		}
+		return schemaError{Path: path, Line: node.Line, Message: message}
+	}
+
+	keyword := leaf.KeywordLocation[strings.LastIndex(leaf.KeywordLocation, "/")+1:]
+	switch keyword {
+	case "type":
+		expected, _, _ := strings.Cut(strings.TrimPrefix(leaf.Message, "expected "), ", but got")
+		// YAML decodes unquoted numbers such as a ruleset version of 1.2 into strings
+		if expected == "string" && (node.Tag == "!!int" || node.Tag == "!!float") {
+			return nil
+		}
+		return []schemaError{fail(node, path, "must be "+schemaTypeName(expected))}
+	case "additionalProperties":
+		known := knownSettings(document, leaf.AbsoluteKeywordLocation)
+		var violations []schemaError
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if slices.Contains(known, key.Value) {
+				continue
+			}
+			violations = append(violations, fail(key, path+"."+key.Value, "is not a known setting, expected one of: "+strings.Join(known, ", ")))
+		}
+		return violations
+	case "required":
+		return []schemaError{fail(node, path, "is missing the required "+strings.ReplaceAll(strings.TrimPrefix(leaf.Message, "missing properties: "), "'", ""))}
+	}
+	return []schemaError{fail(node, path, leaf.Message)}
+}
+
+// locateInstance follows a JSON pointer such as /profiles/public/max_errors
+// to its node, returning the node and its path such as .profiles.public.max_errors
+func locateInstance(root *yaml.Node, pointer string) (*yaml.Node, string) {
+	node, path := resolveAlias(root), ""
+	if pointer == "" {
+		return node, path
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == token {
+					node, path = resolveAlias(node.Content[i+1]), path+"."+token
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(token); err == nil && i < len(node.Content) {
+				node, path = resolveAlias(node.Content[i]), path+"["+token+"]"
+			}
+		}
+	}
+	return node, path
+}
+
+// knownSettings lists the properties of the schema owning a failed
+// additionalProperties keyword, given its absolute location
+func knownSettings(document interface{}, location string) []string {
+	_, fragment, _ := strings.Cut(location, "#")
+	tokens := strings.Split(strings.TrimPrefix(fragment, "/"), "/")
+	schema := document
+	for _, token := range tokens[:len(tokens)-1] {
+		object, ok := schema.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		schema = object[strings.NewReplacer("~1", "/", "~0", "~").Replace(token)]
+	}
+	object, _ := schema.(map[string]interface{})
+	properties, _ := object["properties"].(map[string]interface{})
+	known := make([]string, 0, len(properties))
+	for name := range properties {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+	return known
+}
+
+// schemaTypeName describes a JSON Schema type in an error message
+func schemaTypeName(schemaType string) string {
+	switch schemaType {
+	case "object":
+		return "a mapping"
+	case "array":
+		return "a list"
+	case "integer":
+		return "an integer"
+	default:
+		return "a " + schemaType
+	}
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "valid", content: "rule_id: r\nmax_errors: 1\napi_path: [apis/*.yaml]\n"},
+		{name: "empty file", content: ""},
+		{name: "unquoted version and empty setting", content: "ruleset_version: 1.2\nmax_errors:\n"},
+		{name: "alias", content: "profiles:\n  public: &public\n    max_errors: 0\n  internal: *public\n"},
+		{
+			name:    "wrong type in a profile",
+			content: "profiles:\n  public:\n    rule_id: r\n    max_errors: many\n",
+			want:    []string{"`.profiles.public.max_errors` must be an integer (line 4)"},
+		},
+		{
+			name:    "unknown settings",
+			content: "rule_id: r\nrulset: x\nprofiles:\n  p:\n    foo: 1\n",
+			want: []string{
+				"`.rulset` is not a known setting, expected one of: api_path,",
+				"`.profiles.p.foo` is not a known setting, expected one of: branches, max_errors, max_warnings, rule_id (line 5)",
+			},
+		},
+		{
+			name:    "missing required setting",
+			content: "policies:\n  - branch: main\n",
+			want:    []string{"`.policies[0]` is missing the required mode (line 2)"},
+		},
+		{
+			name:    "keywords",
+			content: "governance_service: ftp://x\nmax_errors: -1\npolicies:\n  - mode: strict\n",
+			want: []string{
+				"`.governance_service` does not match pattern '^https?://' (line 1)",
+				"`.max_errors` must be >= 0 but found -1 (line 2)",
+				"`.policies[0].mode` value must be one of \"enforce\", \"advisory\" (line 4)",
+			},
+		},
+		{name: "not a mapping", content: "- a\n", want: []string{"`.` must be a mapping (line 1)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfigSchema([]byte(tt.content))
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %q", tt.want)
+			}
+			got := strings.Split(err.Error(), "\n")
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(got[i], want) {
+					t.Errorf("error %d is %q, want %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// outOfScopeReason is the exclusion reason of findings outside --only-path and --only-tag
+const outOfScopeReason = "outside the selected paths and tags"
+
+// RunOptions are the command line options of a governance run
+type RunOptions struct {
+	// OnlyPaths and OnlyTags restrict the analysis to the operations under the
+	// path globs or with the tags, for APIs governed incrementally
+	OnlyPaths []string
+	OnlyTags  []string
+	// Reports are the files the caller writes the run's reports to, such as
+	// the status file, linked from the outputs and the check run
+	Reports []string
+	// DebugEnv prints the governance environment and how the settings were
+	// resolved, with secrets redacted
+	DebugEnv bool
+	// SnapshotDir receives canonical snapshots of the reports, or with
+	// SnapshotCompare holds the snapshots the reports must match
+	SnapshotDir     string
+	SnapshotCompare bool
+	// Formats are the report files written besides the console report,
+	// overriding output_format
+	Formats []string
+	// APIPath overrides api_path, e.g. with the specs a GitOps hook read from
+	// a ConfigMap, and APIPathSource names where it came from
+	APIPath       string
+	APIPathSource string
+	// Local runs ignore the CI environment, publishing nothing to the pull
+	// request or the pipeline, e.g. for the lint command
+	Local bool
+	// Console receives the console report and the workflow commands of the
+	// run, stdout by default
+	Console io.Writer
+}
+
+// pathGlob compiles a path glob, where * matches within a path segment and **
+// across segments, e.g. /users/** matches /users and everything below it
+func pathGlob(glob string) *regexp.Regexp {
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.ReplaceAll(pattern, `/\*\*`, `(/.*)?`)
+	pattern = strings.ReplaceAll(pattern, `\*\*`, `.*`)
+	pattern = strings.ReplaceAll(pattern, `\*`, `[^/]*`)
+	return regexp.MustCompile("^" + pattern + "$")
+}
+
+// excludeOutOfScope splits findings into the ones on the operations selected by
+// path glob or tag and the ones outside of them, including findings outside the
+// paths such as on shared components. The docs are the parsed documents of the
+// spec file by index.
+func excludeOutOfScope(findings []Finding, docs map[int]specDocument, onlyPaths, onlyTags []string) (kept, excluded []Finding) {
+	if len(onlyPaths) == 0 && len(onlyTags) == 0 {
+		return findings, nil
+	}
+	globs := pathGlobs(onlyPaths)
+	for _, finding := range findings {
+		if inScope(finding.Path, docs[finding.Document], globs, onlyTags) {
+			kept = append(kept, finding)
+			continue
+		}
+		finding.ExclusionReason = outOfScopeReason
+		excluded = append(excluded, finding)
+	}
+	return kept, excluded
+}
+
+// pathGlobs compiles the path globs
+func pathGlobs(globs []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		compiled = append(compiled, pathGlob(glob))
+	}
+	return compiled
+}
+
+// inScope reports whether a finding path is on a path matching the globs, if
+// any, and on an operation with one of the tags, if any. Findings on a path
+// item match a tag when any of its operations has it.
+func inScope(path []string, doc specDocument, globs []*regexp.Regexp, tags []string) bool {
+	if len(path) < 2 || (path[0] != "paths" && path[0] != "webhooks") {
+		return false
+	}
+	if len(globs) > 0 && !slices.ContainsFunc(globs, func(glob *regexp.Regexp) bool { return glob.MatchString(path[1]) }) {
+		return false
+	}
+	if len(tags) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(operationTags(path, doc), func(tag string) bool { return slices.Contains(tags, tag) })
+}
@@ -0,0 +1,354 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/mockserver"
+	"go.uber.org/zap"
+)
+
+// selfTestEnv are the variables of the runner environment the self-test runs
+// keep, all others are cleared so the runner's settings don't leak in
+var selfTestEnv = []string{"PATH", "HOME", "TMPDIR", "TMP", "TEMP", "SYSTEMROOT"}
+
+// selfTestRun is the outcome of one analysis run of the self-test
+type selfTestRun struct {
+	Result  *RunResult
+	Err     error
+	Console string
+}
+
+// selfTestCheck is an analysis run against the mock service and what its
+// outcome must look like
+type selfTestCheck struct {
+	Name string
+	// env returns the variables of the run on top of the mock service settings,
+	// given the directory of the check
+	env     func(dir string) map[string]string
+	options func(dir string) RunOptions
+	verify  func(dir string, run selfTestRun) error
+}
+
+// selfTestChecks cover the output formats and failure modes. The mock service
+// reports an error and a warning for any spec, so runs fail the thresholds.
+var selfTestChecks = []selfTestCheck{
+	{
+		Name: "console report",
+		verify: func(dir string, run selfTestRun) error {
+			if err := verifyCounts(run); err != nil {
+				return err
+			}
+			if !strings.Contains(run.Console, "owasp-rate-limit") {
+				return fmt.Errorf("console report doesn't list the findings")
+			}
+			return nil
+		},
+	},
+	{
+		Name: "GitHub outputs",
+		env: func(dir string) map[string]string {
+			return map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_OUTPUT": filepath.Join(dir, "github_output")}
+		},
+		verify: func(dir string, run selfTestRun) error {
+			return verifyOutputFile(filepath.Join(dir, "github_output"))
+		},
+	},
+	{
+		Name: "GitLab dotenv",
+		env: func(dir string) map[string]string {
+			return map[string]string{"GITLAB_CI": "true", "INPUT_GITLAB_OUTPUT_FILE": filepath.Join(dir, "governance.env")}
+		},
+		verify: func(dir string, run selfTestRun) error {
+			return verifyOutputFile(filepath.Join(dir, "governance.env"))
+		},
+	},
+	{
+		Name: "status file",
+		verify: func(dir string, run selfTestRun) error {
+			path := filepath.Join(dir, "governance-status.json")
+			if err := WriteStatusFile(path, run.Result, run.Err); err != nil {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			var status RunResult
+			if err := json.Unmarshal(data, &status); err != nil {
+				return fmt.Errorf("status file isn't valid JSON: %w", err)
+			}
+			return verifyCounts(selfTestRun{Result: &status})
+		},
+	},
+	{
+		Name: "report snapshots",
+		options: func(dir string) RunOptions {
+			return RunOptions{SnapshotDir: filepath.Join(dir, "snapshots")}
+		},
+		verify: func(dir string, run selfTestRun) error {
+			entries, err := os.ReadDir(filepath.Join(dir, "snapshots"))
+			if err != nil {
+				return err
+			}
+			written := map[string]bool{}
+			for _, entry := range entries {
+				written[strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))] = true
+			}
+			// Every output format is snapshotted, named after the format
+			for _, name := range append([]string{"check-run", "step-summary"}, outputFormats...) {
+				if !written[name] {
+					return fmt.Errorf("snapshot of %s missing", name)
+				}
+			}
+			return verifyCounts(run)
+		},
+	},
+	{
+		Name: "report files",
+		options: func(dir string) RunOptions {
+			var formats []string
+			for _, format := range outputFormats {
+				if format != formatConsole {
+					formats = append(formats, format+":"+selfTestReportPath(dir, format))
+				}
+			}
+			return RunOptions{Formats: formats}
+		},
+		verify: func(dir string, run selfTestRun) error {
+			for _, format := range outputFormats {
+				if format == formatConsole {
+					continue
+				}
+				if err := verifyReportFile(format, reporters[format].file(selfTestReportPath(dir, format))); err != nil {
+					return err
+				}
+			}
+			return verifyCounts(run)
+		},
+	},
+	{
+		Name: "retries",
+		env: func(dir string) map[string]string {
+			return map[string]string{"INPUT_GOVERNANCE_AUTH": "flaky-token", "INPUT_RETRIES": "2", "INPUT_RETRY_BACKOFF": "10ms"}
+		},
+		verify: func(dir string, run selfTestRun) error {
+			if run.Result.RetriesUsed == 0 {
+				return fmt.Errorf("failed request wasn't retried")
+			}
+			return verifyCounts(run)
+		},
+	},
+	{
+		Name: "auth failure",
+		env: func(dir string) map[string]string {
+			return map[string]string{"INPUT_GOVERNANCE_AUTH": "expired-token"}
+		},
+		verify: func(dir string, run selfTestRun) error {
+			if run.Err == nil || run.Result.Verdict != VerdictError {
+				return fmt.Errorf("expected the run to fail with the %s verdict, got %s", VerdictError, run.Result.Verdict)
+			}
+			return nil
+		},
+	},
+}
+
+// RunSelfTest runs the analysis against the embedded mock governance service
+// in every output format and verifies the results, as a smoke test of the
+// action on a new runner image. The runs don't see the runner's environment or
+// the repository's configuration file.
+func RunSelfTest(logger *zap.Logger) error {
+	dir, err := os.MkdirTemp("", "governance-self-test-")
+	if err != nil {
+		return fmt.Errorf("failed to create the self-test directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(mockserver.Handler())
+	defer server.Close()
+	logger.Info("Started the mock governance service", zap.String("url", server.URL))
+
+	spec, err := generateFixture(nil, "yaml")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "openapi.yaml"), spec, 0644); err != nil {
+		return fmt.Errorf("failed to write the self-test spec: %w", err)
+	}
+
+	environ := os.Environ()
+	defer restoreEnv(environ)
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	defer os.Chdir(cwd)
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+
+	failed := 0
+	for i, check := range selfTestChecks {
+		checkDir := filepath.Join(dir, fmt.Sprintf("check-%d", i+1))
+		if err := os.Mkdir(checkDir, 0755); err != nil {
+			return err
+		}
+		env := map[string]string{
+			"INPUT_GOVERNANCE_SERVICE": server.URL + "/api",
+			"INPUT_GOVERNANCE_AUTH":    "self-test-token",
+			"INPUT_RULE_ID":            "self-test",
+			"INPUT_API_PATH":           "openapi.yaml",
+			"INPUT_CACHE_DIR":          filepath.Join(checkDir, "cache"),
+		}
+		if check.env != nil {
+			for name, value := range check.env(checkDir) {
+				env[name] = value
+			}
+		}
+		var options RunOptions
+		if check.options != nil {
+			options = check.options(checkDir)
+		}
+
+		setSelfTestEnv(environ, env)
+		var console bytes.Buffer
+		options.Console = &console
+		var run selfTestRun
+		run.Result, run.Err = RunAction(zap.NewNop(), options)
+		run.Console = console.String()
+		if err := check.verify(checkDir, run); err != nil {
+			failed++
+			fmt.Printf("❌ %s: %v\n", check.Name, err)
+			continue
+		}
+		fmt.Printf("✅ %s\n", check.Name)
+	}
+
+	fmt.Printf("%d of %d self-test checks passed\n", len(selfTestChecks)-failed, len(selfTestChecks))
+	if failed > 0 {
+		return fmt.Errorf("self-test failed: %d of %d checks failed", failed, len(selfTestChecks))
+	}
+	return nil
+}
+
+// verifyCounts checks that the run reached the verdict the mock findings lead to
+func verifyCounts(run selfTestRun) error {
+	result := run.Result
+	if result.Verdict != VerdictFail || result.Errors != 1 || result.Warnings != 1 {
+		return fmt.Errorf("expected the %s verdict with 1 error and 1 warning, got %s with %d errors and %d warnings",
+			VerdictFail, result.Verdict, result.Errors, result.Warnings)
+	}
+	return nil
+}
+
+// selfTestReportPath returns where the report files check writes a format
+func selfTestReportPath(dir, format string) string {
+	return filepath.Join(dir, "reports", "governance."+format)
+}
+
+// verifyReportFile checks a report file parses as its format and lists the
+// findings, with the pages of the Markdown report, or for the admission
+// verdict carries the verdict
+func verifyReportFile(format, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%s report not written: %w", format, err)
+	}
+	switch format {
+	case formatMarkdown:
+		// The index links to a page per spec listing its findings
+		pages, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.md"))
+		if err != nil {
+			return err
+		}
+		for _, page := range pages {
+			if page == path {
+				continue
+			}
+			pageContent, err := os.ReadFile(page)
+			if err != nil {
+				return err
+			}
+			content = append(content, pageContent...)
+		}
+	case formatJUnit:
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				return fmt.Errorf("%s report isn't valid XML: %w", format, err)
+			}
+		}
+	case formatSARIF, formatCodeQuality, formatJSON, formatAdmission:
+		var parsed interface{}
+		if err := json.Unmarshal(content, &parsed); err != nil {
+			return fmt.Errorf("%s report isn't valid JSON: %w", format, err)
+		}
+	}
+	if format == formatAdmission {
+		var verdict admissionVerdict
+		if err := json.Unmarshal(content, &verdict); err != nil || verdict.Verdict != VerdictFail {
+			return fmt.Errorf("expected the %s verdict in the admission verdict, got %q", VerdictFail, verdict.Verdict)
+		}
+		return nil
+	}
+	if !bytes.Contains(content, []byte("owasp-rate-limit")) {
+		return fmt.Errorf("%s report doesn't list the findings", format)
+	}
+	return nil
+}
+
+// verifyOutputFile checks the counts in a file of name=value outputs
+func verifyOutputFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("outputs not written: %w", err)
+	}
+	outputs := map[string]string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		if name, value, ok := strings.Cut(line, "="); ok {
+			outputs[name] = value
+		}
+	}
+	expected := map[string]string{"error_count": "1", "warning_count": "1", "total_issues": "2"}
+	for name, value := range expected {
+		if outputs[name] != value {
+			return fmt.Errorf("expected output %s=%s, got %q", name, value, outputs[name])
+		}
+	}
+	return nil
+}
+
+// setSelfTestEnv replaces the environment with the kept runner variables and
+// the variables of a run
+func setSelfTestEnv(environ []string, env map[string]string) {
+	os.Clearenv()
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		for _, kept := range selfTestEnv {
+			if strings.EqualFold(name, kept) {
+				os.Setenv(name, value)
+			}
+		}
+	}
+	for name, value := range env {
+		os.Setenv(name, value)
+	}
+}
+
+// restoreEnv restores the environment saved with os.Environ
+func restoreEnv(environ []string) {
+	os.Clearenv()
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		os.Setenv(name, value)
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Severity names used to configure severity styles
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityInfo    = "info"
+)
+
+// SeverityStyle controls how a severity level is displayed in reports
+type SeverityStyle struct {
+	Label string `yaml:"label"`
+	Icon  string `yaml:"icon"`
+	// Color is a named color (red, yellow, blue, ...) or a #rrggbb hex value
+	Color string `yaml:"color"`
+}
+
+// defaultSeverityStyles are used for any style field that isn't configured
+var defaultSeverityStyles = map[string]SeverityStyle{
+	severityError:   {Label: "ERROR", Icon: "❌"},
+	severityWarning: {Label: "WARNING", Icon: "⚠️"},
+	severityInfo:    {Label: "INFO", Icon: "ℹ️"},
+}
+
+// ansiColors maps named colors to ANSI foreground codes
+var ansiColors = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+	"gray":    "90",
+}
+
+// severityName returns the configuration name of a severity level
+func severityName(severity int) string {
+	switch severity {
+	case 0:
+		return severityError
+	case 1:
+		return severityWarning
+	default:
+		return severityInfo
+	}
+}
+
+// maxSeverity is the highest severity level the service reports, for hints,
+// which count as info findings
+const maxSeverity = 3
+
+// mapUnknownSeverities gives findings with a severity level the service isn't
+// known to report, e.g. a negative one, the unknown_severity level, so they
+// aren't silently left out of the counts and gates. Each unknown level is
+// logged with the rules that reported it.
+func mapUnknownSeverities(config *Configuration, findings []Finding, logger *zap.Logger) {
+	unknown := map[int][]string{}
+	for i := range findings {
+		severity := findings[i].Severity
+		if severity >= 0 && severity <= maxSeverity {
+			continue
+		}
+		if !slices.Contains(unknown[severity], findings[i].Rule.Name) {
+			unknown[severity] = append(unknown[severity], findings[i].Rule.Name)
+		}
+		findings[i].Severity = severityLevels[config.UnknownSeverity]
+	}
+	levels := make([]int, 0, len(unknown))
+	for severity := range unknown {
+		levels = append(levels, severity)
+	}
+	sort.Ints(levels)
+	for _, severity := range levels {
+		logger.Warn("Governance service reported an unknown severity level, check unknown_severity",
+			zap.Int("severity", severity), zap.Strings("rules", unknown[severity]), zap.String("counted_as", config.UnknownSeverity))
+	}
+}
+
+// severityStyle returns the display style of a severity level, falling back to
+// the defaults for fields that aren't configured
+func (c *Configuration) severityStyle(severity int) SeverityStyle {
+	name := severityName(severity)
+	style := defaultSeverityStyles[name]
+	style.Label = c.text("severity." + name)
+	if custom, ok := c.SeverityStyles[name]; ok {
+		if custom.Label != "" {
+			style.Label = custom.Label
+		}
+		if custom.Icon != "" {
+			style.Icon = custom.Icon
+		}
+		style.Color = custom.Color
+	}
+	return style
+}
+
+// validateSeverityStyles checks the configured severity names and colors
+func validateSeverityStyles(styles map[string]SeverityStyle) error {
+	for name, style := range styles {
+		if _, ok := defaultSeverityStyles[name]; !ok {
+			return fmt.Errorf("severities: unknown severity %s, must be one of: %s, %s, %s", name, severityError, severityWarning, severityInfo)
+		}
+		if style.Color != "" && ansiColor(style.Color) == "" {
+			return fmt.Errorf("severities: %s: unknown color %s", name, style.Color)
+		}
+	}
+	return nil
+}
+
+// ansiColor returns the ANSI SGR parameters of a named or #rrggbb color, or "" if invalid
+func ansiColor(color string) string {
+	if code, ok := ansiColors[strings.ToLower(color)]; ok {
+		return code
+	}
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) != 6 || hex == color {
+		return ""
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("38;2;%d;%d;%d", rgb>>16&0xff, rgb>>8&0xff, rgb&0xff)
+}
+
+// colorize wraps text in the ANSI escape codes of the style color
+func (s SeverityStyle) colorize(text string) string {
+	code := ansiColor(s.Color)
+	if code == "" {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
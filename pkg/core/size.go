@@ -0,0 +1,49 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxSpecSize is the largest spec analyzed unless max_spec_size says otherwise
+const defaultMaxSpecSize = 50 << 20
+
+// Byte size units accepted in size inputs
+var byteUnits = []struct {
+	Suffix string
+	Bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseByteSize parses a size such as 50MB, 512KB or 1048576
+func parseByteSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(value, unit.Suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.Suffix))
+			multiplier = unit.Bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return n * multiplier, nil
+}
+
+// formatByteSize formats a size in the largest unit it reaches
+func formatByteSize(size int64) string {
+	for _, unit := range byteUnits[:len(byteUnits)-1] {
+		if size >= unit.Bytes {
+			return fmt.Sprintf("%.1f %s", float64(size)/float64(unit.Bytes), unit.Suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// maxSlackFindings caps the findings listed in a Slack message
+const maxSlackFindings = 10
+
+// slackRoute is a Slack webhook and the findings posted to it
+type slackRoute struct {
+	Webhook  string
+	Owners   []string // Owning teams routed to the webhook, none for the global webhook
+	Findings []Finding
+}
+
+// ownerKey normalizes a CODEOWNERS owner or channel mapping key, so "@org/team"
+// and "org/team" map to the same channel
+func ownerKey(owner string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(owner), "@"))
+}
+
+// slackRoutes assigns each finding to the channels of the teams owning its spec
+// file. Findings in files without a mapped owner go to the global webhook, if
+// there is one. Owners sharing a webhook get a single message.
+func slackRoutes(findings []Finding, owners codeowners, channels map[string]string, global string) []*slackRoute {
+	var routes []*slackRoute
+	byWebhook := map[string]*slackRoute{}
+	route := func(webhook, owner string, finding Finding) {
+		r, ok := byWebhook[webhook]
+		if !ok {
+			r = &slackRoute{Webhook: webhook}
+			byWebhook[webhook] = r
+			routes = append(routes, r)
+		}
+		if owner != "" && !slices.Contains(r.Owners, owner) {
+			r.Owners = append(r.Owners, owner)
+		}
+		// Owners of the same file sharing the webhook route the finding in a row
+		if n := len(r.Findings); n == 0 || r.Findings[n-1].Fingerprint() != finding.Fingerprint() {
+			r.Findings = append(r.Findings, finding)
+		}
+	}
+
+	for _, finding := range findings {
+		routed := false
+		for _, owner := range owners.owners(repoPath(finding.File)) {
+			if webhook := channels[ownerKey(owner)]; webhook != "" {
+				route(webhook, owner, finding)
+				routed = true
+			}
+		}
+		if !routed && global != "" {
+			route(global, "", finding)
+		}
+	}
+	return routes
+}
+
+// slackMessage renders the findings of a route as a Slack message
+func slackMessage(config *Configuration, ciContext map[string]string, report *analysisReport, route *slackRoute) integrations.SlackMessage {
+	errorCount, warningCount := countSeverities(route.Findings)
+	errorStyle, warningStyle := config.severityStyle(0), config.severityStyle(1)
+
+	var text strings.Builder
+	text.WriteString("*Governance findings*")
+	if repository := ciContext["repository"]; repository != "" {
+		fmt.Fprintf(&text, " in %s", slackEscaper.Replace(repository))
+	}
+	if number := ciContext["pull_request"]; number != "" && integrations.IsPullRequest(ciContext) {
+		fmt.Fprintf(&text, " (#%s)", number)
+	} else if branch := ciContext["branch"]; branch != "" {
+		fmt.Fprintf(&text, " (%s)", slackEscaper.Replace(branch))
+	}
+	if len(route.Owners) > 0 {
+		fmt.Fprintf(&text, " for %s", strings.Join(route.Owners, ", "))
+	}
+	fmt.Fprintf(&text, "\n%s %d %s, %s %d %s, %d total issues\n",
+		errorStyle.Icon, errorCount, errorStyle.Label, warningStyle.Icon, warningCount, warningStyle.Label, len(route.Findings))
+
+	findings := make([]Finding, len(route.Findings))
+	copy(findings, route.Findings)
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Severity < findings[j].Severity })
+	for i, finding := range findings {
+		if i == maxSlackFindings {
+			fmt.Fprintf(&text, "… and %d more\n", len(findings)-maxSlackFindings)
+			break
+		}
+		location := fmt.Sprintf("%s:%d", repoPath(finding.File), finding.Range.Start.Line)
+		if finding.Permalink != "" {
+			location = fmt.Sprintf("<%s|%s>", finding.Permalink, location)
+		}
+		fmt.Fprintf(&text, "• %s `%s` %s (%s)\n", config.severityStyle(finding.Severity).Icon,
+			finding.Rule.Name, slackEscaper.Replace(finding.Message), location)
+	}
+	if report.ReportURL != "" {
+		fmt.Fprintf(&text, "<%s|Full report>\n", report.ReportURL)
+	}
+	return integrations.SlackMessage{Text: text.String()}
+}
+
+// slackEscaper escapes the characters Slack treats as markup
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// notifySlack posts the findings to the Slack channels of the teams owning the
+// spec files, by CODEOWNERS and the slack_channels mapping, and the rest to the
+// global webhook. Runs without findings post nothing. Failures are logged but
+// never fail the run.
+func notifySlack(ctx context.Context, config *Configuration, ciContext map[string]string, report *analysisReport, logger *zap.Logger) {
+	if len(report.Findings) == 0 {
+		return
+	}
+	var owners codeowners
+	if len(config.SlackChannels) > 0 {
+		var path string
+		var err error
+		if owners, path, err = loadCodeowners(); err != nil {
+			logger.Warn("Failed to read CODEOWNERS, posting to the global Slack webhook", zap.String("path", path), zap.Error(err))
+		} else if path == "" {
+			logger.Warn("No CODEOWNERS file to route Slack notifications by, posting to the global Slack webhook")
+		}
+	}
+
+	channels := make(map[string]string, len(config.SlackChannels))
+	for owner, webhook := range config.SlackChannels {
+		channels[ownerKey(owner)] = os.ExpandEnv(webhook)
+	}
+	routes := slackRoutes(report.Findings, owners, channels, config.SlackWebhook)
+	if len(routes) == 0 {
+		logger.Info("No Slack channel for the findings, skipping Slack notifications")
+		return
+	}
+
+	client := integrations.NewSlackClient(logger)
+	for _, route := range routes {
+		logger.Info("Posting findings to Slack", zap.Strings("owners", route.Owners), zap.Int("findings", len(route.Findings)))
+		if err := client.Post(ctx, route.Webhook, slackMessage(config, ciContext, report, route)); err != nil {
+			logger.Warn("Failed to post to Slack", zap.Strings("owners", route.Owners), zap.Error(err))
+			report.reportingFailed("slack_webhook", err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxSnapshotDiffLines is how many differing lines are shown per drifted snapshot
+const maxSnapshotDiffLines = 10
+
+// commitSHA matches full commit hashes, e.g. in permalinks
+var commitSHA = regexp.MustCompile(`\b[0-9a-f]{40}\b`)
+
+// runDuration matches the run duration of the results file, which is taken
+// when the file is written
+var runDuration = regexp.MustCompile(`"duration_ms": \d+`)
+
+// snapshotTime replaces the run timestamps in snapshots
+var snapshotTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// canonicalReport returns a copy of the report without the values that change
+// from run to run, such as timestamps, evaluation times and run URLs, so the
+// reports rendered from it only change when the findings or the reporter
+// configuration do
+func canonicalReport(config *Configuration, report *analysisReport) *analysisReport {
+	canonical := *report
+	canonical.Started = snapshotTime.In(config.Timezone)
+	canonical.Finished = snapshotTime.In(config.Timezone)
+	canonical.Diagnostics = nil
+	canonical.ReportURL = ""
+	canonical.ArtifactURLs = nil
+	canonical.Findings = canonicalFindings(report.Findings)
+	canonical.Excluded = canonicalFindings(report.Excluded)
+	return &canonical
+}
+
+// canonicalFindings copies the findings without when they were first seen,
+// which depends on the run history
+func canonicalFindings(findings []Finding) []Finding {
+	canonical := make([]Finding, len(findings))
+	for i, finding := range findings {
+		finding.FirstSeen = time.Time{}
+		canonical[i] = finding
+	}
+	return canonical
+}
+
+// canonicalResult returns a copy of the result of the canonical report without
+// the timings and the CI context of the run
+func canonicalResult(result *RunResult, report *analysisReport) *RunResult {
+	canonical := *result
+	canonical.StartedAt, canonical.FinishedAt = report.Started, report.Finished
+	canonical.RetriesUsed, canonical.RetryWait = 0, 0
+	canonical.AnalysisDurationMS, canonical.ServiceLatencyMS, canonical.EvaluationTimeMS = 0, 0, 0
+	canonical.Artifacts, canonical.ReportURL = nil, ""
+	canonical.ci, canonical.ciContext = "local", map[string]string{}
+	canonical.report = report
+	return &canonical
+}
+
+// reportSnapshots renders the reports of the run from the canonical report,
+// keyed by snapshot file name: the console and pull request reports, and the
+// report of every output format, named after the format
+func reportSnapshots(config *Configuration, report *analysisReport, result *RunResult) (map[string]string, error) {
+	canonical := canonicalReport(config, report)
+	var console bytes.Buffer
+	printReport(&console, config, canonical)
+	snapshots := map[string]string{
+		"console.txt":     console.String(),
+		"check-run.md":    checkRunSummary(config, canonical, nil),
+		"step-summary.md": stepSummary(config, canonical),
+	}
+
+	dir, err := os.MkdirTemp("", "governance-snapshots")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the reports: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	run := canonicalResult(result, canonical)
+	for _, format := range outputFormats {
+		if format == formatConsole {
+			continue
+		}
+		r := reporters[format]
+		path := filepath.Join(dir, format+filepath.Ext(r.defaultPath(config)))
+		if err := r.write(config, canonical, run, path); err != nil {
+			return nil, fmt.Errorf("failed to render the %s report: %w", r.label(), err)
+		}
+	}
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		snapshots[filepath.ToSlash(name)] = runDuration.ReplaceAllString(string(content), `"duration_ms": 0`)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the rendered reports: %w", err)
+	}
+
+	for name, content := range snapshots {
+		snapshots[name] = commitSHA.ReplaceAllString(content, "<commit>")
+	}
+	return snapshots, nil
+}
+
+// writeSnapshots renders the report snapshots to dir, or with compare checks
+// them against the ones in dir and fails when the reports drifted
+func writeSnapshots(config *Configuration, report *analysisReport, result *RunResult, dir string, compare bool) error {
+	snapshots, err := reportSnapshots(config, report, result)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(snapshots))
+	for name := range snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !compare {
+		for _, name := range names {
+			// The Markdown report is a directory of pages
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+				return fmt.Errorf("failed to create snapshot directory: %w", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(snapshots[name]), 0644); err != nil {
+				return fmt.Errorf("failed to write snapshot: %w", err)
+			}
+		}
+		return nil
+	}
+
+	var drifted []string
+	for _, name := range names {
+		expected, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(config.Console, "📸 %s: no snapshot\n", name)
+			drifted = append(drifted, name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		if string(expected) == snapshots[name] {
+			continue
+		}
+		drifted = append(drifted, name)
+		fmt.Fprintf(config.Console, "📸 %s drifted from the snapshot:\n", name)
+		printSnapshotDiff(config.Console, string(expected), snapshots[name])
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("reports drifted from the snapshots in %s: %s", dir, strings.Join(drifted, ", "))
+	}
+	return nil
+}
+
+// printSnapshotDiff prints the first lines that differ between the snapshot and
+// the generated report
+func printSnapshotDiff(w io.Writer, expected, actual string) {
+	expectedLines, actualLines := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	shown := 0
+	for i := 0; i < max(len(expectedLines), len(actualLines)); i++ {
+		var want, got string
+		if i < len(expectedLines) {
+			want = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			got = actualLines[i]
+		}
+		if want == got {
+			continue
+		}
+		if shown == maxSnapshotDiffLines {
+			fmt.Fprintln(w, "    …")
+			return
+		}
+		fmt.Fprintf(w, "    line %d:\n    - %s\n    + %s\n", i+1, want, got)
+		shown++
+	}
+}
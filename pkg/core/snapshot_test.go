@@ -0,0 +1,49 @@
+package core
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReportSnapshotsCoverEveryFormat(t *testing.T) {
+	config := &Configuration{ReportSettings: ReportSettings{Timezone: time.UTC, Console: io.Discard, ReportPages: "file", ReportPageSize: 500}}
+	report := &analysisReport{
+		Verdict:  VerdictFail,
+		Findings: []Finding{testFinding("owasp-rate-limit", "paths", "/users")},
+		Coverage: &operationCoverage{},
+		Started:  time.Now(),
+		Finished: time.Now(),
+	}
+	result := &RunResult{Verdict: VerdictFail, StartedAt: time.Now(), FinishedAt: time.Now(), DurationMS: 42, report: report}
+
+	snapshots, err := reportSnapshots(config, report, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{"console.txt", "check-run.md", "step-summary.md", "markdown/" + reportIndex}
+	for _, format := range outputFormats {
+		if format != formatConsole && format != formatMarkdown {
+			names = append(names, format+filepath.Ext(reporters[format].defaultPath(config)))
+		}
+	}
+	for _, name := range names {
+		if _, ok := snapshots[name]; !ok {
+			t.Errorf("no %s snapshot", name)
+		}
+	}
+
+	// Rendering again later gives the same snapshots
+	later := *result
+	later.StartedAt, later.FinishedAt, later.DurationMS = time.Now().Add(time.Hour), time.Now().Add(2*time.Hour), 7
+	again, err := reportSnapshots(config, report, &later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range snapshots {
+		if again[name] != content {
+			t.Errorf("%s snapshot changed between runs:\n%s\n%s", name, content, again[name])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// Snippet rendering defaults: only the finding's lines, at most 20 of them,
+// and lines cut to 160 columns so single-line JSON specs stay readable
+const (
+	defaultSnippetMaxLines = 20
+	defaultSnippetTabWidth = 4
+	defaultSnippetMaxWidth = 160
+)
+
+// ellipsis marks where a snippet line was cut
+const ellipsis = "…"
+
+// snippetLine is a rendered line of a spec snippet
+type snippetLine struct {
+	Number  int // 1-based line number, 0 for the omission notice
+	Text    string
+	Finding bool // Whether the line is part of the finding rather than context
+}
+
+// snippetLines renders the lines of a finding's range with the configured
+// context around it. Lines beyond snippet_max_lines are replaced by a notice,
+// tabs are expanded and lines wider than snippet_max_width are cut to a window
+// around the finding's column.
+func snippetLines(config *Configuration, lines []string, r integrations.LintRange) []snippetLine {
+	start, end := int(r.Start.Line), int(r.End.Line)
+	if start < 1 || end > len(lines) || end < start {
+		return nil
+	}
+	first, last := max(1, start-config.SnippetContext), min(len(lines), end+config.SnippetContext)
+
+	var snippet []snippetLine
+	for number := first; number <= last; number++ {
+		if config.SnippetMaxLines > 0 && len(snippet) == config.SnippetMaxLines {
+			snippet = append(snippet, snippetLine{Text: fmt.Sprintf("%s %d more lines", ellipsis, last-number+1)})
+			break
+		}
+		column := 0
+		if number == start {
+			column = int(r.Start.Character)
+		}
+		text, column := expandTabs(lines[number-1], column, config.SnippetTabWidth)
+		snippet = append(snippet, snippetLine{
+			Number:  number,
+			Text:    cutLine(text, column, config.SnippetMaxWidth),
+			Finding: number >= start && number <= end,
+		})
+	}
+	return snippet
+}
+
+// printSnippet prints the snippet of a finding. Findings lines are marked when
+// context lines are shown around them.
+func printSnippet(w io.Writer, config *Configuration, lines []string, r integrations.LintRange) {
+	snippet := snippetLines(config, lines, r)
+	if len(snippet) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "    --- %s ---\n", config.text("report.snippet"))
+	for _, line := range snippet {
+		marker := " "
+		if line.Finding && config.SnippetContext > 0 {
+			marker = ">"
+		}
+		if line.Number == 0 {
+			fmt.Fprintf(w, "      %s\n", line.Text)
+			continue
+		}
+		fmt.Fprintf(w, "   %s%4d | %s\n", marker, line.Number, line.Text)
+	}
+	fmt.Fprintln(w, "    -------------------")
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop, returning the
+// expanded line and the expanded position of column. A width of 0 keeps tabs.
+func expandTabs(line string, column, width int) (string, int) {
+	if width <= 0 || !strings.Contains(line, "\t") {
+		return line, column
+	}
+	var expanded strings.Builder
+	expandedColumn := column
+	position := 0
+	for i, r := range []rune(line) {
+		if i == column {
+			expandedColumn = position
+		}
+		if r != '\t' {
+			expanded.WriteRune(r)
+			position++
+			continue
+		}
+		spaces := width - position%width
+		expanded.WriteString(strings.Repeat(" ", spaces))
+		position += spaces
+	}
+	return expanded.String(), expandedColumn
+}
+
+// cutLine cuts a line wider than width to a window starting shortly before
+// column, marking the cut ends. A width of 0 keeps the whole line.
+func cutLine(line string, column, width int) string {
+	runes := []rune(line)
+	if width <= 0 || len(runes) <= width {
+		return line
+	}
+	from := max(0, min(column-width/4, len(runes)-width))
+	to := from + width
+	cut := string(runes[from:to])
+	if from > 0 {
+		cut = ellipsis + cut
+	}
+	if to < len(runes) {
+		cut += ellipsis
+	}
+	return cut
+}
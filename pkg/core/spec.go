@@ -0,0 +1,203 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// httpMethods are the operation keys of an OpenAPI path item
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// specDocument is a parsed OpenAPI or Swagger document
+type specDocument map[string]interface{}
+
+// parseSpec parses YAML or JSON spec content
+func parseSpec(content string) (specDocument, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal([]byte(content), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+	doc, ok := stringKeys(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("spec is not a YAML or JSON object")
+	}
+	return specDocument(doc), nil
+}
+
+// stringKeys converts YAML mappings with non-string keys, such as unquoted
+// response codes, into string-keyed maps
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = stringKeys(item)
+		}
+		return m
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = stringKeys(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = stringKeys(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// object returns the map stored at the given key path, or nil
+func (d specDocument) object(keys ...string) map[string]interface{} {
+	current := map[string]interface{}(d)
+	for _, key := range keys {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// format detects the spec format hint from the version field, or returns "" for
+// unknown documents
+func (d specDocument) format() string {
+	switch {
+	case d["openapi"] != nil:
+		// An unquoted version such as 3.1 is parsed as a number
+		if version := fmt.Sprint(d["openapi"]); strings.HasPrefix(version, "3.1") {
+			return "openapi31"
+		}
+		return "openapi3"
+	case d["swagger"] != nil:
+		return "swagger2"
+	case d["asyncapi"] != nil:
+		return "asyncapi"
+	}
+	return ""
+}
+
+// operations returns the operation objects of the paths or, in OpenAPI 3.1, the
+// webhooks section, keyed by path or webhook name and method
+func (d specDocument) operations(section string) map[string]map[string]map[string]interface{} {
+	operations := map[string]map[string]map[string]interface{}{}
+	for path, item := range d.object(section) {
+		pathItem, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, method := range httpMethods {
+			if operation, ok := pathItem[method].(map[string]interface{}); ok {
+				if operations[path] == nil {
+					operations[path] = map[string]map[string]interface{}{}
+				}
+				operations[path][method] = operation
+			}
+		}
+	}
+	return operations
+}
+
+// SpecStats holds basic size statistics of a spec
+type SpecStats struct {
+	Paths           int `json:"paths"`
+	Operations      int `json:"operations"`
+	Schemas         int `json:"schemas"`
+	SecuritySchemes int `json:"security_schemes"`
+	Webhooks        int `json:"webhooks"` // OpenAPI 3.1 webhooks
+}
+
+// add returns the sum of both statistics, treating nil as empty
+func (s *SpecStats) add(other *SpecStats) *SpecStats {
+	if s == nil {
+		return other
+	}
+	return &SpecStats{
+		Paths:           s.Paths + other.Paths,
+		Operations:      s.Operations + other.Operations,
+		Schemas:         s.Schemas + other.Schemas,
+		SecuritySchemes: s.SecuritySchemes + other.SecuritySchemes,
+		Webhooks:        s.Webhooks + other.Webhooks,
+	}
+}
+
+// specStats computes the statistics of a spec, supporting both OpenAPI 3 and Swagger 2 layouts
+func specStats(doc specDocument) *SpecStats {
+	stats := &SpecStats{
+		Paths:           len(doc.object("paths")),
+		Schemas:         len(doc.object("components", "schemas")) + len(doc.object("definitions")),
+		SecuritySchemes: len(doc.object("components", "securitySchemes")) + len(doc.object("securityDefinitions")),
+		Webhooks:        len(doc.object("webhooks")),
+	}
+	for _, section := range []string{"paths", "webhooks"} {
+		for _, methods := range doc.operations(section) {
+			stats.Operations += len(methods)
+		}
+	}
+	return stats
+}
+
+// specLocation is a line range in the spec file
+type specLocation struct {
+	StartLine int
+	EndLine   int
+}
+
+// parseSpecNode parses spec content into a YAML node tree, which keeps the line
+// numbers of the original file for both YAML and JSON specs
+func parseSpecNode(content string) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil, fmt.Errorf("spec is empty")
+	}
+	return root.Content[0], nil
+}
+
+// locatePath resolves a finding path to the lines of the spec file it covers. When
+// the full path doesn't exist the location of the deepest existing parent is returned.
+func locatePath(root *yaml.Node, path []string) (specLocation, bool) {
+	node := root
+	location := specLocation{StartLine: root.Line, EndLine: lastLine(root)}
+	for _, segment := range path {
+		var key, value *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					key, value = node.Content[i], node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(node.Content) {
+				key, value = node.Content[index], node.Content[index]
+			}
+		}
+		if value == nil {
+			break
+		}
+		node = value
+		location = specLocation{StartLine: key.Line, EndLine: lastLine(value)}
+	}
+	return location, location.StartLine > 0
+}
+
+// lastLine returns the last line covered by a node and its children
+func lastLine(node *yaml.Node) int {
+	line := node.Line
+	for _, child := range node.Content {
+		if childLine := lastLine(child); childLine > line {
+			line = childLine
+		}
+	}
+	return line
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxPathSuggestions is how many similar specs a path error suggests
+const maxPathSuggestions = 3
+
+// specExtensions are the file extensions of specs suggested for a wrong path
+var specExtensions = []string{".yaml", ".yml", ".json"}
+
+// normalizeSpecPath accepts both separators so configurations written for
+// Windows runners work everywhere, and the other way around
+func normalizeSpecPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if spec, err := normalizeGitSpec(path); err == nil {
+		return spec
+	}
+	return filepath.Clean(filepath.FromSlash(strings.ReplaceAll(path, `\`, "/")))
+}
+
+// expandSpecPaths expands api_path, a comma-separated list of spec paths and
+// globs such as apis/**/*.yaml, into the specs to analyze: in the order listed,
+// the matches of a glob sorted. A glob matching no spec is an error, so a typo
+// can't pass the gate by analyzing nothing.
+func expandSpecPaths(apiPath string) ([]string, error) {
+	var paths []string
+	add := func(path string) {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	for _, entry := range splitList(apiPath) {
+		git := strings.HasPrefix(entry, gitSpecScheme)
+		if git {
+			if _, err := normalizeGitSpec(entry); err != nil {
+				return nil, err
+			}
+		}
+		if !strings.ContainsAny(entry, "*?") {
+			add(normalizeSpecPath(entry))
+			continue
+		}
+		var matches []string
+		var err error
+		if git {
+			matches, err = globGitSpecs(entry)
+		} else {
+			matches, err = globSpecs(entry)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("api_path: %s matches no spec files", entry)
+		}
+		for _, match := range matches {
+			add(match)
+		}
+	}
+	return paths, nil
+}
+
+// globSpecs returns the spec files matching a glob, walking the directory
+// before its first wildcard. Hidden and vendored directories are skipped, as
+// in catalog scans, and so is what .governanceignore lists.
+func globSpecs(pattern string) ([]string, error) {
+	ignore, err := loadGovernanceIgnore()
+	if err != nil {
+		return nil, err
+	}
+	pattern = path.Clean(strings.ReplaceAll(pattern, `\`, "/"))
+	root := "."
+	if slash := strings.LastIndex(pattern[:strings.IndexAny(pattern, "*?")], "/"); slash == 0 {
+		root = "/"
+	} else if slash > 0 {
+		root = pattern[:slash]
+	}
+
+	var specs []string
+	err = filepath.WalkDir(filepath.FromSlash(root), func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if file == filepath.FromSlash(root) && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if file != filepath.FromSlash(root) && (strings.HasPrefix(name, ".") || slices.Contains(skippedScanDirs, name) || ignore.ignored(repoPath(file), true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if slices.Contains(specExtensions, strings.ToLower(filepath.Ext(file))) && globMatch(pattern, filepath.ToSlash(file)) && !ignore.ignored(repoPath(file), false) {
+			specs = append(specs, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("api_path: failed to expand %s: %w", pattern, err)
+	}
+	sort.Strings(specs)
+	return specs, nil
+}
+
+// checkSpecPath returns an error suggesting similar specs when the path
+// doesn't exist or is a directory
+func checkSpecPath(path string) error {
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		specs := specFilesIn(path)
+		if len(specs) == 0 {
+			return fmt.Errorf("%s is a directory without spec files, point api_path at a spec file", path)
+		}
+		if len(specs) > maxPathSuggestions {
+			specs = specs[:maxPathSuggestions]
+		}
+		return fmt.Errorf("%s is a directory, point api_path at a spec file, e.g. %s", path, strings.Join(specs, ", "))
+	case os.IsNotExist(err):
+		if suggestions := similarSpecs(path); len(suggestions) > 0 {
+			return fmt.Errorf("spec %s not found, did you mean %s?", path, strings.Join(suggestions, " or "))
+		}
+		return fmt.Errorf("spec %s not found", path)
+	}
+	return nil
+}
+
+// specFilesIn lists the spec files in a directory
+func specFilesIn(dir string) []string {
+	var specs []string
+	for _, ext := range specExtensions {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*"+ext))
+		specs = append(specs, matches...)
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+// similarSpecs returns the specs with names closest to the missing path, looking
+// in its directory or, if that doesn't exist either, the nearest existing parent
+func similarSpecs(path string) []string {
+	dir := filepath.Dir(path)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+
+	name := strings.ToLower(filepath.Base(path))
+	type candidate struct {
+		path     string
+		distance int
+	}
+	var candidates []candidate
+	for _, spec := range specFilesIn(dir) {
+		distance := editDistance(name, strings.ToLower(filepath.Base(spec)))
+		// Only suggest names that are plausibly a typo of the missing one
+		if distance <= max(len(name)/2, 2) {
+			candidates = append(candidates, candidate{spec, distance})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < maxPathSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].path)
+	}
+	return suggestions
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(rb)]
+}
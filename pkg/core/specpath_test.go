@@ -0,0 +1,75 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// chdir makes dir the working directory for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestExpandSpecPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{
+		"apis/users.yaml", "apis/orders.yml", "apis/v2/accounts.json", "apis/notes.txt",
+		"apis/.drafts/draft.yaml", "apis/node_modules/dep.yaml", "apis/legacy/old.yaml",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("openapi: 3.0.3\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, governanceIgnoreFile), []byte("apis/legacy/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, dir)
+
+	tests := []struct {
+		name    string
+		apiPath string
+		want    []string
+	}{
+		{name: "single path", apiPath: "apis/users.yaml", want: []string{"apis/users.yaml"}},
+		{name: "missing paths are checked later", apiPath: "apis/missing.yaml", want: []string{"apis/missing.yaml"}},
+		{name: "windows separators", apiPath: `apis\users.yaml`, want: []string{"apis/users.yaml"}},
+		{name: "glob within a directory", apiPath: "apis/*.yaml", want: []string{"apis/users.yaml"}},
+		{name: "recursive glob skips hidden, vendored and ignored directories", apiPath: "apis/**/*", want: []string{"apis/orders.yml", "apis/users.yaml", "apis/v2/accounts.json"}},
+		{name: "listed order, globs sorted", apiPath: "apis/v2/accounts.json, apis/*.y*ml", want: []string{"apis/v2/accounts.json", "apis/orders.yml", "apis/users.yaml"}},
+		{name: "duplicates dropped", apiPath: "apis/users.yaml,apis/*.yaml,./apis/users.yaml", want: []string{"apis/users.yaml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandSpecPaths(tt.apiPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range got {
+				got[i] = filepath.ToSlash(got[i])
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, apiPath := range []string{"apis/*.txt", "nope/**/*.yaml", "apis/legacy/*.yaml"} {
+		if _, err := expandSpecPaths(apiPath); err == nil {
+			t.Errorf("expandSpecPaths(%q): expected an error for a glob matching no spec", apiPath)
+		}
+	}
+}
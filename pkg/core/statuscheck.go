@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// defaultStatusCheckName is the external status check the verdict is reported
+// to by default
+const defaultStatusCheckName = "Governance"
+
+// publishStatusCheck reports the verdict to the GitLab external status check
+// named status_check_name, so merge requests can require a passing governance
+// run whatever the status of the pipeline job. The status is set for the merge
+// request's head commit. Failures are logged but never fail the run.
+func publishStatusCheck(ctx context.Context, config *Configuration, ciContext map[string]string, result *RunResult, logger *zap.Logger) {
+	iid, err := strconv.Atoi(ciContext["pull_request"])
+	if err != nil || !integrations.IsPullRequest(ciContext) {
+		logger.Info("Not running on a merge request, skipping status check", zap.String("event_type", ciContext["event_type"]))
+		return
+	}
+	if config.GitLabToken == "" {
+		logger.Warn("gitlab_token is required to report external status checks")
+		return
+	}
+	client := integrations.NewGitLabClient(config.GitLabToken, logger)
+
+	checks, err := client.ListStatusChecks(ctx, iid)
+	if err != nil {
+		logger.Warn("Failed to report status check", zap.Error(err))
+		result.report.reportingFailed("status_check", err)
+		return
+	}
+	var check *integrations.ExternalStatusCheck
+	for i := range checks {
+		if checks[i].Name == config.StatusCheckName {
+			check = &checks[i]
+			break
+		}
+	}
+	if check == nil {
+		logger.Warn("No external status check applies to the merge request, add one in the project's merge request settings",
+			zap.String("name", config.StatusCheckName))
+		return
+	}
+
+	refs, err := client.MergeRequestDiffRefs(ctx, iid)
+	if err != nil {
+		logger.Warn("Failed to report status check", zap.Error(err))
+		result.report.reportingFailed("status_check", err)
+		return
+	}
+	status := "failed"
+	if result.proceeds() {
+		status = "passed"
+	}
+	if err := client.SetStatusCheckResponse(ctx, iid, check.ID, refs.HeadSHA, status); err != nil {
+		logger.Warn("Failed to report status check", zap.String("name", check.Name), zap.Error(err))
+		result.report.reportingFailed("status_check", err)
+		return
+	}
+	logger.Info("Reported status check", zap.String("name", check.Name), zap.String("sha", refs.HeadSHA), zap.String("status", status))
+}
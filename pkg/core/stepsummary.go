@@ -0,0 +1,178 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxStepSummaryFindings caps the findings with snippets in the step summary,
+// which GitHub limits to 1 MiB per step
+const maxStepSummaryFindings = 100
+
+// ruleFindings are the findings of a rule in the step summary
+type ruleFindings struct {
+	Rule     string
+	Severity int // The most severe level among the findings
+	Findings []Finding
+}
+
+// groupByRule groups the findings by rule, the most severe rules and then the
+// ones with the most findings first
+func groupByRule(findings []Finding) []*ruleFindings {
+	var groups []*ruleFindings
+	byRule := map[string]*ruleFindings{}
+	for _, finding := range findings {
+		name := finding.Rule.Name
+		if name == "" {
+			name = finding.Code
+		}
+		group, ok := byRule[name]
+		if !ok {
+			group = &ruleFindings{Rule: name, Severity: finding.Severity}
+			byRule[name] = group
+			groups = append(groups, group)
+		}
+		group.Severity = min(group.Severity, finding.Severity)
+		group.Findings = append(group.Findings, finding)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Severity != groups[j].Severity {
+			return groups[i].Severity < groups[j].Severity
+		}
+		return len(groups[i].Findings) > len(groups[j].Findings)
+	})
+	return groups
+}
+
+// stepSummary renders the report as the Markdown of the workflow run page:
+// the counts by severity, a table of the rules with findings and the findings
+// of each rule with their snippets in collapsible sections
+func stepSummary(config *Configuration, report *analysisReport) string {
+	findings := report.Findings
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "## %s\n\n%s\n\n", config.text("report.title"), verdictMarkdown(report.Verdict))
+
+	counts := map[string]int{}
+	for _, finding := range findings {
+		counts[severityName(finding.Severity)]++
+	}
+	fmt.Fprintf(&summary, "| Severity | Findings |\n|---|---:|\n")
+	for _, level := range []int{0, 1, 2} {
+		style := config.severityStyle(level)
+		fmt.Fprintf(&summary, "| %s %s | %d |\n", style.Icon, style.Label, counts[severityName(level)])
+	}
+	if len(report.Excluded) > 0 {
+		fmt.Fprintf(&summary, "| Excluded | %d |\n", len(report.Excluded))
+	}
+	fmt.Fprintf(&summary, "\n_Run started %s_\n", formatTimestamp(report.Started))
+	if report.ReportURL != "" {
+		fmt.Fprintf(&summary, "\n[Full report](%s)\n", report.ReportURL)
+	}
+	if len(report.Approvals) > 0 {
+		fmt.Fprintf(&summary, "\n### Approved operations\n\n| Operation | File | Approved by | Tickets | Findings |\n|---|---|---|---|---:|\n")
+		for _, approval := range report.Approvals {
+			approvedBy := strings.Join(approval.ApprovedBy, ", ")
+			if !approval.valid() {
+				approvedBy = config.text("report.approval_invalid", strings.Join(approval.Problems, "; "))
+			}
+			fmt.Fprintf(&summary, "| `%s %s` | %s | %s | %s | %d |\n", strings.ToUpper(approval.Method), approval.Path,
+				markdownCell(approval.File), markdownCell(approvedBy), markdownCell(strings.Join(approval.Tickets, ", ")), approval.Findings)
+		}
+	}
+	if len(report.ReportingErrors) > 0 {
+		fmt.Fprintf(&summary, "\n### %s\n\n| Integration | Error |\n|---|---|\n", config.text("report.reporting_errors"))
+		for _, failure := range report.ReportingErrors {
+			fmt.Fprintf(&summary, "| `%s` | %s |\n", failure.Integration, markdownCell(failure.Error))
+		}
+	}
+	if len(findings) == 0 {
+		return summary.String()
+	}
+
+	groups := groupByRule(findings)
+	fmt.Fprintf(&summary, "\n### Findings by rule\n\n| Rule | Severity | Findings |\n|---|---|---:|\n")
+	for _, group := range groups {
+		style := config.severityStyle(group.Severity)
+		fmt.Fprintf(&summary, "| %s | %s %s | %d |\n", config.ruleMarkdown(group.Rule), style.Icon, style.Label, len(group.Findings))
+	}
+	if tags := tagRollup(findings); len(tags) > 0 {
+		errorStyle, warningStyle, infoStyle := config.severityStyle(0), config.severityStyle(1), config.severityStyle(2)
+		fmt.Fprintf(&summary, "\n### Findings by tag\n\n| Tag | %s %s | %s %s | %s %s | Findings |\n|---|---:|---:|---:|---:|\n",
+			errorStyle.Icon, errorStyle.Label, warningStyle.Icon, warningStyle.Label, infoStyle.Icon, infoStyle.Label)
+		for _, tc := range tags {
+			fmt.Fprintf(&summary, "| %s | %d | %d | %d | %d |\n", markdownCell(tc.Tag), tc.Errors, tc.Warnings, tc.Info, tc.Total)
+		}
+	}
+	summary.WriteString("\n")
+
+	fileLines := map[string][]string{}
+	shown := 0
+	for _, group := range groups {
+		style := config.severityStyle(group.Severity)
+		fmt.Fprintf(&summary, "#### %s %s\n\n", style.Icon, config.ruleMarkdown(group.Rule))
+		if rule := report.Ruleset.Rule(group.Rule); rule != nil {
+			if rule.Description != "" {
+				fmt.Fprintf(&summary, "%s\n\n", rule.Description)
+			}
+			if rule.Remediation != "" {
+				fmt.Fprintf(&summary, "%s\n\n", config.text("report.remediation", rule.Remediation))
+			}
+		}
+		for _, finding := range group.Findings {
+			if shown == maxStepSummaryFindings {
+				fmt.Fprintf(&summary, "_… and %d more findings in the job log_\n", len(findings)-shown)
+				return summary.String()
+			}
+			shown++
+			if _, ok := fileLines[finding.File]; !ok {
+				content, _ := readSpecFile(finding.File)
+				fileLines[finding.File] = strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+			}
+			writeSummaryFinding(&summary, config, finding, fileLines[finding.File])
+		}
+	}
+	return summary.String()
+}
+
+// writeSummaryFinding writes a finding of the step summary, with its snippet
+// in a collapsible section
+func writeSummaryFinding(summary *strings.Builder, config *Configuration, finding Finding, lines []string) {
+	location := fmt.Sprintf("%s:%d", repoPath(finding.File), finding.Range.Start.Line)
+	if finding.Permalink != "" {
+		location = fmt.Sprintf("<a href=%q>%s</a>", finding.Permalink, location)
+	}
+	fmt.Fprintf(summary, "<details><summary>%s %s — <code>%s</code></summary>\n\n",
+		config.severityStyle(finding.Severity).Icon, htmlEscaper.Replace(finding.Message), location)
+	if snippet := snippetLines(config, lines, finding.Range); len(snippet) > 0 {
+		fmt.Fprintf(summary, "```%s\n", strings.TrimPrefix(filepath.Ext(finding.File), "."))
+		for _, line := range snippet {
+			if line.Number == 0 {
+				fmt.Fprintf(summary, "%s\n", line.Text)
+				continue
+			}
+			fmt.Fprintf(summary, "%4d | %s\n", line.Number, line.Text)
+		}
+		fmt.Fprintf(summary, "```\n\n")
+	}
+	fmt.Fprintf(summary, "%s\n</details>\n\n", config.text("report.fingerprint", finding.Fingerprint()))
+}
+
+// htmlEscaper escapes text inside the HTML of the step summary
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// writeStepSummary appends the report to the summary of the workflow step
+func writeStepSummary(config *Configuration, report *analysisReport) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(stepSummary(config, report)); err != nil {
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	return nil
+}
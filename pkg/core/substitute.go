@@ -0,0 +1,41 @@
+package core
+
+import (
+	"os"
+	"regexp"
+)
+
+// envPlaceholder matches a ${VAR} placeholder in a spec
+var envPlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// substituteEnv replaces ${VAR} placeholders for the allowed variables with their
+// values from the environment. Placeholders for other variables are left as they
+// are, as are allowed variables that aren't set, which are returned as missing.
+func substituteEnv(content string, allowed []string) (string, []string) {
+	if len(allowed) == 0 {
+		return content, nil
+	}
+	allow := map[string]bool{}
+	for _, name := range allowed {
+		allow[name] = true
+	}
+
+	var missing []string
+	reported := map[string]bool{}
+	substituted := envPlaceholder.ReplaceAllStringFunc(content, func(placeholder string) string {
+		name := envPlaceholder.FindStringSubmatch(placeholder)[1]
+		if !allow[name] {
+			return placeholder
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			if !reported[name] {
+				missing = append(missing, name)
+				reported[name] = true
+			}
+			return placeholder
+		}
+		return value
+	})
+	return substituted, missing
+}
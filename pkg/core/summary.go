@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Score penalties per finding, the score starts at 100 and doesn't go below 0
+const (
+	scoreErrorPenalty   = 10
+	scoreWarningPenalty = 3
+	scoreInfoPenalty    = 1
+)
+
+// runSummary is the JSON schema of the summary output
+type runSummary struct {
+	Verdict  string `json:"verdict"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+	Info     int    `json:"info"`
+	Total    int    `json:"total"`
+	Excluded int    `json:"excluded"`
+	Score    int    `json:"score"`
+	// Run timestamps in the configured timezone
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// ruleCount is the number of findings of a rule
+type ruleCount struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+}
+
+// runResults is the JSON schema of the results output
+type runResults struct {
+	Rules []ruleCount `json:"rules"`
+	Tags  []tagCount  `json:"tags"`
+}
+
+// governanceScore rates a run from 100 (no findings) down to 0
+func governanceScore(errors, warnings, info int) int {
+	score := 100 - errors*scoreErrorPenalty - warnings*scoreWarningPenalty - info*scoreInfoPenalty
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// summarize builds the summary output of a run
+func summarize(result *RunResult) runSummary {
+	info := result.Total - result.Errors - result.Warnings
+	return runSummary{
+		Verdict:  result.Verdict,
+		Errors:   result.Errors,
+		Warnings: result.Warnings,
+		Info:     info,
+		Total:    result.Total,
+		Excluded: result.Excluded,
+		Score:    governanceScore(result.Errors, result.Warnings, info),
+
+		StartedAt:  result.StartedAt,
+		FinishedAt: result.FinishedAt,
+	}
+}
+
+// countRules builds the results output, rules with the most findings first,
+// and the findings per OAS tag
+func countRules(findings []Finding) runResults {
+	counts := map[string]*ruleCount{}
+	for _, finding := range findings {
+		name := finding.Rule.Name
+		if name == "" {
+			name = finding.Code
+		}
+		count, ok := counts[name]
+		if !ok {
+			count = &ruleCount{Rule: name, Severity: strings.ToLower(severityName(finding.Severity))}
+			counts[name] = count
+		}
+		count.Count++
+	}
+
+	results := runResults{Rules: []ruleCount{}, Tags: tagRollup(findings)}
+	for _, count := range counts {
+		results.Rules = append(results.Rules, *count)
+	}
+	sort.Slice(results.Rules, func(i, j int) bool {
+		if results.Rules[i].Count != results.Rules[j].Count {
+			return results.Rules[i].Count > results.Rules[j].Count
+		}
+		return results.Rules[i].Rule < results.Rules[j].Rule
+	})
+	return results
+}
+
+// setSummaryOutputs sets the summary and results outputs as compact JSON
+func setSummaryOutputs(config *Configuration, report *analysisReport, result *RunResult) {
+	if summary, err := json.Marshal(summarize(result)); err == nil {
+		setOutput(config, "summary", string(summary))
+	}
+	if results, err := json.Marshal(countRules(report.Findings)); err == nil {
+		setOutput(config, "results", string(results))
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// tagCount is the number of findings on the operations with an OAS tag
+type tagCount struct {
+	Tag      string `json:"tag"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+	Info     int    `json:"info"`
+	Total    int    `json:"total"`
+}
+
+// operationTags returns the OAS tags of the operation a finding path is on.
+// Paths on a path item have the tags of all its operations, and paths outside
+// the operations, e.g. on shared components, have none.
+func operationTags(path []string, doc specDocument) []string {
+	if len(path) < 2 || (path[0] != "paths" && path[0] != "webhooks") {
+		return nil
+	}
+	pathItem, _ := doc.object(path[0])[path[1]].(map[string]interface{})
+	methods := httpMethods
+	if len(path) >= 3 && slices.Contains(httpMethods, strings.ToLower(path[2])) {
+		methods = []string{strings.ToLower(path[2])}
+	}
+	var tags []string
+	for _, method := range methods {
+		operation, _ := pathItem[method].(map[string]interface{})
+		operationTags, _ := operation["tags"].([]interface{})
+		for _, tag := range operationTags {
+			if name, ok := tag.(string); ok && !slices.Contains(tags, name) {
+				tags = append(tags, name)
+			}
+		}
+	}
+	return tags
+}
+
+// tagFindings sets the tags of the findings from the operations they're on.
+// The docs are the parsed documents of the spec file by index.
+func tagFindings(findings []Finding, docs map[int]specDocument) {
+	for i := range findings {
+		findings[i].Tags = operationTags(findings[i].Path, docs[findings[i].Document])
+	}
+}
+
+// tagRollup counts the findings per OAS tag, the tags with the most errors and
+// then the most findings first. Findings on operations with several tags count
+// towards each of them, untagged findings towards none.
+func tagRollup(findings []Finding) []tagCount {
+	counts := map[string]*tagCount{}
+	for _, finding := range findings {
+		for _, tag := range finding.Tags {
+			count, ok := counts[tag]
+			if !ok {
+				count = &tagCount{Tag: tag}
+				counts[tag] = count
+			}
+			switch finding.Severity {
+			case 0:
+				count.Errors++
+			case 1:
+				count.Warnings++
+			default:
+				count.Info++
+			}
+			count.Total++
+		}
+	}
+
+	rollup := make([]tagCount, 0, len(counts))
+	for _, count := range counts {
+		rollup = append(rollup, *count)
+	}
+	sort.Slice(rollup, func(i, j int) bool {
+		if rollup[i].Errors != rollup[j].Errors {
+			return rollup[i].Errors > rollup[j].Errors
+		}
+		if rollup[i].Total != rollup[j].Total {
+			return rollup[i].Total > rollup[j].Total
+		}
+		return rollup[i].Tag < rollup[j].Tag
+	})
+	return rollup
+}
+
+// printTagRollup prints the tag section of the console report
+func printTagRollup(w io.Writer, config *Configuration, rollup []tagCount) {
+	if len(rollup) == 0 {
+		return
+	}
+	printHeading(w, config.text("report.tags"))
+	errorStyle, warningStyle, infoStyle := config.severityStyle(0), config.severityStyle(1), config.severityStyle(2)
+	for _, tc := range rollup {
+		fmt.Fprintf(w, "    %s: %s %d, %s %d, %s %d\n", tc.Tag,
+			errorStyle.Icon, tc.Errors, warningStyle.Icon, tc.Warnings, infoStyle.Icon, tc.Info)
+	}
+}
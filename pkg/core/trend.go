@@ -0,0 +1,139 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// historyService reads the run history from the governance service, which
+// records the runs itself, rather than from an object store or a local file
+const historyService = "service"
+
+// historyRetention is how long runs are kept in an object store or local history
+const historyRetention = 90 * 24 * time.Hour
+
+// defaultTrendWindow is the time window trend gates look back over
+const defaultTrendWindow = 7 * 24 * time.Hour
+
+// Counts a trend gate can hold steady
+const (
+	trendErrors   = "errors"
+	trendWarnings = "warnings"
+	trendTotal    = "total"
+)
+
+// trendMetrics are the known trend gate counts
+var trendMetrics = []string{trendErrors, trendWarnings, trendTotal}
+
+// trendBranch returns the branch whose history the run is compared against
+func trendBranch(config *Configuration, ciContext map[string]string) string {
+	if config.TrendBranch != "" {
+		return config.TrendBranch
+	}
+	if ciContext["default_branch"] != "" {
+		return ciContext["default_branch"]
+	}
+	return "main"
+}
+
+// checkTrend compares the run against the earliest run on the tracked branch
+// within the trend window and fails when a gated count increased, e.g. when
+// there are more errors than a week ago on main. Findings already reported by
+// earlier runs are marked with when they were first seen. Runs on the tracked
+// branch are added to an object store or local history. History failures are
+// logged but never fail the run.
+func checkTrend(ctx context.Context, config *Configuration, ciContext map[string]string, client *integrations.GovernanceClient, report *analysisReport, result *RunResult, logger *zap.Logger) error {
+	if config.History == "" {
+		return nil
+	}
+	branch := trendBranch(config, ciContext)
+	since := result.StartedAt.Add(-config.TrendWindow)
+
+	// Findings can persist for longer than the window
+	history, err := loadHistory(ctx, config, client, ciContext["repository"], branch, result.StartedAt.Add(-historyRetention))
+	if err != nil {
+		logger.Warn("Failed to fetch the run history, skipping the trend gate", zap.Error(err))
+		return nil
+	}
+
+	current := integrations.HistoryEntry{
+		Branch:       ciContext["branch"],
+		Commit:       ciContext["commit"],
+		Errors:       result.Errors,
+		Warnings:     result.Warnings,
+		Total:        result.Total,
+		Time:         result.StartedAt.UTC(),
+		Fingerprints: fingerprints(report.Findings),
+	}
+	if current.Branch == branch && !integrations.IsPullRequest(ciContext) {
+		recordHistory(ctx, config, history, current, logger)
+	}
+
+	persisting := markPersistingFindings(report.Findings, history, branch, since)
+	setOutput(config, "persisting_count", fmt.Sprintf("%d", persisting))
+
+	baseline := trendBaseline(history, branch, since)
+	if baseline == nil {
+		logger.Info("No runs in the trend window to compare against", zap.String("branch", branch),
+			zap.String("window", formatWindow(config.TrendWindow)))
+		return nil
+	}
+	setOutput(config, "trend_error_delta", fmt.Sprintf("%d", current.Errors-baseline.Errors))
+	setOutput(config, "trend_warning_delta", fmt.Sprintf("%d", current.Warnings-baseline.Warnings))
+	logger.Info("Compared against the run history", zap.String("branch", branch),
+		zap.String("baseline_commit", baseline.Commit), zap.Time("baseline_time", baseline.Time),
+		zap.Int("error_delta", current.Errors-baseline.Errors), zap.Int("warning_delta", current.Warnings-baseline.Warnings),
+		zap.Int("persisting", persisting))
+
+	var increased []string
+	for _, metric := range config.TrendGate {
+		before, now := trendCount(*baseline, metric), trendCount(current, metric)
+		if now > before {
+			increased = append(increased, fmt.Sprintf("%s increased from %d to %d", metric, before, now))
+		}
+	}
+	if len(increased) > 0 {
+		return fmt.Errorf("%s over the last %s on %s", strings.Join(increased, ", "), formatWindow(config.TrendWindow), branch)
+	}
+	return nil
+}
+
+// trendBaseline returns the earliest run on the branch within the window, or
+// nil if there is none
+func trendBaseline(history []integrations.HistoryEntry, branch string, since time.Time) *integrations.HistoryEntry {
+	var baseline *integrations.HistoryEntry
+	for i, entry := range history {
+		if entry.Branch != branch || entry.Time.Before(since) {
+			continue
+		}
+		if baseline == nil || entry.Time.Before(baseline.Time) {
+			baseline = &history[i]
+		}
+	}
+	return baseline
+}
+
+// trendCount returns the gated count of a run
+func trendCount(entry integrations.HistoryEntry, metric string) int {
+	switch metric {
+	case trendErrors:
+		return entry.Errors
+	case trendWarnings:
+		return entry.Warnings
+	default:
+		return entry.Total
+	}
+}
+
+// formatWindow formats a trend window, in days when it's a whole number of them
+func formatWindow(window time.Duration) string {
+	if window > 0 && window%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", window/(24*time.Hour))
+	}
+	return window.String()
+}
@@ -0,0 +1,260 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// Layout of the terminal UI
+const (
+	tuiListWidth   = 44
+	tuiPaneHeight  = 20
+	tuiContextRows = 5
+	tuiMinWidth    = 80
+)
+
+// tuiItem is a finding listed in the terminal UI
+type tuiItem struct {
+	Finding
+	Fixed bool // The finding was gone on the latest re-check
+}
+
+// tui is the state of the interactive terminal UI
+type tui struct {
+	ctx     context.Context
+	config  *Configuration
+	client  *integrations.GovernanceClient
+	target  specTarget
+	out     io.Writer
+	width   int
+	items   []tuiItem
+	current int
+	lines   []string
+	status  string
+}
+
+// RunTUI runs an interactive terminal UI listing the findings of a spec next to
+// the spec itself. Re-checking marks the findings that were fixed in the meantime.
+func RunTUI(logger *zap.Logger, specPath string, in io.Reader, out io.Writer) error {
+	config, err := getConfiguration()
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	config.APIPath = specPath
+	config.Manifest = ""
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Logging would scroll the screen, only errors are returned
+	quiet := zap.NewNop()
+	ctx := context.Background()
+	client, _, err := connectGovernance(ctx, config, quiet)
+	if err != nil {
+		return err
+	}
+
+	t := &tui{
+		ctx:    ctx,
+		config: config,
+		client: client,
+		target: specTarget{Path: specPath},
+		out:    out,
+		width:  terminalWidth(),
+	}
+	if err := t.check(quiet); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(in)
+	for {
+		t.render()
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		command := strings.TrimSpace(scanner.Text())
+		switch {
+		case command == "q":
+			return nil
+		case command == "n" || command == "":
+			t.move(1)
+		case command == "p":
+			t.move(-1)
+		case command == "r":
+			if err := t.check(quiet); err != nil {
+				t.status = "Re-check failed: " + err.Error()
+			}
+		default:
+			if n, err := strconv.Atoi(command); err == nil && n >= 1 && n <= len(t.items) {
+				t.current = n - 1
+			} else {
+				t.status = fmt.Sprintf("Unknown command %q", command)
+			}
+		}
+	}
+}
+
+// check analyzes the spec, marking listed findings that are gone as fixed and
+// appending new ones
+func (t *tui) check(logger *zap.Logger) error {
+	findings, content, err := analyzeTarget(t.ctx, t.config, t.client, t.target, logger)
+	if err != nil {
+		return err
+	}
+	t.lines = strings.Split(strings.ReplaceAll(content, "\t", "  "), "\n")
+
+	current := map[string]Finding{}
+	for _, finding := range findings {
+		current[finding.Fingerprint()] = finding
+	}
+
+	fixed := 0
+	seen := map[string]bool{}
+	for i := range t.items {
+		fingerprint := t.items[i].Fingerprint()
+		seen[fingerprint] = true
+		if finding, ok := current[fingerprint]; ok {
+			// Keep the finding but take its location from the edited spec
+			t.items[i].Finding = finding
+			t.items[i].Fixed = false
+		} else {
+			t.items[i].Fixed = true
+			fixed++
+		}
+	}
+	added := 0
+	for _, finding := range findings {
+		if !seen[finding.Fingerprint()] {
+			t.items = append(t.items, tuiItem{Finding: finding})
+			added++
+		}
+	}
+	t.status = fmt.Sprintf("Checked %s: %d open, %d fixed, %d new", t.target.Path, len(findings), fixed, added)
+	return nil
+}
+
+// move selects the next or previous finding
+func (t *tui) move(delta int) {
+	if len(t.items) == 0 {
+		return
+	}
+	t.current = (t.current + delta + len(t.items)) % len(t.items)
+}
+
+// render draws the finding list, the spec pane and the details of the selected finding
+func (t *tui) render() {
+	var screen strings.Builder
+	screen.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&screen, "Governance findings: %s\n\n", t.target.Path)
+
+	list := t.listRows()
+	spec := t.specRows()
+	specWidth := t.width - tuiListWidth - 3
+	for row := 0; row < tuiPaneHeight; row++ {
+		fmt.Fprintf(&screen, "%s │ %s\n", fit(rowAt(list, row), tuiListWidth), fit(rowAt(spec, row), specWidth))
+	}
+
+	screen.WriteString(strings.Repeat("─", t.width) + "\n")
+	if len(t.items) > 0 {
+		item := t.items[t.current]
+		style := t.config.severityStyle(item.Severity)
+		state := "open"
+		if item.Fixed {
+			state = "fixed"
+		}
+		fmt.Fprintf(&screen, "%d/%d %s %s (%s)\n", t.current+1, len(t.items), style.colorize(style.Label), item.Rule.Name, state)
+		fmt.Fprintf(&screen, "%s\n", item.Message)
+		fmt.Fprintf(&screen, "Path: %s\n", strings.Join(item.Path, "."))
+	} else {
+		screen.WriteString("No governance issues found\n")
+	}
+	fmt.Fprintf(&screen, "\n%s\n[n]ext  [p]revious  <number> jump  [r]e-check  [q]uit > ", t.status)
+	fmt.Fprint(t.out, screen.String())
+}
+
+// listRows renders the finding list, scrolled so the selected finding is visible
+func (t *tui) listRows() []string {
+	first := 0
+	if t.current >= tuiPaneHeight {
+		first = t.current - tuiPaneHeight + 1
+	}
+
+	var rows []string
+	for i := first; i < len(t.items) && len(rows) < tuiPaneHeight; i++ {
+		item := t.items[i]
+		cursor := " "
+		if i == t.current {
+			cursor = ">"
+		}
+		mark := "[ ]"
+		if item.Fixed {
+			mark = "[x]"
+		}
+		label := t.config.severityStyle(item.Severity).Label
+		rows = append(rows, fmt.Sprintf("%s%3d %s %-7.7s %s", cursor, i+1, mark, label, item.Rule.Name))
+	}
+	return rows
+}
+
+// specRows renders the spec around the selected finding, marking its lines
+func (t *tui) specRows() []string {
+	start, end := 0, 0
+	if len(t.items) > 0 {
+		location := t.items[t.current].Location
+		start, end = location.StartLine, location.StartLine
+		if location.EndLine-location.StartLine < tuiPaneHeight-tuiContextRows {
+			end = location.EndLine
+		}
+	}
+
+	first := start - tuiContextRows
+	if first < 1 {
+		first = 1
+	}
+	var rows []string
+	for line := first; line <= len(t.lines) && len(rows) < tuiPaneHeight; line++ {
+		marker := " "
+		if line >= start && line <= end && start > 0 {
+			marker = ">"
+		}
+		rows = append(rows, fmt.Sprintf("%s%4d  %s", marker, line, t.lines[line-1]))
+	}
+	return rows
+}
+
+// rowAt returns the row at index i, or "" past the end
+func rowAt(rows []string, i int) string {
+	if i < len(rows) {
+		return rows[i]
+	}
+	return ""
+}
+
+// fit truncates or pads text to exactly width runes
+func fit(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if n := utf8.RuneCountInString(text); n <= width {
+		return text + strings.Repeat(" ", width-n)
+	}
+	runes := []rune(text)
+	return string(runes[:width-1]) + "…"
+}
+
+// terminalWidth returns the terminal width from COLUMNS, with a sensible minimum
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns >= tuiMinWidth {
+		return columns
+	}
+	return 120
+}
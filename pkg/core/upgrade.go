@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+	"go.uber.org/zap"
+)
+
+// findingDelta is the change in findings between two analyses of the specs,
+// e.g. from the pinned to the latest ruleset version
+type findingDelta struct {
+	Added    []Finding // Findings only the second analysis reports
+	Resolved []Finding // Findings only the first analysis reports
+}
+
+// diffFindings compares the findings of two analyses by fingerprint
+func diffFindings(before, after []Finding) findingDelta {
+	inBefore := map[string]bool{}
+	for _, finding := range before {
+		inBefore[finding.Fingerprint()] = true
+	}
+	inAfter := map[string]bool{}
+	var delta findingDelta
+	for _, finding := range after {
+		inAfter[finding.Fingerprint()] = true
+		if !inBefore[finding.Fingerprint()] {
+			delta.Added = append(delta.Added, finding)
+		}
+	}
+	for _, finding := range before {
+		if !inAfter[finding.Fingerprint()] {
+			delta.Resolved = append(delta.Resolved, finding)
+		}
+	}
+	return delta
+}
+
+// withoutExcluded drops the findings the main analysis excluded, e.g. as
+// exempted, matching them by fingerprint
+func withoutExcluded(findings, excluded []Finding) []Finding {
+	fingerprints := map[string]bool{}
+	for _, finding := range excluded {
+		fingerprints[finding.Fingerprint()] = true
+	}
+	var kept []Finding
+	for _, finding := range findings {
+		if !fingerprints[finding.Fingerprint()] {
+			kept = append(kept, finding)
+		}
+	}
+	return kept
+}
+
+// startUpgradePreview evaluates the specs against the latest ruleset version in
+// the background, while they're analyzed against the pinned one
+func startUpgradePreview(ctx context.Context, config *Configuration, client *integrations.GovernanceClient, targets []specTarget, logger *zap.Logger) *concurrentAnalysis {
+	latestConfig := *config
+	latestConfig.RulesetVersion = ""
+	return startAnalysis(ctx, &latestConfig, client, targets, logger)
+}
+
+// previewUpgrade compares the findings of the latest ruleset version with those
+// of the pinned version. Findings the pinned analysis excludes, e.g. as
+// exempted, aren't new. The preview never affects the verdict, failures are
+// logged and reported as a nil delta.
+func previewUpgrade(config *Configuration, client *integrations.GovernanceClient, preview *concurrentAnalysis, report *analysisReport, logger *zap.Logger) *findingDelta {
+	latest, err := preview.wait(client)
+	if err != nil {
+		logger.Warn("Failed to evaluate the latest ruleset version, skipping the upgrade preview", zap.Error(err))
+		return nil
+	}
+
+	delta := diffFindings(report.Findings, withoutExcluded(latest, report.Excluded))
+	setOutput(config, "upgrade_added_count", strconv.Itoa(len(delta.Added)))
+	setOutput(config, "upgrade_resolved_count", strconv.Itoa(len(delta.Resolved)))
+	return &delta
+}
+
+// printFindingDelta prints a section of the console report listing the
+// findings a delta adds and resolves
+func printFindingDelta(w io.Writer, config *Configuration, heading string, delta *findingDelta) {
+	fmt.Fprintln(w)
+	printHeading(w, heading)
+	if len(delta.Added) == 0 && len(delta.Resolved) == 0 {
+		fmt.Fprintln(w, "    "+config.text("report.upgrade_none"))
+		return
+	}
+	fmt.Fprintln(w, "    "+config.text("report.upgrade_counts", len(delta.Added), len(delta.Resolved)))
+	for _, finding := range delta.Added {
+		style := config.severityStyle(finding.Severity)
+		fmt.Fprintf(w, "    + [%s] %s: %s (%s)\n", style.Label, finding.Rule.Name, finding.Message, finding.File)
+	}
+	for _, finding := range delta.Resolved {
+		style := config.severityStyle(finding.Severity)
+		fmt.Fprintf(w, "    - [%s] %s: %s (%s)\n", style.Label, finding.Rule.Name, finding.Message, finding.File)
+	}
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/TykTechnologies/governance-action/pkg/integrations"
+)
+
+// testFinding returns a finding of a rule on a path of openapi.yaml
+func testFinding(rule string, path ...string) Finding {
+	return Finding{
+		LintResult: integrations.LintResult{Path: path, Message: rule + " violated", Rule: integrations.RuleReference{Name: rule}},
+		File:       "openapi.yaml",
+	}
+}
+
+// sameFindings compares findings by fingerprint, in any order
+func sameFindings(t *testing.T, label string, got, want []Finding) {
+	t.Helper()
+	gotPrints, wantPrints := fingerprints(got), fingerprints(want)
+	if len(gotPrints) != len(wantPrints) {
+		t.Errorf("%s: got %d findings %v, want %d %v", label, len(gotPrints), gotPrints, len(wantPrints), wantPrints)
+		return
+	}
+	for i := range gotPrints {
+		if gotPrints[i] != wantPrints[i] {
+			t.Errorf("%s[%d]: got %s, want %s", label, i, gotPrints[i], wantPrints[i])
+		}
+	}
+}
+
+func TestDiffFindings(t *testing.T) {
+	rateLimit := testFinding("owasp-rate-limit", "paths", "/users", "get")
+	errors401 := testFinding("owasp-define-error-responses-401", "paths", "/users", "get")
+	otherPath := testFinding("owasp-rate-limit", "paths", "/orders", "get")
+	moved := rateLimit
+	moved.Range.Start.Line = 42
+
+	tests := []struct {
+		name          string
+		before, after []Finding
+		added         []Finding
+		resolved      []Finding
+	}{
+		{name: "no findings"},
+		{name: "unchanged", before: []Finding{rateLimit}, after: []Finding{rateLimit}},
+		{name: "moved lines aren't a change", before: []Finding{rateLimit}, after: []Finding{moved}},
+		{name: "added", before: []Finding{rateLimit}, after: []Finding{rateLimit, errors401}, added: []Finding{errors401}},
+		{name: "resolved", before: []Finding{rateLimit, otherPath}, after: []Finding{otherPath}, resolved: []Finding{rateLimit}},
+		{name: "both", before: []Finding{rateLimit}, after: []Finding{errors401}, added: []Finding{errors401}, resolved: []Finding{rateLimit}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta := diffFindings(tt.before, tt.after)
+			sameFindings(t, "added", delta.Added, tt.added)
+			sameFindings(t, "resolved", delta.Resolved, tt.resolved)
+		})
+	}
+}
+
+func TestPreviewUpgradeIgnoresExcludedFindings(t *testing.T) {
+	pinned := testFinding("owasp-define-error-responses-401", "paths", "/users", "get")
+	exempted := testFinding("owasp-rate-limit", "paths", "/users", "get", "responses", "200")
+	added := testFinding("owasp-security-hosts-https-oas3", "servers", "0", "url")
+
+	tests := []struct {
+		name     string
+		report   *analysisReport
+		latest   []Finding
+		added    []Finding
+		resolved []Finding
+	}{
+		{
+			name:   "exempted on the pinned version",
+			report: &analysisReport{Findings: []Finding{pinned}, Excluded: []Finding{exempted}},
+			latest: []Finding{pinned, exempted},
+		},
+		{
+			name:   "new on the latest version",
+			report: &analysisReport{Findings: []Finding{pinned}, Excluded: []Finding{exempted}},
+			latest: []Finding{pinned, exempted, added},
+			added:  []Finding{added},
+		},
+		{
+			name:     "resolved on the latest version",
+			report:   &analysisReport{Findings: []Finding{pinned, exempted}},
+			latest:   []Finding{exempted},
+			resolved: []Finding{pinned},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview := &concurrentAnalysis{done: make(chan struct{}), findings: tt.latest}
+			close(preview.done)
+			delta := previewUpgrade(&Configuration{Local: true}, nil, preview, tt.report, nil)
+			if delta == nil {
+				t.Fatal("expected a delta")
+			}
+			sameFindings(t, "added", delta.Added, tt.added)
+			sameFindings(t, "resolved", delta.Resolved, tt.resolved)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidateOptions configure a local structural check of specs
+type ValidateOptions struct {
+	Files []string // Spec files, globs or git specs to check
+}
+
+// SpecProblem is a syntax or structural problem of a spec
+type SpecProblem struct {
+	File     string
+	Document int // 1-based index in a multi-document file, 0 for single-document files
+	Line     int // 0 when the problem isn't located, e.g. a structural one
+	Message  string
+}
+
+func (p SpecProblem) String() string {
+	location := p.File
+	if p.Line > 0 {
+		location += ":" + strconv.Itoa(p.Line)
+	}
+	if p.Document > 0 {
+		location += fmt.Sprintf(" (document %d)", p.Document)
+	}
+	return location + ": " + p.Message
+}
+
+// ValidateResult are the problems found in the checked specs
+type ValidateResult struct {
+	Specs    int
+	Problems []SpecProblem
+}
+
+// Summary returns the closing line of a validate run for the terminal
+func (r *ValidateResult) Summary() string {
+	specs := fmt.Sprintf("%d specs", r.Specs)
+	if r.Specs == 1 {
+		specs = "1 spec"
+	}
+	switch len(r.Problems) {
+	case 0:
+		return fmt.Sprintf("✅ No problems in %s", specs)
+	case 1:
+		return fmt.Sprintf("❌ 1 problem in %s", specs)
+	}
+	return fmt.Sprintf("❌ %d problems in %s", len(r.Problems), specs)
+}
+
+// ValidateSpecs checks specs locally, before anything is sent to the
+// governance service: the YAML or JSON syntax and duplicate keys, then the
+// structure of OpenAPI 3.0 and Swagger 2.0 documents with kin-openapi, which
+// loads the document, resolves its $refs and validates it against the
+// specification. kin-openapi doesn't support OpenAPI 3.1 yet, so 3.1 and
+// AsyncAPI documents are only checked for their syntax.
+func ValidateSpecs(options ValidateOptions) (*ValidateResult, error) {
+	maxSize, err := getSizeInput("MAX_SPEC_SIZE", defaultMaxSpecSize)
+	if err != nil {
+		return nil, err
+	}
+	files, err := expandSpecPaths(strings.Join(options.Files, ","))
+	if err != nil {
+		return nil, err
+	}
+	result := &ValidateResult{Specs: len(files)}
+	for _, file := range files {
+		content, err := readOASFile(file, maxSize)
+		if err != nil {
+			result.Problems = append(result.Problems, SpecProblem{File: file, Message: err.Error()})
+			continue
+		}
+		for _, document := range splitDocuments(content) {
+			if err := validateSpecDocument(file, document); err != nil {
+				problem := SpecProblem{File: file, Document: document.Index, Message: err.Error()}
+				if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+					line, _ := strconv.Atoi(match[1])
+					problem.Line, problem.Message = line+document.Offset, "syntax error: "+match[2]
+				}
+				result.Problems = append(result.Problems, problem)
+			}
+		}
+	}
+	return result, nil
+}
+
+// yamlErrorLine matches the line of a YAML or JSON syntax error
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// validateSpecDocument checks one document of a spec file, returning the
+// first problem found
+func validateSpecDocument(file string, document yamlDocument) error {
+	// Decoding into a map also rejects duplicate keys
+	var raw interface{}
+	if err := yaml.Unmarshal([]byte(document.Content), &raw); err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+			return fmt.Errorf("yaml: %s", typeErr.Errors[0])
+		}
+		return err
+	}
+	if raw == nil {
+		return fmt.Errorf("spec is empty")
+	}
+	doc, err := parseSpec(document.Content)
+	if err != nil {
+		return err
+	}
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = specRefReader(file)
+	location := &url.URL{Path: file}
+	if _, name, ok := parseGitSpec(file); ok {
+		location.Path = name
+	}
+
+	format := doc.format()
+	switch format {
+	case "openapi3", "swagger2":
+	case "openapi31", "asyncapi":
+		return nil
+	default:
+		return fmt.Errorf("spec is neither an OpenAPI nor a Swagger spec: openapi or swagger is required")
+	}
+
+	// kin-openapi decodes the document from JSON, type errors such as a number
+	// for info.version are reported by encoding/json
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	spec := &openapi3.T{}
+	if format == "openapi3" {
+		if err := json.Unmarshal(content, spec); err != nil {
+			return fmt.Errorf("failed to load the spec: %w", err)
+		}
+		if err := loader.ResolveRefsIn(spec, location); err != nil {
+			return fmt.Errorf("failed to load the spec: %w", err)
+		}
+	} else {
+		// Swagger 2.0 is validated as its OpenAPI 3.0 conversion
+		var swagger openapi2.T
+		if err := json.Unmarshal(content, &swagger); err != nil {
+			return fmt.Errorf("failed to load the spec: %w", err)
+		}
+		if spec, err = openapi2conv.ToV3WithLoader(&swagger, loader, location); err != nil {
+			return fmt.Errorf("failed to load the spec: %w", err)
+		}
+		if spec.Paths == nil && swagger.Paths != nil {
+			spec.Paths = openapi3.NewPaths()
+		}
+	}
+	return spec.Validate(context.Background())
+}
+
+// specRefReader reads the files a spec references with $ref from the working
+// tree, or from the ref of a git spec. Remote references aren't followed.
+func specRefReader(file string) openapi3.ReadFromURIFunc {
+	return func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if location.Scheme != "" || location.Host != "" {
+			return nil, fmt.Errorf("remote $ref %s isn't read by validate", location)
+		}
+		if ref, _, ok := parseGitSpec(file); ok {
+			return readSpecFile(gitSpecPath(ref, strings.TrimPrefix(location.Path, "/")))
+		}
+		return readSpecFile(location.Path)
+	}
+}
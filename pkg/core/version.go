@@ -0,0 +1,5 @@
+package core
+
+// Version is the running version, set at build time with
+// -ldflags "-X github.com/TykTechnologies/governance-action/pkg/core.Version=v1.2.3"
+var Version = "dev"
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// workflowCommandData escapes the message of a GitHub workflow command
+var workflowCommandData = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// workflowCommandProperty escapes a property value of a GitHub workflow command
+var workflowCommandProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// workflowAnnotation renders a finding as an ::error, ::warning or ::notice
+// workflow command, which GitHub shows as an inline annotation on the changed
+// files of a pull request
+func workflowAnnotation(finding Finding) string {
+	command := "notice"
+	switch finding.Severity {
+	case 0:
+		command = "error"
+	case 1:
+		command = "warning"
+	}
+	region := findingRegion(finding)
+	properties := []string{"file=" + workflowCommandProperty.Replace(repoPath(finding.File)), fmt.Sprintf("line=%d", region.StartLine)}
+	if region.EndLine > 0 {
+		properties = append(properties, fmt.Sprintf("endLine=%d", region.EndLine))
+	}
+	// Columns only apply to annotations on a single line
+	if region.StartColumn > 0 && (region.EndLine == 0 || region.EndLine == region.StartLine) {
+		properties = append(properties, fmt.Sprintf("col=%d", region.StartColumn))
+		if region.EndColumn > 0 {
+			properties = append(properties, fmt.Sprintf("endColumn=%d", region.EndColumn))
+		}
+	}
+	if name := finding.Rule.Name; name != "" {
+		properties = append(properties, "title="+workflowCommandProperty.Replace(name))
+	}
+	return fmt.Sprintf("::%s %s::%s", command, strings.Join(properties, ","), workflowCommandData.Replace(finding.Message))
+}
+
+// printWorkflowAnnotations annotates the findings with workflow commands
+func printWorkflowAnnotations(w io.Writer, findings []Finding) {
+	for _, finding := range findings {
+		fmt.Fprintln(w, workflowAnnotation(finding))
+	}
+}
@@ -0,0 +1,78 @@
+package integrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// metadataCache stores ruleset metadata on disk so repeated runs can reuse it
+type metadataCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// cachedRuleset is a ruleset cache entry
+type cachedRuleset struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Ruleset   *Ruleset  `json:"ruleset"`
+}
+
+// metadataKey identifies the ruleset metadata a client may see: the service, the
+// ruleset, the tenant and the token, hashed, so a tenant is never served another
+// tenant's metadata and a new token is checked against the service before its
+// metadata is cached
+func (c *GovernanceClient) metadataKey(ruleID string) string {
+	token := sha256.Sum256([]byte(c.authToken))
+	return strings.Join([]string{c.baseURL, ruleID, c.orgID, c.teamID, hex.EncodeToString(token[:])}, "\x00")
+}
+
+// path returns the cache file of a metadata key
+func (m *metadataCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(m.dir, "ruleset-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// load returns the cached ruleset and whether it's still within the TTL
+func (m *metadataCache) load(key string) (*cachedRuleset, bool) {
+	content, err := os.ReadFile(m.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cachedRuleset
+	if err := json.Unmarshal(content, &entry); err != nil || entry.Ruleset == nil {
+		return nil, false
+	}
+	return &entry, time.Since(entry.FetchedAt) < m.ttl
+}
+
+// store writes a ruleset to the cache, replacing the file atomically so
+// concurrent runs never read a partial entry
+func (m *metadataCache) store(key string, ruleset *Ruleset) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	content, err := json.Marshal(cachedRuleset{FetchedAt: time.Now(), Ruleset: ruleset})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(m.dir, "ruleset-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return os.Rename(tmp.Name(), m.path(key))
+}
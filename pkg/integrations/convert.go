@@ -0,0 +1,106 @@
+package integrations
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// specJSON converts spec content to JSON for the analysis request. JSON content
+// is sent as is. YAML is converted node by node instead of through Go values, so
+// constructs JSON Schema 2020-12 (OpenAPI 3.1) relies on survive: dates in
+// const, default and examples stay strings rather than becoming timestamps,
+// and non-string keys such as unquoted response codes become strings.
+func specJSON(content string) (json.RawMessage, error) {
+	if json.Valid([]byte(content)) {
+		return json.RawMessage(content), nil
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		return nil, fmt.Errorf("content is neither valid YAML nor JSON: %w", err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil, fmt.Errorf("content is empty")
+	}
+	value, err := jsonValue(root.Content[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert YAML to JSON: %w", err)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert YAML to JSON: %w", err)
+	}
+	return data, nil
+}
+
+// jsonValue converts a YAML node into a value encoding/json can marshal
+func jsonValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return jsonValue(node.Content[0])
+	case yaml.AliasNode:
+		return jsonValue(node.Alias)
+	case yaml.MappingNode:
+		object := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			// Merge keys pull in the entries of the referenced mappings. Explicit
+			// entries win, and with a sequence of mappings the earlier ones do.
+			if key.Tag == "!!merge" {
+				merged, err := jsonValue(value)
+				if err != nil {
+					return nil, err
+				}
+				sources, ok := merged.([]interface{})
+				if !ok {
+					sources = []interface{}{merged}
+				}
+				for _, source := range sources {
+					entries, ok := source.(map[string]interface{})
+					if !ok {
+						return nil, fmt.Errorf("line %d: merge key must reference a mapping or a sequence of mappings", key.Line)
+					}
+					for name, entry := range entries {
+						if _, set := object[name]; !set {
+							object[name] = entry
+						}
+					}
+				}
+				continue
+			}
+			converted, err := jsonValue(value)
+			if err != nil {
+				return nil, err
+			}
+			object[key.Value] = converted
+		}
+		return object, nil
+	case yaml.SequenceNode:
+		array := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, converted)
+		}
+		return array, nil
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!int", "!!float", "!!bool", "!!null":
+			var value interface{}
+			if err := node.Decode(&value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			return value, nil
+		default:
+			// Strings, timestamps and binary values are kept as written
+			return node.Value, nil
+		}
+	}
+	return nil, fmt.Errorf("line %d: unsupported YAML node", node.Line)
+}
@@ -0,0 +1,64 @@
+package integrations
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSpecJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "JSON is sent as is", content: `{"openapi": "3.1.0"}`, want: `{"openapi":"3.1.0"}`},
+		{name: "scalars", content: "a: 1\nb: 1.5\nc: true\nd: null\ne: text\n", want: `{"a":1,"b":1.5,"c":true,"d":null,"e":"text"}`},
+		{name: "dates stay strings", content: "const: 2024-01-02\n", want: `{"const":"2024-01-02"}`},
+		{name: "response codes become strings", content: "responses:\n  200: {description: ok}\n", want: `{"responses":{"200":{"description":"ok"}}}`},
+		{name: "alias", content: "a: &x {b: 1}\nc: *x\n", want: `{"a":{"b":1},"c":{"b":1}}`},
+		{name: "merge key", content: "a: &x {b: 1, c: 1}\nd:\n  <<: *x\n  c: 2\n", want: `{"a":{"b":1,"c":1},"d":{"b":1,"c":2}}`},
+		{name: "explicit key before the merge key wins", content: "a: &x {b: 1}\nd:\n  b: 2\n  <<: *x\n", want: `{"a":{"b":1},"d":{"b":2}}`},
+		{
+			name:    "merge sequence, earlier mappings win",
+			content: "x: &x {a: 1}\ny: &y {a: 2, b: 2}\nz:\n  <<: [*x, *y]\n  c: 3\n",
+			want:    `{"x":{"a":1},"y":{"a":2,"b":2},"z":{"a":1,"b":2,"c":3}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := specJSON(tt.content)
+			if err != nil {
+				t.Fatalf("specJSON: %v", err)
+			}
+			var gotValue, wantValue interface{}
+			if err := json.Unmarshal(got, &gotValue); err != nil {
+				t.Fatalf("invalid JSON %s: %v", got, err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &wantValue); err != nil {
+				t.Fatalf("invalid expectation: %v", err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecJSONErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty", content: ""},
+		{name: "invalid YAML", content: "a: [unclosed"},
+		{name: "merge of a scalar", content: "a: &x 1\nb:\n  <<: *x\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := specJSON(tt.content); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package integrations
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDebugBody is how much of a request or response body is kept in the debug bundle
+const maxDebugBody = 8 << 10
+
+// redactedHeaders are replaced in the debug bundle as they carry credentials
+var redactedHeaders = []string{"Authorization", "X-Api-Key", "Private-Token", "Job-Token", "Cookie", "Set-Cookie"}
+
+// redactedParamMarkers identify query parameters carrying credentials by name,
+// such as access_token or X-Amz-Signature
+var redactedParamMarkers = []string{"auth", "token", "secret", "password", "key", "sig", "code"}
+
+// slackWebhookHost serves incoming webhooks, whose path is the credential
+const slackWebhookHost = "hooks.slack.com"
+
+// HTTPExchange is a sanitized request and response pair
+type HTTPExchange struct {
+	Time         time.Time           `json:"time"`
+	Method       string              `json:"method"`
+	URL          string              `json:"url"`
+	Request      map[string][]string `json:"requestHeaders"`
+	RequestBody  string              `json:"requestBody,omitempty"`
+	Status       int                 `json:"status,omitempty"`
+	Response     map[string][]string `json:"responseHeaders,omitempty"`
+	ResponseBody string              `json:"responseBody,omitempty"`
+	Error        string              `json:"error,omitempty"`
+	DurationMS   int64               `json:"durationMs"`
+}
+
+// HTTPRecorder captures the HTTP traffic of the integrations for support diagnostics
+type HTTPRecorder struct {
+	mu        sync.Mutex
+	exchanges []HTTPExchange
+}
+
+// debugRecorder captures the traffic of all clients created while it's set
+var debugRecorder *HTTPRecorder
+
+// EnableHTTPDebug captures the traffic of the clients created from now on
+func EnableHTTPDebug() *HTTPRecorder {
+	debugRecorder = &HTTPRecorder{}
+	return debugRecorder
+}
+
+// newHTTPClient creates the HTTP client of an integration, recording its traffic
+// when HTTP debugging is enabled
+func newHTTPClient(timeout time.Duration) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	if debugRecorder != nil {
+		client.Transport = &recordingTransport{base: http.DefaultTransport, recorder: debugRecorder}
+	}
+	return client
+}
+
+// recordingTransport records every round trip with the recorder
+type recordingTransport struct {
+	base     http.RoundTripper
+	recorder *HTTPRecorder
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange := HTTPExchange{
+		Time:    time.Now().UTC(),
+		Method:  req.Method,
+		URL:     sanitizeURL(req.URL),
+		Request: sanitizeHeaders(req.Header),
+	}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		exchange.RequestBody = truncateBody(body)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	exchange.DurationMS = time.Since(exchange.Time).Milliseconds()
+	if err != nil {
+		exchange.Error = err.Error()
+		t.recorder.add(exchange)
+		return nil, err
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	exchange.Status = resp.StatusCode
+	exchange.Response = sanitizeHeaders(resp.Header)
+	exchange.ResponseBody = truncateBody(body)
+	if readErr != nil {
+		exchange.Error = readErr.Error()
+	}
+	t.recorder.add(exchange)
+	return resp, nil
+}
+
+func (r *HTTPRecorder) add(exchange HTTPExchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, exchange)
+}
+
+// WriteBundle writes the captured exchanges and the run metadata to a zip file
+func (r *HTTPRecorder) WriteBundle(path string, metadata map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create debug bundle: %w", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	entries := []struct {
+		name    string
+		content interface{}
+	}{{"run.json", metadata}, {"http.json", r.exchanges}}
+	for _, entry := range entries {
+		name := entry.name
+		data, err := json.MarshalIndent(entry.content, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		w, err := archive.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to write debug bundle: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write debug bundle: %w", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write debug bundle: %w", err)
+	}
+	return nil
+}
+
+// sanitizeHeaders copies headers with the credentials redacted
+func sanitizeHeaders(headers http.Header) map[string][]string {
+	sanitized := headers.Clone()
+	for _, name := range redactedHeaders {
+		if sanitized.Get(name) != "" {
+			sanitized.Set(name, "[REDACTED]")
+		}
+	}
+	return sanitized
+}
+
+// sanitizeURL formats a URL with the credentials in its user info, query
+// string or Slack webhook path redacted
+func sanitizeURL(u *url.URL) string {
+	sanitized := *u
+	if u.User != nil {
+		sanitized.User = url.User("REDACTED")
+	}
+	if sanitized.Host == slackWebhookHost {
+		sanitized.Path, sanitized.RawPath = "/services/[REDACTED]", ""
+	}
+	query := u.Query()
+	redacted := false
+	for name := range query {
+		lower := strings.ToLower(name)
+		for _, marker := range redactedParamMarkers {
+			if strings.Contains(lower, marker) {
+				query.Set(name, "[REDACTED]")
+				redacted = true
+				break
+			}
+		}
+	}
+	if redacted {
+		sanitized.RawQuery = query.Encode()
+	}
+	return sanitized.String()
+}
+
+// truncateBody keeps the start of a body
+func truncateBody(body []byte) string {
+	if len(body) <= maxDebugBody {
+		return string(body)
+	}
+	return fmt.Sprintf("%s... [truncated %d bytes]", body[:maxDebugBody], len(body)-maxDebugBody)
+}
@@ -0,0 +1,73 @@
+package integrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// RuleTiming is the time the governance service spent evaluating a rule
+type RuleTiming struct {
+	Rule       string  `json:"rule"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// analysisEnvelope is the response of services that report diagnostics along
+// with the results, rather than only the results array
+type analysisEnvelope struct {
+	Results     []LintResult `json:"results"`
+	Diagnostics *struct {
+		EvaluationMS float64      `json:"evaluation_ms"`
+		Rules        []RuleTiming `json:"rules"`
+	} `json:"diagnostics"`
+}
+
+// Diagnostics are the evaluation times the governance service reported,
+// summed over the analysis requests
+type Diagnostics struct {
+	EvaluationTime time.Duration
+	RuleTimes      map[string]time.Duration
+}
+
+// Diagnostics returns the evaluation times reported so far, or nil if the
+// service doesn't report them
+func (c *GovernanceClient) Diagnostics() *Diagnostics {
+	if c.diagnostics == nil {
+		return nil
+	}
+	return &Diagnostics{EvaluationTime: c.diagnostics.EvaluationTime, RuleTimes: maps.Clone(c.diagnostics.RuleTimes)}
+}
+
+// parseAnalysisResponse parses the results of an analysis request, recording
+// the diagnostics when the service wraps the results in an envelope
+func (c *GovernanceClient) parseAnalysisResponse(body []byte) ([]LintResult, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		var results []LintResult
+		if err := json.Unmarshal(body, &results); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		return results, nil
+	}
+
+	var envelope analysisEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if diagnostics := envelope.Diagnostics; diagnostics != nil {
+		if c.diagnostics == nil {
+			c.diagnostics = &Diagnostics{RuleTimes: map[string]time.Duration{}}
+		}
+		c.diagnostics.EvaluationTime += milliseconds(diagnostics.EvaluationMS)
+		for _, timing := range diagnostics.Rules {
+			c.diagnostics.RuleTimes[timing.Rule] += milliseconds(timing.DurationMS)
+		}
+	}
+	return envelope.Results, nil
+}
+
+// milliseconds converts fractional milliseconds to a duration
+func milliseconds(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
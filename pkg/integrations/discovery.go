@@ -0,0 +1,167 @@
+package integrations
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// specKeywords are searched for to find spec candidates, which are confirmed by
+// parsing them once fetched
+var specKeywords = []string{"openapi", "swagger", "asyncapi"}
+
+// specFileExtensions are the extensions of spec candidates
+var specFileExtensions = []string{".yaml", ".yml", ".json"}
+
+// RemoteFile is a file in a repository of the provider
+type RemoteFile struct {
+	Repository string // Full name, e.g. org/repo or group/project
+	Path       string
+	ProjectID  int // GitLab only
+	Ref        string
+}
+
+// SearchSpecs finds candidate spec files in the repositories of a GitHub
+// organization with the code search API. Only default branches are indexed.
+func (c *GitHubClient) SearchSpecs(ctx context.Context, org string) ([]RemoteFile, error) {
+	var files []RemoteFile
+	for _, keyword := range specKeywords {
+		searchPath := fmt.Sprintf("/search/code?q=%s&per_page=100", url.QueryEscape(keyword+" org:"+org))
+		err := c.list(ctx, searchPath, func(body []byte) error {
+			var result struct {
+				Items []struct {
+					Path       string `json:"path"`
+					Repository struct {
+						FullName string `json:"full_name"`
+					} `json:"repository"`
+				} `json:"items"`
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return err
+			}
+			for _, item := range result.Items {
+				files = addCandidate(files, RemoteFile{Repository: item.Repository.FullName, Path: item.Path})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", org, err)
+		}
+	}
+	return files, nil
+}
+
+// FetchFile returns the content of a file on the default branch of a repository
+func (c *GitHubClient) FetchFile(ctx context.Context, file RemoteFile) (string, error) {
+	var result struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	contentsPath := fmt.Sprintf("/repos/%s/contents/%s", file.Repository, escapePath(file.Path))
+	if file.Ref != "" {
+		contentsPath += "?ref=" + url.QueryEscape(file.Ref)
+	}
+	if err := c.do(ctx, "GET", contentsPath, nil, &result); err != nil {
+		return "", fmt.Errorf("failed to fetch %s/%s: %w", file.Repository, file.Path, err)
+	}
+	return decodeContent(result.Content, result.Encoding)
+}
+
+// SearchSpecs finds candidate spec files in the projects of a GitLab group and
+// its subgroups with the blob search API
+func (c *GitLabClient) SearchSpecs(ctx context.Context, group string) ([]RemoteFile, error) {
+	var files []RemoteFile
+	projects := map[int]string{}
+	type blob struct {
+		Path      string `json:"path"`
+		Ref       string `json:"ref"`
+		ProjectID int    `json:"project_id"`
+	}
+	for _, keyword := range specKeywords {
+		var blobs []blob
+		searchPath := fmt.Sprintf("/groups/%s/search?scope=blobs&search=%s&per_page=100",
+			url.PathEscape(group), url.QueryEscape(keyword))
+		err := c.list(ctx, searchPath, func(body []byte) error {
+			var batch []blob
+			if err := json.Unmarshal(body, &batch); err != nil {
+				return err
+			}
+			blobs = append(blobs, batch...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", group, err)
+		}
+
+		for _, blob := range blobs {
+			name, ok := projects[blob.ProjectID]
+			if !ok {
+				var project struct {
+					PathWithNamespace string `json:"path_with_namespace"`
+				}
+				if err := c.do(ctx, "GET", fmt.Sprintf("/projects/%d", blob.ProjectID), nil, &project); err != nil {
+					return nil, fmt.Errorf("failed to look up project %d: %w", blob.ProjectID, err)
+				}
+				name = project.PathWithNamespace
+				projects[blob.ProjectID] = name
+			}
+			files = addCandidate(files, RemoteFile{Repository: name, Path: blob.Path, ProjectID: blob.ProjectID, Ref: blob.Ref})
+		}
+	}
+	return files, nil
+}
+
+// FetchFile returns the content of a file at the ref it was found on
+func (c *GitLabClient) FetchFile(ctx context.Context, file RemoteFile) (string, error) {
+	var result struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	ref := file.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	filePath := fmt.Sprintf("/projects/%d/repository/files/%s?ref=%s",
+		file.ProjectID, url.PathEscape(file.Path), url.QueryEscape(ref))
+	if err := c.do(ctx, "GET", filePath, nil, &result); err != nil {
+		return "", fmt.Errorf("failed to fetch %s/%s: %w", file.Repository, file.Path, err)
+	}
+	return decodeContent(result.Content, result.Encoding)
+}
+
+// addCandidate adds a search hit with a spec extension unless it's already listed
+func addCandidate(files []RemoteFile, file RemoteFile) []RemoteFile {
+	if !slices.Contains(specFileExtensions, strings.ToLower(path.Ext(file.Path))) {
+		return files
+	}
+	if slices.ContainsFunc(files, func(f RemoteFile) bool { return f.Repository == file.Repository && f.Path == file.Path }) {
+		return files
+	}
+	return append(files, file)
+}
+
+// escapePath escapes each segment of a repository path for use in a URL
+func escapePath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// decodeContent decodes file content returned by a provider API
+func decodeContent(content, encoding string) (string, error) {
+	if encoding != "base64" {
+		return content, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content, "\n", ""))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode file content: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,145 @@
+package integrations
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+)
+
+// Event types the CI events are normalized to, so behavior can depend on
+// what triggered the run on both platforms
+const (
+	EventPullRequest = "pull_request" // Pull or merge request
+	EventPush        = "push"
+	EventTag         = "tag"
+	EventSchedule    = "schedule"
+	EventManual      = "manual" // Started by hand or through the API
+	EventMergeQueue  = "merge_queue"
+	EventComment     = "comment"
+	EventOther       = "other"
+)
+
+// EventTypes are the known event types
+var EventTypes = []string{EventPullRequest, EventPush, EventTag, EventSchedule, EventManual, EventMergeQueue, EventComment, EventOther}
+
+// githubEventType normalizes a GitHub event name
+func githubEventType(name, refType string) string {
+	switch name {
+	case "pull_request", "pull_request_target":
+		return EventPullRequest
+	case "push":
+		if refType == "tag" {
+			return EventTag
+		}
+		return EventPush
+	case "schedule":
+		return EventSchedule
+	case "workflow_dispatch", "repository_dispatch":
+		return EventManual
+	case "merge_group":
+		return EventMergeQueue
+	case "issue_comment":
+		return EventComment
+	case "":
+		return ""
+	}
+	return EventOther
+}
+
+// gitlabEventType normalizes a GitLab pipeline source
+func gitlabEventType(source, tag string) string {
+	switch source {
+	case "merge_request_event", "external_pull_request_event":
+		return EventPullRequest
+	case "push":
+		if tag != "" {
+			return EventTag
+		}
+		return EventPush
+	case "schedule":
+		return EventSchedule
+	case "web", "api", "trigger", "pipeline", "chat":
+		return EventManual
+	case "":
+		return ""
+	}
+	return EventOther
+}
+
+// githubEvent is the part of an event payload the action uses
+type githubEvent struct {
+	PullRequest *struct {
+		Number int `json:"number"`
+		Base   struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"base"`
+	} `json:"pull_request"`
+	Repository struct {
+		DefaultBranch string `json:"default_branch"`
+	} `json:"repository"`
+	// Deployment is set for deployment and deployment_protection_rule events
+	Deployment *struct {
+		ID          int64  `json:"id"`
+		SHA         string `json:"sha"`
+		Environment string `json:"environment"`
+	} `json:"deployment"`
+	// DeploymentCallbackURL is where deployment_protection_rule events are
+	// answered
+	DeploymentCallbackURL string `json:"deployment_callback_url"`
+	Environment           string `json:"environment"`
+}
+
+// applyGitHubEvent fills in the default branch and, for pull requests, the
+// number, target branch and base commit from the event payload. The number is
+// only in the ref for pull_request events, not for pull_request_target. For
+// deployments it fills in the deployment, its environment and commit, and the
+// callback of deployment protection rules.
+func applyGitHubEvent(context map[string]string, eventPath string) {
+	data, err := os.ReadFile(eventPath)
+	if err != nil {
+		return
+	}
+	var event githubEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return
+	}
+
+	context["default_branch"] = event.Repository.DefaultBranch
+	// Deployments are checked at the exact commit being deployed
+	if deployment := event.Deployment; deployment != nil {
+		context["deployment_id"] = strconv.FormatInt(deployment.ID, 10)
+		context["environment"] = deployment.Environment
+		if deployment.SHA != "" {
+			context["commit"] = deployment.SHA
+		}
+	}
+	if event.DeploymentCallbackURL != "" {
+		context["deployment_callback_url"] = event.DeploymentCallbackURL
+		context["environment"] = event.Environment
+	}
+	if pr := event.PullRequest; pr != nil && context["event_type"] == EventPullRequest {
+		if context["pull_request"] == "" && pr.Number > 0 {
+			context["pull_request"] = strconv.Itoa(pr.Number)
+		}
+		context["target_branch"] = pr.Base.Ref
+		if context["base_commit"] == "" {
+			context["base_commit"] = pr.Base.SHA
+		}
+	}
+}
+
+// IsPullRequest reports whether the run was triggered by a pull or merge request
+func IsPullRequest(context map[string]string) bool {
+	if eventType := context["event_type"]; eventType != "" && eventType != EventPullRequest && eventType != EventMergeQueue {
+		return false
+	}
+	return context["pull_request"] != ""
+}
+
+// OnDefaultBranch reports whether the run is on the default branch, e.g. after
+// a merge, rather than on a pull request or another branch
+func OnDefaultBranch(context map[string]string) bool {
+	return context["default_branch"] != "" && context["branch"] == context["default_branch"] &&
+		context["event_type"] != EventPullRequest && context["event_type"] != EventMergeQueue
+}
@@ -0,0 +1,126 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ExemptionRequest asks the governance team to waive a finding that can't be fixed
+type ExemptionRequest struct {
+	Fingerprint   string   `json:"fingerprint"`
+	RulesetID     string   `json:"rulesetId"`
+	Rule          string   `json:"rule"`
+	Path          []string `json:"path"`
+	Message       string   `json:"message"`
+	File          string   `json:"file"`
+	APIID         string   `json:"apiId,omitempty"`
+	APIName       string   `json:"apiName,omitempty"`
+	Repository    string   `json:"repository,omitempty"`
+	PullRequest   string   `json:"pullRequest,omitempty"`
+	Justification string   `json:"justification"`
+	RequestedBy   string   `json:"requestedBy,omitempty"`
+}
+
+// Exemption is an exemption request as recorded by the governance service
+type Exemption struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"` // e.g. pending, approved or rejected
+	Fingerprint string     `json:"fingerprint,omitempty"`
+	Rule        string     `json:"rule,omitempty"`
+	Repository  string     `json:"repository,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+// ExemptionApproved is the status of exemptions granted by the governance team
+const ExemptionApproved = "approved"
+
+// RequestExemption submits an exemption request for approval
+func (c *GovernanceClient) RequestExemption(ctx context.Context, request ExemptionRequest) (*Exemption, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := c.baseURL + "/exemptions"
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setHeaders(req)
+
+	c.logger.Debug("Requesting exemption", zap.String("url", endpoint), zap.String("fingerprint", request.Fingerprint))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := authError(resp, respBody); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("governance service returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var exemption Exemption
+	if err := json.Unmarshal(respBody, &exemption); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal exemption: %w", err)
+	}
+	return &exemption, nil
+}
+
+// ListApprovedExemptions returns the approved exemptions for a repository. A
+// service without the exemption endpoint has no exemptions.
+func (c *GovernanceClient) ListApprovedExemptions(ctx context.Context, repository string) ([]Exemption, error) {
+	query := url.Values{"status": {ExemptionApproved}}
+	if repository != "" {
+		query.Set("repository", repository)
+	}
+	endpoint := c.baseURL + "/exemptions?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+
+	c.logger.Debug("Fetching approved exemptions", zap.String("url", endpoint))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := authError(resp, body); err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("governance service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var exemptions []Exemption
+	if err := json.Unmarshal(body, &exemptions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal exemptions: %w", err)
+	}
+	return exemptions, nil
+}
@@ -0,0 +1,255 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// The repository is read with go-git, so blame attribution, git specs and
+// diff_base work without a git binary on the runner.
+
+// BlameInfo identifies the commit that last changed a line
+type BlameInfo struct {
+	Commit  string `json:"commit"`
+	Author  string `json:"author"`
+	Email   string `json:"email"`
+	Summary string `json:"summary"`
+}
+
+// ChangedLines returns the lines of file that were added or modified on HEAD
+// since it diverged from base, like git diff base...HEAD
+func ChangedLines(ctx context.Context, base, file string) (map[int]bool, error) {
+	repo, name, err := openRepositoryFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	head, err := commitAt(repo, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	baseCommit, err := commitAt(repo, base)
+	if err != nil {
+		return nil, err
+	}
+	bases, err := head.MergeBase(baseCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the merge base of %s and HEAD: %w", base, err)
+	}
+	if len(bases) == 0 {
+		return nil, fmt.Errorf("%s and HEAD have no common history, fetch more of it", base)
+	}
+
+	changed := map[int]bool{}
+	after, err := fileContents(head, name)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return changed, nil
+	} else if err != nil {
+		return nil, err
+	}
+	// A file the base doesn't have is changed on every line
+	before, err := fileContents(bases[0], name)
+	if err != nil && !errors.Is(err, object.ErrFileNotFound) {
+		return nil, err
+	}
+
+	line := 1
+	for _, change := range diff.Do(before, after) {
+		count := strings.Count(change.Text, "\n")
+		if !strings.HasSuffix(change.Text, "\n") {
+			count++
+		}
+		switch change.Type {
+		case diffmatchpatch.DiffEqual:
+			line += count
+		case diffmatchpatch.DiffInsert:
+			for end := line + count; line < end; line++ {
+				changed[line] = true
+			}
+		}
+	}
+	return changed, nil
+}
+
+// blameCache keeps the blame of each file at a commit, findings on the same
+// file are attributed from one blame
+var blameCache = struct {
+	sync.Mutex
+	results map[string]*git.BlameResult
+}{results: map[string]*git.BlameResult{}}
+
+// BlameLine returns the commit that last changed a line of file on HEAD
+func BlameLine(ctx context.Context, file string, line int) (*BlameInfo, error) {
+	repo, name, err := openRepositoryFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	head, err := commitAt(repo, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	key := head.Hash.String() + ":" + name
+	blameCache.Lock()
+	blame, ok := blameCache.results[key]
+	blameCache.Unlock()
+	if !ok {
+		if blame, err = git.Blame(head, name); err != nil {
+			return nil, fmt.Errorf("failed to blame %s: %w", name, err)
+		}
+		blameCache.Lock()
+		blameCache.results[key] = blame
+		blameCache.Unlock()
+	}
+	if line < 1 || line > len(blame.Lines) {
+		return nil, fmt.Errorf("no blame information for %s:%d", file, line)
+	}
+
+	blamed := blame.Lines[line-1]
+	info := &BlameInfo{Commit: blamed.Hash.String(), Author: blamed.AuthorName, Email: blamed.Author}
+	if commit, err := repo.CommitObject(blamed.Hash); err == nil {
+		info.Summary, _, _ = strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	}
+	return info, nil
+}
+
+// GitShow returns the content of a file at a revision, with the path relative
+// to the repository root
+func GitShow(ctx context.Context, rev, file string) ([]byte, error) {
+	commit, err := openCommit(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	content, err := fileContents(commit, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", file, rev, err)
+	}
+	return []byte(content), nil
+}
+
+// GitFileSize returns the size of a file at a revision in bytes
+func GitFileSize(ctx context.Context, rev, file string) (int64, error) {
+	commit, err := openCommit(ctx, rev)
+	if err != nil {
+		return 0, err
+	}
+	f, err := commit.File(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s at %s: %w", file, rev, err)
+	}
+	return f.Size, nil
+}
+
+// ResolveRevision returns the commit a revision such as a branch or tag names
+func ResolveRevision(ctx context.Context, rev string) (string, error) {
+	commit, err := openCommit(ctx, rev)
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String(), nil
+}
+
+// GitFiles lists the files of the repository at a revision
+func GitFiles(ctx context.Context, rev string) ([]string, error) {
+	commit, err := openCommit(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	files, err := commit.Files()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files at %s: %w", rev, err)
+	}
+	var names []string
+	err = files.ForEach(func(f *object.File) error {
+		names = append(names, f.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files at %s: %w", rev, err)
+	}
+	return names, nil
+}
+
+// openRepository opens the repository of the working directory, looking for
+// it in the parent directories like git does
+func openRepository(ctx context.Context) (*git.Repository, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	repo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the git repository: %w", err)
+	}
+	return repo, nil
+}
+
+// openRepositoryFile opens the repository of the working directory and returns
+// the path of file, relative to the working directory, in the repository
+func openRepositoryFile(ctx context.Context, file string) (*git.Repository, string, error) {
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open the git working tree: %w", err)
+	}
+	root, err := filepath.EvalSymlinks(worktree.Filesystem.Root())
+	if err != nil {
+		return nil, "", err
+	}
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return nil, "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	name, err := filepath.Rel(root, path)
+	if err != nil || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return nil, "", fmt.Errorf("%s is outside the git repository at %s", file, root)
+	}
+	return repo, filepath.ToSlash(name), nil
+}
+
+// openCommit opens the repository of the working directory and returns the
+// commit a revision names
+func openCommit(ctx context.Context, rev string) (*object.Commit, error) {
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return commitAt(repo, rev)
+}
+
+// commitAt returns the commit a revision names
+func commitAt(repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision %s: %w", rev, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision %s: %w", rev, err)
+	}
+	return commit, nil
+}
+
+// fileContents returns the content of a file, by its path in the repository,
+// at a commit
+func fileContents(commit *object.Commit, name string) (string, error) {
+	f, err := commit.File(name)
+	if err != nil {
+		return "", err
+	}
+	return f.Contents()
+}
@@ -0,0 +1,170 @@
+package integrations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// testRepository creates a repository with a commit per step, writing the
+// files of the step, and makes it the working directory. It returns the
+// commit hashes.
+func testRepository(t *testing.T, steps ...map[string]string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hashes []string
+	for i, files := range steps {
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := worktree.Add(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		author := &object.Signature{Name: "Dev " + string(rune('A'+i)), Email: "dev@example.com", When: time.Unix(int64(i)*3600, 0)}
+		hash, err := worktree.Commit("step "+string(rune('1'+i))+"\n\ndetails", &git.CommitOptions{Author: author})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, hash.String())
+	}
+	if _, err := repo.CreateTag("v1", hashNamed(t, repo, hashes[0]), &git.CreateTagOptions{
+		Message: "release", Tagger: &object.Signature{Name: "Dev", Email: "dev@example.com"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return hashes
+}
+
+func hashNamed(t *testing.T, repo *git.Repository, hash string) plumbing.Hash {
+	t.Helper()
+	commit, err := commitAt(repo, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return commit.Hash
+}
+
+func TestChangedLines(t *testing.T) {
+	testRepository(t,
+		map[string]string{"apis/users.yaml": "openapi: 3.0.3\ninfo:\n  title: Users\npaths: {}\n"},
+		map[string]string{"apis/users.yaml": "openapi: 3.0.3\ninfo:\n  title: Users API\n  version: \"1\"\npaths: {}\n", "apis/new.yaml": "a: 1\nb: 2"},
+	)
+	tests := []struct {
+		name string
+		file string
+		want map[int]bool
+	}{
+		{name: "modified and added lines", file: "apis/users.yaml", want: map[int]bool{3: true, 4: true}},
+		{name: "new file without a final newline", file: "apis/new.yaml", want: map[int]bool{1: true, 2: true}},
+		{name: "file not on HEAD", file: "apis/missing.yaml", want: map[int]bool{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ChangedLines(context.Background(), "v1", tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlameLine(t *testing.T) {
+	hashes := testRepository(t,
+		map[string]string{"openapi.yaml": "openapi: 3.0.3\npaths: {}\n"},
+		map[string]string{"openapi.yaml": "openapi: 3.0.3\ninfo: {title: t}\npaths: {}\n"},
+	)
+	tests := []struct {
+		line int
+		want BlameInfo
+	}{
+		{1, BlameInfo{Commit: hashes[0], Author: "Dev A", Email: "dev@example.com", Summary: "step 1"}},
+		{2, BlameInfo{Commit: hashes[1], Author: "Dev B", Email: "dev@example.com", Summary: "step 2"}},
+		{3, BlameInfo{Commit: hashes[0], Author: "Dev A", Email: "dev@example.com", Summary: "step 1"}},
+	}
+	for _, tt := range tests {
+		got, err := BlameLine(context.Background(), "openapi.yaml", tt.line)
+		if err != nil {
+			t.Fatalf("line %d: %v", tt.line, err)
+		}
+		if *got != tt.want {
+			t.Errorf("line %d: got %+v, want %+v", tt.line, *got, tt.want)
+		}
+	}
+	if _, err := BlameLine(context.Background(), "openapi.yaml", 4); err == nil {
+		t.Error("expected an error for a line past the end of the file")
+	}
+}
+
+func TestGitRevisions(t *testing.T) {
+	hashes := testRepository(t,
+		map[string]string{"apis/users.yaml": "v1\n", "README.md": "readme\n"},
+		map[string]string{"apis/users.yaml": "v2\n", "apis/orders.yaml": "orders\n"},
+	)
+	ctx := context.Background()
+
+	for rev, want := range map[string]string{"HEAD": hashes[1], "HEAD~1": hashes[0], "master": hashes[1], "v1": hashes[0]} {
+		got, err := ResolveRevision(ctx, rev)
+		if err != nil {
+			t.Errorf("ResolveRevision(%s): %v", rev, err)
+		} else if got != want {
+			t.Errorf("ResolveRevision(%s) = %s, want %s", rev, got, want)
+		}
+	}
+	if _, err := ResolveRevision(ctx, "nope"); err == nil {
+		t.Error("expected an error for an unknown revision")
+	}
+
+	content, err := GitShow(ctx, "v1", "apis/users.yaml")
+	if err != nil || string(content) != "v1\n" {
+		t.Errorf("GitShow = %q, %v, want v1", content, err)
+	}
+	if _, err := GitShow(ctx, "v1", "apis/orders.yaml"); err == nil {
+		t.Error("expected an error for a file the revision doesn't have")
+	}
+	if size, err := GitFileSize(ctx, "HEAD", "apis/orders.yaml"); err != nil || size != 7 {
+		t.Errorf("GitFileSize = %d, %v, want 7", size, err)
+	}
+
+	files, err := GitFiles(ctx, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	if want := []string{"README.md", "apis/orders.yaml", "apis/users.yaml"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("GitFiles = %v, want %v", files, want)
+	}
+}
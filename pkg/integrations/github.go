@@ -0,0 +1,273 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// GitHubClient handles communication with the GitHub REST API
+type GitHubClient struct {
+	*providerClient
+	token      string
+	repository string
+}
+
+// NewGitHubClient creates a GitHub client for the repository the workflow runs in
+func NewGitHubClient(token string, logger *zap.Logger) *GitHubClient {
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	return &GitHubClient{
+		providerClient: newProviderClient("GitHub", apiURL, map[string]string{
+			"Accept":               "application/vnd.github+json",
+			"Authorization":        "Bearer " + token,
+			"X-GitHub-Api-Version": "2022-11-28",
+		}, logger),
+		token:      token,
+		repository: os.Getenv("GITHUB_REPOSITORY"),
+	}
+}
+
+// AddLabels adds labels to a pull request
+func (c *GitHubClient) AddLabels(ctx context.Context, number int, labels []string) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d/labels", c.repository, number)
+	return c.do(ctx, "POST", path, map[string]interface{}{"labels": labels}, nil)
+}
+
+// RemoveLabel removes a label from a pull request. Removing a label that isn't
+// present is not an error.
+func (c *GitHubClient) RemoveLabel(ctx context.Context, number int, label string) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d/labels/%s", c.repository, number, url.PathEscape(label))
+	err := c.do(ctx, "DELETE", path, nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// RequestReviewers requests a review from users and teams on a pull request
+func (c *GitHubClient) RequestReviewers(ctx context.Context, number int, users, teams []string) error {
+	path := fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", c.repository, number)
+	return c.do(ctx, "POST", path, map[string]interface{}{
+		"reviewers":      nonNil(users),
+		"team_reviewers": nonNil(teams),
+	}, nil)
+}
+
+// PullRequestComment is a comment on a pull request's conversation
+type PullRequestComment struct {
+	ID   int64  `json:"id,omitempty"`
+	Body string `json:"body"`
+}
+
+// FindComment returns the first comment on a pull request containing marker, or nil
+func (c *GitHubClient) FindComment(ctx context.Context, number int, marker string) (*PullRequestComment, error) {
+	var found *PullRequestComment
+	path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100", c.repository, number)
+	err := c.list(ctx, path, func(body []byte) error {
+		var batch []PullRequestComment
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return err
+		}
+		for i := range batch {
+			if found == nil && strings.Contains(batch[i].Body, marker) {
+				found = &batch[i]
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// CreateComment comments on a pull request
+func (c *GitHubClient) CreateComment(ctx context.Context, number int, body string) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d/comments", c.repository, number)
+	return c.do(ctx, "POST", path, PullRequestComment{Body: body}, nil)
+}
+
+// UpdateComment replaces the body of a pull request comment
+func (c *GitHubClient) UpdateComment(ctx context.Context, id int64, body string) error {
+	path := fmt.Sprintf("/repos/%s/issues/comments/%d", c.repository, id)
+	return c.do(ctx, "PATCH", path, PullRequestComment{Body: body}, nil)
+}
+
+// CheckRun is a GitHub check run
+type CheckRun struct {
+	ID      int64  `json:"id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	HeadSHA string `json:"head_sha,omitempty"`
+	Status  string `json:"status,omitempty"`
+	// Conclusion is one of success, failure or neutral once the run is completed
+	Conclusion string          `json:"conclusion,omitempty"`
+	Output     *CheckRunOutput `json:"output,omitempty"`
+}
+
+// CheckRunOutput is the report attached to a check run
+type CheckRunOutput struct {
+	Title       string            `json:"title"`
+	Summary     string            `json:"summary"`
+	Annotations []CheckAnnotation `json:"annotations,omitempty"`
+}
+
+// CheckAnnotation is an inline annotation on a file of a check run
+type CheckAnnotation struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	// AnnotationLevel is one of notice, warning or failure
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+	RawDetails      string `json:"raw_details,omitempty"`
+}
+
+// maxAnnotationsPerRequest is the GitHub limit of annotations per check run request
+const maxAnnotationsPerRequest = 50
+
+// FindCheckRun returns the latest check run with the given name on a commit, or nil
+func (c *GitHubClient) FindCheckRun(ctx context.Context, sha, name string) (*CheckRun, error) {
+	path := fmt.Sprintf("/repos/%s/commits/%s/check-runs?check_name=%s", c.repository, sha, url.QueryEscape(name))
+	var response struct {
+		CheckRuns []CheckRun `json:"check_runs"`
+	}
+	if err := c.do(ctx, "GET", path, nil, &response); err != nil {
+		return nil, err
+	}
+	if len(response.CheckRuns) == 0 {
+		return nil, nil
+	}
+	return &response.CheckRuns[0], nil
+}
+
+// ListCheckRunAnnotations returns the annotations of a check run
+func (c *GitHubClient) ListCheckRunAnnotations(ctx context.Context, id int64) ([]CheckAnnotation, error) {
+	var annotations []CheckAnnotation
+	path := fmt.Sprintf("/repos/%s/check-runs/%d/annotations?per_page=100", c.repository, id)
+	err := c.list(ctx, path, func(body []byte) error {
+		var batch []CheckAnnotation
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return err
+		}
+		annotations = append(annotations, batch...)
+		return nil
+	})
+	return annotations, err
+}
+
+// CreateCheckRun creates a check run. Annotations beyond the per-request limit
+// are added with follow-up updates.
+func (c *GitHubClient) CreateCheckRun(ctx context.Context, run CheckRun) (*CheckRun, error) {
+	annotations := run.Output.Annotations
+	output := *run.Output
+	output.Annotations = firstAnnotations(annotations)
+	run.Output = &output
+
+	var created CheckRun
+	if err := c.do(ctx, "POST", fmt.Sprintf("/repos/%s/check-runs", c.repository), run, &created); err != nil {
+		return nil, err
+	}
+	if err := c.appendAnnotations(ctx, created.ID, output, annotations[len(output.Annotations):]); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateCheckRun updates a check run. GitHub appends the annotations to the
+// ones already present on the check run.
+func (c *GitHubClient) UpdateCheckRun(ctx context.Context, id int64, run CheckRun) error {
+	annotations := run.Output.Annotations
+	output := *run.Output
+	output.Annotations = firstAnnotations(annotations)
+	run.Output = &output
+
+	if err := c.do(ctx, "PATCH", fmt.Sprintf("/repos/%s/check-runs/%d", c.repository, id), run, nil); err != nil {
+		return err
+	}
+	return c.appendAnnotations(ctx, id, output, annotations[len(output.Annotations):])
+}
+
+// appendAnnotations adds annotations to a check run in batches
+func (c *GitHubClient) appendAnnotations(ctx context.Context, id int64, output CheckRunOutput, annotations []CheckAnnotation) error {
+	for len(annotations) > 0 {
+		output.Annotations = firstAnnotations(annotations)
+		annotations = annotations[len(output.Annotations):]
+		path := fmt.Sprintf("/repos/%s/check-runs/%d", c.repository, id)
+		if err := c.do(ctx, "PATCH", path, map[string]interface{}{"output": output}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deployment is a GitHub deployment of a commit to an environment
+type Deployment struct {
+	ID          int64  `json:"id,omitempty"`
+	Ref         string `json:"ref,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Description string `json:"description,omitempty"`
+	// AutoMerge and RequiredContexts are set so the deployment is created
+	// for the exact commit, without merging the default branch or waiting
+	// for other statuses
+	AutoMerge        bool     `json:"auto_merge"`
+	RequiredContexts []string `json:"required_contexts"`
+}
+
+// DeploymentStatus is the state of a deployment
+type DeploymentStatus struct {
+	// State is one of success, failure, error, in_progress or pending
+	State       string `json:"state"`
+	Description string `json:"description,omitempty"`
+	LogURL      string `json:"log_url,omitempty"`
+}
+
+// CreateDeployment creates a deployment of a commit to an environment
+func (c *GitHubClient) CreateDeployment(ctx context.Context, sha, environment, description string) (*Deployment, error) {
+	deployment := Deployment{Ref: sha, Environment: environment, Description: description, RequiredContexts: []string{}}
+	var created Deployment
+	if err := c.do(ctx, "POST", fmt.Sprintf("/repos/%s/deployments", c.repository), deployment, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// CreateDeploymentStatus sets the state of a deployment
+func (c *GitHubClient) CreateDeploymentStatus(ctx context.Context, id int64, status DeploymentStatus) error {
+	return c.do(ctx, "POST", fmt.Sprintf("/repos/%s/deployments/%d/statuses", c.repository, id), status, nil)
+}
+
+// ReviewDeploymentProtectionRule approves or rejects a deployment waiting on
+// a custom deployment protection rule, through the callback URL of the
+// deployment_protection_rule event. state is approved or rejected.
+func (c *GitHubClient) ReviewDeploymentProtectionRule(ctx context.Context, callbackURL, environment, state, comment string) error {
+	return c.do(ctx, "POST", callbackURL, map[string]string{
+		"environment_name": environment,
+		"state":            state,
+		"comment":          comment,
+	}, nil)
+}
+
+// firstAnnotations returns the annotations that fit in a single request
+func firstAnnotations(annotations []CheckAnnotation) []CheckAnnotation {
+	if len(annotations) > maxAnnotationsPerRequest {
+		return annotations[:maxAnnotationsPerRequest]
+	}
+	return annotations
+}
+
+// nonNil returns an empty slice for nil so it's encoded as [] rather than null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,192 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// GitLabClient handles communication with the GitLab REST API
+type GitLabClient struct {
+	*providerClient
+	projectID string
+}
+
+// NewGitLabClient creates a GitLab client for the project the pipeline runs in
+func NewGitLabClient(token string, logger *zap.Logger) *GitLabClient {
+	apiURL := os.Getenv("CI_API_V4_URL")
+	if apiURL == "" {
+		apiURL = "https://gitlab.com/api/v4"
+	}
+	return &GitLabClient{
+		providerClient: newProviderClient("GitLab", apiURL, map[string]string{"PRIVATE-TOKEN": token}, logger),
+		projectID:      os.Getenv("CI_PROJECT_ID"),
+	}
+}
+
+// NewGitLabJobTokenClient creates a GitLab client authenticated with the CI
+// job token of the pipeline, for projects that don't provision an access token
+func NewGitLabJobTokenClient(jobToken string, logger *zap.Logger) *GitLabClient {
+	client := NewGitLabClient("", logger)
+	client.headers = map[string]string{"JOB-TOKEN": jobToken}
+	return client
+}
+
+// UpdateMergeRequestLabels adds and removes labels on a merge request
+func (c *GitLabClient) UpdateMergeRequestLabels(ctx context.Context, iid int, add, remove []string) error {
+	path := fmt.Sprintf("/projects/%s/merge_requests/%d", c.projectID, iid)
+	return c.do(ctx, "PUT", path, map[string]interface{}{
+		"add_labels":    strings.Join(add, ","),
+		"remove_labels": strings.Join(remove, ","),
+	}, nil)
+}
+
+// AddMergeRequestReviewers adds users to the reviewers of a merge request,
+// keeping the reviewers that are already assigned
+func (c *GitLabClient) AddMergeRequestReviewers(ctx context.Context, iid int, usernames []string) error {
+	path := fmt.Sprintf("/projects/%s/merge_requests/%d", c.projectID, iid)
+
+	var mr struct {
+		Reviewers []struct {
+			ID int `json:"id"`
+		} `json:"reviewers"`
+	}
+	if err := c.do(ctx, "GET", path, nil, &mr); err != nil {
+		return fmt.Errorf("failed to get merge request: %w", err)
+	}
+
+	reviewerIDs := []int{}
+	for _, reviewer := range mr.Reviewers {
+		reviewerIDs = append(reviewerIDs, reviewer.ID)
+	}
+	for _, username := range usernames {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		if err := c.do(ctx, "GET", "/users?username="+url.QueryEscape(username), nil, &users); err != nil {
+			return fmt.Errorf("failed to look up user %s: %w", username, err)
+		}
+		if len(users) == 0 {
+			return fmt.Errorf("user %s not found", username)
+		}
+		reviewerIDs = append(reviewerIDs, users[0].ID)
+	}
+
+	return c.do(ctx, "PUT", path, map[string]interface{}{"reviewer_ids": reviewerIDs}, nil)
+}
+
+// DiffRefs are the commits a merge request diff is between, which anchor
+// discussions on its lines
+type DiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+// DiscussionPosition anchors a merge request discussion to a line of the diff
+type DiscussionPosition struct {
+	PositionType string `json:"position_type"`
+	BaseSHA      string `json:"base_sha"`
+	HeadSHA      string `json:"head_sha"`
+	StartSHA     string `json:"start_sha"`
+	OldPath      string `json:"old_path"`
+	NewPath      string `json:"new_path"`
+	NewLine      int    `json:"new_line"`
+}
+
+// DiscussionNote is a note of a merge request discussion
+type DiscussionNote struct {
+	ID       int64  `json:"id"`
+	Body     string `json:"body"`
+	Resolved bool   `json:"resolved"`
+}
+
+// Discussion is a merge request discussion thread
+type Discussion struct {
+	ID    string           `json:"id"`
+	Notes []DiscussionNote `json:"notes"`
+}
+
+// Resolved reports whether the discussion's notes are resolved
+func (d Discussion) Resolved() bool {
+	return len(d.Notes) > 0 && d.Notes[0].Resolved
+}
+
+// MergeRequestDiffRefs returns the commits of the latest merge request diff
+func (c *GitLabClient) MergeRequestDiffRefs(ctx context.Context, iid int) (DiffRefs, error) {
+	var mr struct {
+		DiffRefs DiffRefs `json:"diff_refs"`
+	}
+	path := fmt.Sprintf("/projects/%s/merge_requests/%d", c.projectID, iid)
+	if err := c.do(ctx, "GET", path, nil, &mr); err != nil {
+		return DiffRefs{}, fmt.Errorf("failed to get merge request: %w", err)
+	}
+	return mr.DiffRefs, nil
+}
+
+// ListDiscussions returns the discussions of a merge request
+func (c *GitLabClient) ListDiscussions(ctx context.Context, iid int) ([]Discussion, error) {
+	var discussions []Discussion
+	path := fmt.Sprintf("/projects/%s/merge_requests/%d/discussions?per_page=100", c.projectID, iid)
+	err := c.list(ctx, path, func(body []byte) error {
+		var batch []Discussion
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return err
+		}
+		discussions = append(discussions, batch...)
+		return nil
+	})
+	return discussions, err
+}
+
+// CreateDiscussion starts a merge request discussion, on a diff line when
+// position is set
+func (c *GitLabClient) CreateDiscussion(ctx context.Context, iid int, body string, position *DiscussionPosition) error {
+	path := fmt.Sprintf("/projects/%s/merge_requests/%d/discussions", c.projectID, iid)
+	payload := map[string]interface{}{"body": body}
+	if position != nil {
+		payload["position"] = position
+	}
+	return c.do(ctx, "POST", path, payload, nil)
+}
+
+// ResolveDiscussion resolves or reopens a merge request discussion
+func (c *GitLabClient) ResolveDiscussion(ctx context.Context, iid int, id string, resolved bool) error {
+	path := fmt.Sprintf("/projects/%s/merge_requests/%d/discussions/%s?resolved=%t", c.projectID, iid, url.PathEscape(id), resolved)
+	return c.do(ctx, "PUT", path, nil, nil)
+}
+
+// ExternalStatusCheck is an external status check that applies to a merge
+// request, with its status for the latest commit
+type ExternalStatusCheck struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	ExternalURL string `json:"external_url"`
+	Status      string `json:"status"`
+}
+
+// ListStatusChecks returns the external status checks of a merge request
+func (c *GitLabClient) ListStatusChecks(ctx context.Context, iid int) ([]ExternalStatusCheck, error) {
+	var checks []ExternalStatusCheck
+	path := fmt.Sprintf("/projects/%s/merge_requests/%d/status_checks", c.projectID, iid)
+	if err := c.do(ctx, "GET", path, nil, &checks); err != nil {
+		return nil, fmt.Errorf("failed to list status checks: %w", err)
+	}
+	return checks, nil
+}
+
+// SetStatusCheckResponse reports the status, passed or failed, of an external
+// status check for the merge request's head commit sha
+func (c *GitLabClient) SetStatusCheckResponse(ctx context.Context, iid int, checkID int64, sha, status string) error {
+	path := fmt.Sprintf("/projects/%s/merge_requests/%d/status_check_responses", c.projectID, iid)
+	return c.do(ctx, "POST", path, map[string]interface{}{
+		"sha":                      sha,
+		"external_status_check_id": checkID,
+		"status":                   status,
+	}, nil)
+}
@@ -4,35 +4,58 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
-	"gopkg.in/yaml.v3"
 )
 
 // GovernanceClient handles communication with the governance service
 type GovernanceClient struct {
-	baseURL    string
-	authToken  string
-	httpClient *http.Client
-	logger     *zap.Logger
+	baseURL        string
+	authToken      string
+	httpClient     *http.Client
+	logger         *zap.Logger
+	metadataCache  *metadataCache
+	payloadVersion string
+	retries        int
+	backoff        time.Duration
+	retryStats     RetryStats
+	requestStats   RequestStats
+	diagnostics    *Diagnostics
+	orgID          string
+	teamID         string
 }
 
 // NewGovernanceClient creates a new governance client
 func NewGovernanceClient(baseURL, authToken string, logger *zap.Logger) *GovernanceClient {
 	return &GovernanceClient{
-		baseURL:   baseURL,
-		authToken: authToken,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		logger: logger,
+		baseURL:    baseURL,
+		authToken:  authToken,
+		httpClient: newHTTPClient(30 * time.Second),
+		logger:     logger,
 	}
 }
 
+// Token validation errors returned by ValidateToken
+var (
+	ErrInvalidToken            = errors.New("invalid token")
+	ErrExpiredToken            = errors.New("expired token")
+	ErrInsufficientPermissions = errors.New("insufficient permissions")
+)
+
+// EnableMetadataCache caches ruleset metadata in dir. Entries younger than ttl are
+// used without contacting the service, older entries are used only when the
+// service can't be reached.
+func (c *GovernanceClient) EnableMetadataCache(dir string, ttl time.Duration) {
+	c.metadataCache = &metadataCache{dir: dir, ttl: ttl}
+}
+
 // LintResult represents a governance analysis result
 type LintResult struct {
 	Code     string        `json:"code"`
@@ -68,41 +91,67 @@ type RuleReference struct {
 	Name string `json:"name"`
 }
 
+// Ruleset represents the metadata of a governance ruleset
+type Ruleset struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Version string         `json:"version"`
+	Rules   []RuleMetadata `json:"rules"`
+}
+
+// RuleMetadata describes a single rule of a ruleset
+type RuleMetadata struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Frameworks  []string `json:"frameworks"` // Compliance framework controls, e.g. "OWASP API3"
+	Remediation string   `json:"remediation,omitempty"`
+}
+
+// Rule returns the metadata for the named rule, or nil if the ruleset doesn't define it
+func (r *Ruleset) Rule(name string) *RuleMetadata {
+	if r == nil {
+		return nil
+	}
+	for i := range r.Rules {
+		if r.Rules[i].Name == name {
+			return &r.Rules[i]
+		}
+	}
+	return nil
+}
+
+// AnalysisRequest describes a spec to analyze and the API record it belongs to
+type AnalysisRequest struct {
+	Content  string
+	RuleID   string
+	Version  string // Pinned ruleset version, the latest version is used without one
+	Filename string // Repository-relative path of the spec, e.g. api/openapi.yaml
+	APIID    string // Governance service API record the findings are linked to
+	APIName  string
+	Format   string // Spec format hint, e.g. openapi31, the service sniffs the content without one
+	// Ruleset is evaluated instead of the published ruleset RuleID, without
+	// linking the findings to an API record
+	Ruleset *InlineRuleset
+}
+
 // AnalyzeOAS analyzes an OpenAPI specification against a specific rule
 func (c *GovernanceClient) AnalyzeOAS(ctx context.Context, oasContent, ruleID, filename string) ([]LintResult, error) {
+	return c.Analyze(ctx, AnalysisRequest{Content: oasContent, RuleID: ruleID, Filename: filename})
+}
+
+// Analyze analyzes an OpenAPI specification as described by the request
+func (c *GovernanceClient) Analyze(ctx context.Context, analysis AnalysisRequest) ([]LintResult, error) {
+	oasContent, ruleID, filename := analysis.Content, analysis.RuleID, analysis.Filename
 	c.logger.Info("Starting OAS analysis", zap.String("rule_id", ruleID), zap.String("filename", filename))
 
 	// Convert YAML content to JSON if needed
-	var jsonContent json.RawMessage
-	var err error
-
-	// Try to parse as YAML first, then convert to JSON
-	var yamlData interface{}
-	if err := yaml.Unmarshal([]byte(oasContent), &yamlData); err == nil {
-		// Successfully parsed as YAML, convert to JSON
-		jsonContent, err = json.Marshal(yamlData)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert YAML to JSON: %w", err)
-		}
-	} else {
-		// Try to parse as JSON directly
-		if json.Valid([]byte(oasContent)) {
-			jsonContent = json.RawMessage(oasContent)
-		} else {
-			return nil, fmt.Errorf("content is neither valid YAML nor JSON: %w", err)
-		}
+	jsonContent, err := specJSON(oasContent)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create the analysis request in the correct format expected by the governance service
-	request := map[string]interface{}{
-		"ruleSetSelector": map[string]interface{}{
-			"id": ruleID,
-		},
-		"apiContent": map[string]interface{}{
-			"name":    filename,
-			"content": jsonContent,
-		},
-	}
+	// Create the analysis request in the format expected by the governance service
+	request := analysisPayload(c.payloadVersion, analysis, jsonContent)
 
 	// Make the API call
 	results, err := c.makeAnalysisRequest(ctx, request)
@@ -114,6 +163,149 @@ func (c *GovernanceClient) AnalyzeOAS(ctx context.Context, oasContent, ruleID, f
 	return results, nil
 }
 
+// RequestStats describes the analysis requests sent to the governance service
+type RequestStats struct {
+	UploadBytes int64         // Size of the request bodies sent, including retries
+	Latency     time.Duration // Time spent waiting for responses, including retries
+}
+
+// RequestStats returns the size and latency of the analysis requests made so far
+func (c *GovernanceClient) RequestStats() RequestStats {
+	return c.requestStats
+}
+
+// Fork returns a client for analyses running concurrently with the client's
+// own. It shares the settings, the negotiated payload version and the metadata
+// cache, but counts its requests apart and reports no diagnostics; Join adds
+// its statistics back.
+func (c *GovernanceClient) Fork() *GovernanceClient {
+	if c == nil {
+		return nil
+	}
+	fork := *c
+	fork.retryStats, fork.requestStats, fork.diagnostics = RetryStats{}, RequestStats{}, nil
+	return &fork
+}
+
+// Join adds the retries and requests of a forked client once its analyses
+// finished
+func (c *GovernanceClient) Join(fork *GovernanceClient) {
+	if c == nil || fork == nil {
+		return
+	}
+	c.retryStats.Retries += fork.retryStats.Retries
+	c.retryStats.Waited += fork.retryStats.Waited
+	c.requestStats.UploadBytes += fork.requestStats.UploadBytes
+	c.requestStats.Latency += fork.requestStats.Latency
+}
+
+// ValidateToken checks the auth token with a HEAD request to the ruleset
+// endpoint, which authorizes like fetching the metadata without transferring
+// it, so authentication problems surface before the spec is uploaded
+func (c *GovernanceClient) ValidateToken(ctx context.Context, ruleID string) error {
+	endpoint := fmt.Sprintf("%s/rulesets/%s", c.baseURL, url.PathEscape(ruleID))
+	req, err := http.NewRequestWithContext(ctx, "HEAD", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+
+	c.logger.Debug("Validating governance token", zap.String("url", endpoint))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	resp.Body.Close()
+	if err := authError(resp, nil); err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("governance service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GetRuleset returns the ruleset metadata, from the cache when enabled, and
+// validates the token either way. Authentication failures are reported as
+// ErrInvalidToken, ErrExpiredToken or ErrInsufficientPermissions.
+func (c *GovernanceClient) GetRuleset(ctx context.Context, ruleID string) (*Ruleset, error) {
+	if c.metadataCache == nil {
+		return c.fetchRuleset(ctx, ruleID)
+	}
+
+	key := c.metadataKey(ruleID)
+	cached, fresh := c.metadataCache.load(key)
+	if fresh {
+		// The token may have expired or been revoked since the metadata was
+		// cached, so it's still validated
+		if err := c.ValidateToken(ctx, ruleID); IsAuthError(err) {
+			return nil, err
+		} else if err != nil {
+			c.logger.Debug("Failed to validate governance token, leaving it to the analysis", zap.Error(err))
+		}
+		c.logger.Debug("Using cached ruleset metadata", zap.String("rule_id", ruleID), zap.Time("fetched_at", cached.FetchedAt))
+		return cached.Ruleset, nil
+	}
+
+	ruleset, err := c.fetchRuleset(ctx, ruleID)
+	if err != nil {
+		// Fall back to stale metadata when the service is unavailable, but never hide auth problems
+		if cached != nil && !IsAuthError(err) {
+			c.logger.Warn("Failed to fetch ruleset metadata, using stale cache",
+				zap.String("rule_id", ruleID), zap.Time("fetched_at", cached.FetchedAt), zap.Error(err))
+			return cached.Ruleset, nil
+		}
+		return nil, err
+	}
+
+	if err := c.metadataCache.store(key, ruleset); err != nil {
+		c.logger.Warn("Failed to cache ruleset metadata", zap.Error(err))
+	}
+	return ruleset, nil
+}
+
+// fetchRuleset fetches the ruleset metadata from the service
+func (c *GovernanceClient) fetchRuleset(ctx context.Context, ruleID string) (*Ruleset, error) {
+	endpoint := fmt.Sprintf("%s/rulesets/%s", c.baseURL, url.PathEscape(ruleID))
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+
+	c.logger.Debug("Fetching ruleset metadata", zap.String("url", endpoint))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if err := authError(resp, body); err != nil {
+		if errors.Is(err, ErrInsufficientPermissions) {
+			return nil, fmt.Errorf("%w: token cannot read ruleset %s", err, ruleID)
+		}
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("ruleset %s not found", ruleID)
+	default:
+		return nil, fmt.Errorf("governance service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var ruleset Ruleset
+	if err := json.Unmarshal(body, &ruleset); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ruleset: %w", err)
+	}
+	return &ruleset, nil
+}
+
 // makeAnalysisRequest makes the actual HTTP request to the governance service
 func (c *GovernanceClient) makeAnalysisRequest(ctx context.Context, request interface{}) ([]LintResult, error) {
 	// For now, we'll use the existing /rulesets/evaluate endpoint
@@ -125,6 +317,16 @@ func (c *GovernanceClient) makeAnalysisRequest(ctx context.Context, request inte
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	var results []LintResult
+	err = c.withRetries(ctx, "evaluate", func(ctx context.Context) error {
+		results, err = c.postAnalysis(ctx, requestBody)
+		return err
+	})
+	return results, err
+}
+
+// postAnalysis makes a single analysis request
+func (c *GovernanceClient) postAnalysis(ctx context.Context, requestBody []byte) ([]LintResult, error) {
 	// Create HTTP request
 	url := fmt.Sprintf("%s/rulesets/evaluate", c.baseURL)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
@@ -134,44 +336,75 @@ func (c *GovernanceClient) makeAnalysisRequest(ctx context.Context, request inte
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-Key", fmt.Sprintf("%s", c.authToken))
+	c.setHeaders(req)
 
 	// Make the request
 	c.logger.Debug("Making request to governance service", zap.String("url", url))
+	started := time.Now()
 	resp, err := c.httpClient.Do(req)
+	c.requestStats.UploadBytes += int64(len(requestBody))
+	c.requestStats.Latency += time.Since(started)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+		return nil, &retryableError{err: fmt.Errorf("failed to make request: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, &retryableError{err: fmt.Errorf("failed to read response body: %w", err)}
 	}
 
 	// Check response status
+	if err := authError(resp, body); err != nil {
+		return nil, err
+	}
 	if resp.StatusCode != http.StatusOK {
 		c.logger.Error("Governance service returned error",
 			zap.Int("status_code", resp.StatusCode),
 			zap.String("response_body", string(body)))
-		return nil, fmt.Errorf("governance service returned status %d: %s", resp.StatusCode, string(body))
+		err := fmt.Errorf("governance service returned status %d: %s", resp.StatusCode, string(body))
+		if retryableStatus(resp.StatusCode) {
+			return nil, &retryableError{err: err, retryAfter: retryAfter(resp)}
+		}
+		return nil, err
 	}
 
 	// Parse response
-	var results []LintResult
-	if err := json.Unmarshal(body, &results); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	return c.parseAnalysisResponse(body)
+}
+
+// authError classifies authentication failures of a governance service response,
+// returning nil when the request was authorized
+func authError(resp *http.Response, body []byte) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		// The service reports expiry either in the body or in the WWW-Authenticate challenge
+		detail := strings.ToLower(string(body) + " " + resp.Header.Get("WWW-Authenticate"))
+		if strings.Contains(detail, "expired") {
+			return ErrExpiredToken
+		}
+		return ErrInvalidToken
+	case http.StatusForbidden:
+		return ErrInsufficientPermissions
 	}
+	return nil
+}
 
-	return results, nil
+// IsAuthError reports whether err means the governance token was rejected:
+// ErrInvalidToken, ErrExpiredToken or ErrInsufficientPermissions
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrExpiredToken) || errors.Is(err, ErrInsufficientPermissions)
 }
 
 // Alternative approach: If the governance service doesn't support direct file analysis,
 // we might need to implement a different workflow. Here's a placeholder for that:
 
 // AnalyzeOASWithUpload analyzes an OAS file by first uploading it to the governance service
-func (c *GovernanceClient) AnalyzeOASWithUpload(ctx context.Context, oasContent, ruleID string) ([]LintResult, error) {
+func (c *GovernanceClient) AnalyzeOASWithUpload(ctx context.Context, oasContent, ruleID, filename string) ([]LintResult, error) {
 	c.logger.Info("Starting OAS analysis with upload workflow", zap.String("rule_id", ruleID))
 
 	// This would be the workflow if we need to:
@@ -185,5 +418,5 @@ func (c *GovernanceClient) AnalyzeOASWithUpload(ctx context.Context, oasContent,
 	// 2. Use the existing /rulesets/evaluate endpoint with the temporary API ID
 	// 3. Clean up the temporary API after analysis
 
-	return c.AnalyzeOAS(ctx, oasContent, ruleID, "")
+	return c.AnalyzeOAS(ctx, oasContent, ruleID, filename)
 }
@@ -0,0 +1,71 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// A token revoked or expired after the metadata was cached must still be
+// rejected before the spec is uploaded
+func TestGetRulesetValidatesTokenOnCacheHit(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{name: "valid token", status: http.StatusOK},
+		{name: "revoked token", status: http.StatusUnauthorized, wantErr: ErrInvalidToken},
+		{name: "lost permissions", status: http.StatusForbidden, wantErr: ErrInsufficientPermissions},
+		{name: "service unavailable", status: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status atomic.Int64
+			status.Store(http.StatusOK)
+			var fetches atomic.Int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if code := int(status.Load()); code != http.StatusOK {
+					w.WriteHeader(code)
+					return
+				}
+				if r.Method == http.MethodGet {
+					fetches.Add(1)
+				}
+				w.Write([]byte(`{"id": "r", "name": "Ruleset", "version": "1.0.0", "rules": []}`))
+			}))
+			defer server.Close()
+
+			client := NewGovernanceClient(server.URL, "token", zap.NewNop())
+			client.EnableMetadataCache(t.TempDir(), time.Hour)
+			if _, err := client.GetRuleset(context.Background(), "r"); err != nil {
+				t.Fatal(err)
+			}
+
+			status.Store(int64(tt.status))
+			ruleset, err := client.GetRuleset(context.Background(), "r")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ruleset.Version != "1.0.0" {
+				t.Errorf("got ruleset %+v, want the cached one", ruleset)
+			}
+			if n := fetches.Load(); n != 1 {
+				t.Errorf("metadata fetched %d times, want once", n)
+			}
+		})
+	}
+}
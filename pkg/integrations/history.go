@@ -0,0 +1,135 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HistoryEntry is the summary of an earlier run, for trend gates
+type HistoryEntry struct {
+	Branch   string    `json:"branch"`
+	Commit   string    `json:"commit,omitempty"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Total    int       `json:"total"`
+	Time     time.Time `json:"time"`
+	// Fingerprints identify the findings of the run, to tell how long a
+	// finding has been open
+	Fingerprints []string `json:"fingerprints,omitempty"`
+}
+
+// GetHistory returns the runs the governance service recorded for a branch of
+// a repository since a point in time. A service without history has no runs.
+func (c *GovernanceClient) GetHistory(ctx context.Context, repository, branch string, since time.Time) ([]HistoryEntry, error) {
+	query := url.Values{"branch": {branch}, "since": {since.UTC().Format(time.RFC3339)}}
+	if repository != "" {
+		query.Set("repository", repository)
+	}
+	endpoint := c.baseURL + "/history?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+
+	c.logger.Debug("Fetching run history", zap.String("url", endpoint))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := authError(resp, body); err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("governance service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var entries []HistoryEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal history: %w", err)
+	}
+	return entries, nil
+}
+
+// FetchHistory downloads the run history from a JSON file in an object store,
+// e.g. through a presigned URL. A missing file has no runs yet. The token, if
+// any, is sent as a bearer token.
+func FetchHistory(ctx context.Context, historyURL, token string) ([]HistoryEntry, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", historyURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := newHTTPClient(30 * time.Second).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch history: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("history URL returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var entries []HistoryEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal history: %w", err)
+	}
+	return entries, nil
+}
+
+// StoreHistory uploads the run history to a JSON file in an object store
+func StoreHistory(ctx context.Context, historyURL, token string, entries []HistoryEntry) error {
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", historyURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := newHTTPClient(30 * time.Second).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to store history: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("history URL returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
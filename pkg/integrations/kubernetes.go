@@ -0,0 +1,79 @@
+package integrations
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// serviceAccountDir holds the service account credentials mounted into pods
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// KubernetesClient reads objects from the API server of the cluster the pod
+// runs in, authenticated as the pod's service account
+type KubernetesClient struct {
+	*providerClient
+	// Namespace is the namespace of the pod
+	Namespace string
+}
+
+// NewInClusterKubernetesClient creates a client for the cluster the pod runs
+// in from its service account credentials
+func NewInClusterKubernetesClient(logger *zap.Logger) (*KubernetesClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in a Kubernetes pod, KUBERNETES_SERVICE_HOST is not set")
+	}
+	token, err := os.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the service account token: %w", err)
+	}
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the cluster CA: %w", err)
+	}
+	namespace, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the pod namespace: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("failed to parse the cluster CA")
+	}
+
+	apiURL := "https://" + net.JoinHostPort(host, port)
+	headers := map[string]string{"Authorization": "Bearer " + strings.TrimSpace(string(token))}
+	client := &KubernetesClient{
+		providerClient: newProviderClient("Kubernetes", apiURL, headers, logger),
+		Namespace:      strings.TrimSpace(string(namespace)),
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	if recording, ok := client.httpClient.Transport.(*recordingTransport); ok {
+		recording.base = transport
+	} else {
+		client.httpClient.Transport = transport
+	}
+	return client, nil
+}
+
+// ConfigMapData returns the data of a ConfigMap, by key
+func (c *KubernetesClient) ConfigMapData(ctx context.Context, namespace, name string) (map[string]string, error) {
+	var configMap struct {
+		Data map[string]string `json:"data"`
+	}
+	path := fmt.Sprintf("/api/v1/namespaces/%s/configmaps/%s", url.PathEscape(namespace), url.PathEscape(name))
+	if err := c.do(ctx, "GET", path, nil, &configMap); err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	return configMap.Data, nil
+}
@@ -0,0 +1,71 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metric is a gauge sample pushed to a Prometheus Pushgateway
+type Metric struct {
+	Name   string
+	Help   string
+	Labels map[string]string
+	Value  float64
+}
+
+// PushMetrics replaces the metrics of the job on a Prometheus Pushgateway, so a
+// scheduled scan always reports the latest snapshot
+func PushMetrics(ctx context.Context, gatewayURL, job string, metrics []Metric) error {
+	endpoint := strings.TrimSuffix(gatewayURL, "/") + "/metrics/job/" + url.PathEscape(job)
+	req, err := http.NewRequestWithContext(ctx, "PUT", endpoint, bytes.NewReader(metricsText(metrics)))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := newHTTPClient(30 * time.Second).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("pushgateway returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// metricsText renders metrics in the Prometheus text exposition format
+func metricsText(metrics []Metric) []byte {
+	var buf bytes.Buffer
+	described := map[string]bool{}
+	for _, metric := range metrics {
+		if !described[metric.Name] {
+			described[metric.Name] = true
+			fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n", metric.Name, metric.Help, metric.Name)
+		}
+		buf.WriteString(metric.Name)
+		if len(metric.Labels) > 0 {
+			names := make([]string, 0, len(metric.Labels))
+			for name := range metric.Labels {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			labels := make([]string, 0, len(names))
+			for _, name := range names {
+				labels = append(labels, name+"="+strconv.Quote(metric.Labels[name]))
+			}
+			buf.WriteString("{" + strings.Join(labels, ",") + "}")
+		}
+		buf.WriteString(" " + strconv.FormatFloat(metric.Value, 'g', -1, 64) + "\n")
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,152 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Analysis request payload versions understood by the governance service generations
+const (
+	PayloadAuto = "auto" // Detect from the version the service advertises
+	PayloadV1   = "v1"   // Legacy flat ruleset_id, filename and content fields
+	PayloadV2   = "v2"   // ruleSetSelector and apiContent selectors
+)
+
+// ValidPayloadVersion reports whether version is a known payload version
+func ValidPayloadVersion(version string) bool {
+	switch version {
+	case PayloadAuto, PayloadV1, PayloadV2:
+		return true
+	}
+	return false
+}
+
+// ServiceInfo is the version information advertised by the governance service
+type ServiceInfo struct {
+	Version        string `json:"version"`
+	PayloadVersion string `json:"payloadVersion,omitempty"`
+}
+
+// SetPayloadVersion sets the shape of analysis request payloads, v2 by default
+func (c *GovernanceClient) SetPayloadVersion(version string) {
+	c.payloadVersion = version
+}
+
+// DetectPayloadVersion asks the governance service for its version and returns
+// the payload version it understands. Services advertising a payload version
+// are taken at their word, otherwise services before 2.0 get the legacy payload.
+func (c *GovernanceClient) DetectPayloadVersion(ctx context.Context) (string, error) {
+	endpoint := c.baseURL + "/version"
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+
+	c.logger.Debug("Fetching governance service version", zap.String("url", endpoint))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("governance service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var info ServiceInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return "", fmt.Errorf("failed to unmarshal service version: %w", err)
+	}
+	if info.PayloadVersion == PayloadV1 || info.PayloadVersion == PayloadV2 {
+		return info.PayloadVersion, nil
+	}
+
+	major, _, _ := strings.Cut(strings.TrimPrefix(info.Version, "v"), ".")
+	number, err := strconv.Atoi(major)
+	if err != nil {
+		return "", fmt.Errorf("unrecognized service version %q", info.Version)
+	}
+	if number < 2 {
+		return PayloadV1, nil
+	}
+	return PayloadV2, nil
+}
+
+// analysisPayload builds the analysis request body in the shape of the payload version
+func analysisPayload(version string, analysis AnalysisRequest, content json.RawMessage) map[string]interface{} {
+	if version == PayloadV1 {
+		payload := map[string]interface{}{
+			"ruleset_id": analysis.RuleID,
+			"filename":   analysis.Filename,
+			"content":    content,
+		}
+		if analysis.Version != "" {
+			payload["ruleset_version"] = analysis.Version
+		}
+		if analysis.APIID != "" {
+			payload["api_id"] = analysis.APIID
+		}
+		if analysis.APIName != "" {
+			payload["api_name"] = analysis.APIName
+		}
+		if analysis.Format != "" {
+			payload["format"] = analysis.Format
+		}
+		if analysis.Ruleset != nil {
+			delete(payload, "ruleset_id")
+			delete(payload, "ruleset_version")
+			delete(payload, "api_id")
+			delete(payload, "api_name")
+			payload["ruleset"] = analysis.Ruleset.Content
+		}
+		return payload
+	}
+
+	apiContent := map[string]interface{}{
+		"name":    analysis.Filename,
+		"content": content,
+	}
+	if analysis.Format != "" {
+		apiContent["format"] = analysis.Format
+	}
+	ruleSetSelector := map[string]interface{}{
+		"id": analysis.RuleID,
+	}
+	if analysis.Version != "" {
+		ruleSetSelector["version"] = analysis.Version
+	}
+	payload := map[string]interface{}{
+		"ruleSetSelector": ruleSetSelector,
+		"apiContent":      apiContent,
+	}
+	// A local ruleset is evaluated one-off rather than against an API record
+	if analysis.Ruleset != nil {
+		delete(payload, "ruleSetSelector")
+		payload["ruleSet"] = map[string]interface{}{
+			"name":       analysis.Ruleset.Name,
+			"definition": analysis.Ruleset.Content,
+		}
+		return payload
+	}
+
+	// Link the findings to an existing API record instead of an anonymous upload
+	if analysis.APIID != "" || analysis.APIName != "" {
+		payload["apiSelector"] = map[string]interface{}{
+			"id":   analysis.APIID,
+			"name": analysis.APIName,
+		}
+	}
+	return payload
+}
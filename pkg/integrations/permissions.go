@@ -0,0 +1,133 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// MissingPermission is a permission a token lacks, in the provider's terms,
+// e.g. checks: write on GitHub or the api scope on GitLab
+type MissingPermission struct {
+	Permission string
+	Reason     string
+}
+
+// userTokenPrefixes are the prefixes of GitHub tokens that act as a user:
+// classic and fine-grained personal access tokens and OAuth tokens. The
+// workflow's GITHUB_TOKEN and GitHub App tokens start with ghs_.
+var userTokenPrefixes = []string{"ghp_", "github_pat_", "gho_", "ghu_"}
+
+// MissingPermissions returns the permissions of required, e.g. checks: write,
+// the token is known to lack on the repository. The permissions of the
+// workflow's GITHUB_TOKEN and GitHub App tokens can't be looked up; requests
+// they're not allowed to make fail with the permission they need instead.
+func (c *GitHubClient) MissingPermissions(ctx context.Context, required []string) ([]MissingPermission, error) {
+	if !slices.ContainsFunc(userTokenPrefixes, func(prefix string) bool { return strings.HasPrefix(c.token, prefix) }) {
+		return nil, nil
+	}
+
+	var repository struct {
+		Private     bool `json:"private"`
+		Permissions struct {
+			Push bool `json:"push"`
+		} `json:"permissions"`
+	}
+	body, header, err := c.request(ctx, "GET", "/repos/"+c.repository, nil)
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized:
+		return nil, fmt.Errorf("github_token is invalid or expired")
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		return missingAll(required, "the token can't access "+c.repository), nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &repository); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// Classic tokens list their scopes, fine-grained tokens don't
+	scopes := splitScopes(header.Get("X-OAuth-Scopes"))
+	scoped := header.Get("X-OAuth-Scopes") == "" || slices.Contains(scopes, "repo") ||
+		(!repository.Private && slices.Contains(scopes, "public_repo"))
+
+	var missing []MissingPermission
+	for _, permission := range required {
+		switch {
+		case permission == "checks: write":
+			missing = append(missing, MissingPermission{permission,
+				"check runs can only be created with the workflow's GITHUB_TOKEN or a GitHub App token"})
+		case strings.HasSuffix(permission, ": write") && !scoped:
+			missing = append(missing, MissingPermission{permission, "the token lacks the repo scope"})
+		case strings.HasSuffix(permission, ": write") && !repository.Permissions.Push:
+			missing = append(missing, MissingPermission{permission, "the token has no write access to " + c.repository})
+		}
+	}
+	return missing, nil
+}
+
+// MissingPermissions returns the scopes of required, e.g. api, the token
+// lacks. Tokens whose scopes can't be looked up, such as CI job tokens, are
+// assumed to have them.
+func (c *GitLabClient) MissingPermissions(ctx context.Context, required []string) ([]MissingPermission, error) {
+	var token struct {
+		Scopes []string `json:"scopes"`
+	}
+	err := c.do(ctx, "GET", "/personal_access_tokens/self", nil, &token)
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized:
+		return nil, fmt.Errorf("gitlab_token is invalid or expired")
+	case errors.As(err, &apiErr):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var missing []MissingPermission
+	for _, scope := range required {
+		if !slices.Contains(token.Scopes, scope) {
+			missing = append(missing, MissingPermission{scope,
+				"the token has the " + strings.Join(token.Scopes, ", ") + " scopes"})
+		}
+	}
+	return missing, nil
+}
+
+// missingAll reports every permission of required as missing for the reason
+func missingAll(required []string, reason string) []MissingPermission {
+	missing := make([]MissingPermission, 0, len(required))
+	for _, permission := range required {
+		missing = append(missing, MissingPermission{permission, reason})
+	}
+	return missing
+}
+
+// splitScopes splits a comma separated scopes header
+func splitScopes(header string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// acceptedPermissions formats the X-Accepted-GitHub-Permissions header of a
+// rejected request, e.g. checks=write becomes checks: write. Alternatives are
+// separated by semicolons.
+func acceptedPermissions(header string) string {
+	var alternatives []string
+	for _, alternative := range strings.Split(header, ";") {
+		if alternative = strings.TrimSpace(alternative); alternative != "" {
+			alternatives = append(alternatives, "`"+strings.ReplaceAll(strings.ReplaceAll(alternative, "=", ": "), ",", ", ")+"`")
+		}
+	}
+	return strings.Join(alternatives, " or ")
+}
@@ -1,6 +1,13 @@
 package integrations
 
-import "os"
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 // DetectCI detects the running CI platform
 func DetectCI() string {
@@ -18,24 +25,136 @@ func DetectCI() string {
 func GetContext(ci string) map[string]string {
 	switch ci {
 	case "github":
-		return map[string]string{
-			"repository": os.Getenv("GITHUB_REPOSITORY"),
-			"commit":     os.Getenv("GITHUB_SHA"),
-			"branch":     os.Getenv("GITHUB_REF_NAME"),
-			"actor":      os.Getenv("GITHUB_ACTOR"),
-			"workflow":   os.Getenv("GITHUB_WORKFLOW"),
-			"run_id":     os.Getenv("GITHUB_RUN_ID"),
+		context := map[string]string{
+			"repository":   os.Getenv("GITHUB_REPOSITORY"),
+			"event":        os.Getenv("GITHUB_EVENT_NAME"),
+			"commit":       os.Getenv("GITHUB_SHA"),
+			"branch":       firstNonEmpty(os.Getenv("GITHUB_HEAD_REF"), os.Getenv("GITHUB_REF_NAME")),
+			"actor":        os.Getenv("GITHUB_ACTOR"),
+			"workflow":     os.Getenv("GITHUB_WORKFLOW"),
+			"run_id":       os.Getenv("GITHUB_RUN_ID"),
+			"pull_request": githubPullRequestNumber(os.Getenv("GITHUB_REF")),
+			"event_type":   githubEventType(os.Getenv("GITHUB_EVENT_NAME"), os.Getenv("GITHUB_REF_TYPE")),
+		}
+		// Tag runs aren't on a branch, so branch policies don't apply to them
+		if context["event_type"] == EventTag {
+			context["tag"], context["branch"] = context["branch"], ""
 		}
+		applyGitHubEvent(context, os.Getenv("GITHUB_EVENT_PATH"))
+		if context["event"] == "merge_group" {
+			applyMergeGroup(context, os.Getenv("GITHUB_EVENT_PATH"))
+		}
+		return context
 	case "gitlab":
 		return map[string]string{
-			"repository": os.Getenv("CI_PROJECT_PATH"),
-			"commit":     os.Getenv("CI_COMMIT_SHA"),
-			"branch":     os.Getenv("CI_COMMIT_BRANCH"),
-			"actor":      os.Getenv("GITLAB_USER_NAME"),
-			"pipeline":   os.Getenv("CI_PIPELINE_ID"),
-			"job":        os.Getenv("CI_JOB_ID"),
+			"repository":     os.Getenv("CI_PROJECT_PATH"),
+			"commit":         os.Getenv("CI_COMMIT_SHA"),
+			"branch":         firstNonEmpty(os.Getenv("CI_COMMIT_BRANCH"), os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME")),
+			"actor":          os.Getenv("GITLAB_USER_NAME"),
+			"pipeline":       os.Getenv("CI_PIPELINE_ID"),
+			"job":            os.Getenv("CI_JOB_ID"),
+			"pull_request":   os.Getenv("CI_MERGE_REQUEST_IID"),
+			"event_type":     gitlabEventType(os.Getenv("CI_PIPELINE_SOURCE"), os.Getenv("CI_COMMIT_TAG")),
+			"tag":            os.Getenv("CI_COMMIT_TAG"),
+			"default_branch": os.Getenv("CI_DEFAULT_BRANCH"),
+			"target_branch":  os.Getenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME"),
 		}
 	default:
 		return map[string]string{"env": "local"}
 	}
 }
+
+// githubPullRequestNumber extracts the pull request number from a refs/pull/<n>/merge ref
+func githubPullRequestNumber(ref string) string {
+	if !strings.HasPrefix(ref, "refs/pull/") {
+		return ""
+	}
+	number, _, _ := strings.Cut(strings.TrimPrefix(ref, "refs/pull/"), "/")
+	return number
+}
+
+// mergeGroupPullRequest matches the pull request number in a merge queue branch,
+// e.g. refs/heads/gh-readonly-queue/main/pr-123-<sha>
+var mergeGroupPullRequest = regexp.MustCompile(`/pr-(\d+)-[0-9a-f]+$`)
+
+// githubMergeGroupEvent is the part of a merge_group event payload the action uses
+type githubMergeGroupEvent struct {
+	MergeGroup struct {
+		HeadSHA string `json:"head_sha"`
+		HeadRef string `json:"head_ref"`
+		BaseSHA string `json:"base_sha"`
+		BaseRef string `json:"base_ref"`
+	} `json:"merge_group"`
+}
+
+// applyMergeGroup resolves a merge queue run to the merge queue commit, the
+// target branch and its base commit from the event payload. The branch is the
+// target branch, so branch policies apply as they would to the pull request.
+func applyMergeGroup(context map[string]string, eventPath string) {
+	data, err := os.ReadFile(eventPath)
+	if err != nil {
+		return
+	}
+	var event githubMergeGroupEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return
+	}
+
+	group := event.MergeGroup
+	if group.HeadSHA != "" {
+		context["commit"] = group.HeadSHA
+	}
+	if group.BaseRef != "" {
+		context["branch"] = strings.TrimPrefix(group.BaseRef, "refs/heads/")
+	}
+	context["base_commit"] = group.BaseSHA
+	if match := mergeGroupPullRequest.FindStringSubmatch(group.HeadRef); match != nil {
+		context["pull_request"] = match[1]
+	}
+}
+
+// IssueComment is a pull request comment that triggered the run
+type IssueComment struct {
+	Body        string
+	Author      string
+	PullRequest string // Empty for comments on plain issues
+}
+
+// ReadGitHubComment reads the comment from an issue_comment event payload
+func ReadGitHubComment(eventPath string) (*IssueComment, error) {
+	data, err := os.ReadFile(eventPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event payload: %w", err)
+	}
+	var event struct {
+		Comment struct {
+			Body string `json:"body"`
+			User struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		} `json:"comment"`
+		Issue struct {
+			Number      int              `json:"number"`
+			PullRequest *json.RawMessage `json:"pull_request"`
+		} `json:"issue"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse event payload: %w", err)
+	}
+
+	comment := &IssueComment{Body: event.Comment.Body, Author: event.Comment.User.Login}
+	if event.Issue.PullRequest != nil {
+		comment.PullRequest = strconv.Itoa(event.Issue.Number)
+	}
+	return comment, nil
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
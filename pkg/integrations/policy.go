@@ -0,0 +1,72 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// GetPolicyBundle returns the organization policy bundle the governance service
+// distributes, or nil when the service doesn't have one for the tenant
+func (c *GovernanceClient) GetPolicyBundle(ctx context.Context) ([]byte, error) {
+	endpoint := c.baseURL + "/policy-bundle"
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+
+	c.logger.Debug("Fetching policy bundle", zap.String("url", endpoint))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := authError(resp, body); err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("governance service returned status %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+// FetchPolicyBundle downloads an organization policy bundle from a URL, e.g. a
+// file in a central repository. The token, if any, is sent as a bearer token.
+func FetchPolicyBundle(ctx context.Context, bundleURL, token string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", bundleURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := newHTTPClient(30 * time.Second).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch policy bundle: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy bundle URL returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,273 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// providerRetries and providerBackoff configure the retries of transient
+	// provider API failures
+	providerRetries = 3
+	providerBackoff = time.Second
+
+	// maxRateLimitWait is the longest a request waits for an exhausted rate
+	// limit to reset before failing instead
+	maxRateLimitWait = time.Minute
+
+	// maxPages caps the pages a listing follows
+	maxPages = 100
+)
+
+// nextLink matches the next page in a Link response header
+var nextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// providerClient makes requests to a code hosting provider's REST API, shared
+// by the GitHub and GitLab clients. It retries transient failures, waits out
+// exhausted rate limits and follows pagination links.
+type providerClient struct {
+	provider   string // Name used in errors and logs, e.g. GitHub
+	apiURL     string
+	headers    map[string]string
+	httpClient *http.Client
+	logger     *zap.Logger
+
+	mu         sync.Mutex
+	remaining  int       // Requests left in the rate limit window, -1 if unknown
+	reset      time.Time // End of the rate limit window
+	retryStats RetryStats
+}
+
+// newProviderClient creates a client for a provider API, sending headers with
+// every request
+func newProviderClient(provider, apiURL string, headers map[string]string, logger *zap.Logger) *providerClient {
+	return &providerClient{
+		provider:   provider,
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		headers:    headers,
+		httpClient: newHTTPClient(30 * time.Second),
+		logger:     logger,
+		remaining:  -1,
+	}
+}
+
+// do performs an API request, encoding body as JSON and decoding the response into out
+func (c *providerClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	respBody, _, err := c.request(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+	return nil
+}
+
+// list performs a GET request and follows the next links of the response,
+// passing the body of each page to page
+func (c *providerClient) list(ctx context.Context, path string, page func(body []byte) error) error {
+	for n := 0; path != "" && n < maxPages; n++ {
+		respBody, header, err := c.request(ctx, "GET", path, nil)
+		if err != nil {
+			return err
+		}
+		if err := page(respBody); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		path = ""
+		if match := nextLink.FindStringSubmatch(header.Get("Link")); match != nil {
+			path = match[1]
+		}
+	}
+	return nil
+}
+
+// RetryStats returns the retries used by the client so far
+func (c *providerClient) RetryStats() RetryStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.retryStats
+}
+
+// request performs an API request with retries and returns the response body
+// and headers. The path may also be an absolute URL, such as a next link.
+func (c *providerClient) request(ctx context.Context, method, path string, body interface{}) ([]byte, http.Header, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+	endpoint := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		endpoint = c.apiURL + path
+	}
+
+	var respBody []byte
+	var header http.Header
+	var stats RetryStats
+	err := retry(ctx, c.logger, c.provider, method+" "+path, providerRetries, providerBackoff, &stats, func(ctx context.Context) error {
+		if err := c.waitForRateLimit(ctx); err != nil {
+			return err
+		}
+		var err error
+		respBody, header, err = c.attempt(ctx, method, endpoint, payload)
+		return err
+	})
+	c.mu.Lock()
+	c.retryStats.Retries += stats.Retries
+	c.retryStats.Waited += stats.Waited
+	c.mu.Unlock()
+	return respBody, header, err
+}
+
+// attempt makes a single API request
+func (c *providerClient) attempt(ctx context.Context, method, endpoint string, payload []byte) ([]byte, http.Header, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	c.logger.Debug("Making request to "+c.provider, zap.String("method", method), zap.String("url", endpoint))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, fmt.Errorf("failed to make request: %w", err)
+		}
+		return nil, nil, &retryableError{err: fmt.Errorf("failed to make request: %w", err)}
+	}
+	defer resp.Body.Close()
+	rateLimited := c.trackRateLimit(resp)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, &retryableError{err: fmt.Errorf("failed to read response body: %w", err)}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{Provider: c.provider, StatusCode: resp.StatusCode, Body: string(respBody),
+			Permissions: acceptedPermissions(resp.Header.Get("X-Accepted-GitHub-Permissions"))}
+		if rateLimited {
+			return nil, nil, c.rateLimitError(resp, apiErr)
+		}
+		if retryableStatus(resp.StatusCode) {
+			return nil, nil, &retryableError{err: apiErr, retryAfter: retryAfter(resp)}
+		}
+		return nil, nil, apiErr
+	}
+	return respBody, resp.Header, nil
+}
+
+// trackRateLimit records the rate limit headers of a response and reports
+// whether the request was rejected by the rate limit. GitHub sends
+// X-RateLimit-* headers, GitLab RateLimit-* headers.
+func (c *providerClient) trackRateLimit(resp *http.Response) bool {
+	remaining := headerInt(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	reset := headerInt(resp.Header, "X-RateLimit-Reset", "RateLimit-Reset")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if remaining >= 0 {
+		c.remaining = remaining
+	}
+	if reset > 0 {
+		c.reset = time.Unix(int64(reset), 0)
+	}
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	// Secondary rate limits only send Retry-After
+	return remaining == 0 || resp.Header.Get("Retry-After") != ""
+}
+
+// rateLimitError returns a retryable error for a rate limited request that
+// resets soon enough to wait for, or a permanent error otherwise
+func (c *providerClient) rateLimitError(resp *http.Response, apiErr *APIError) error {
+	wait := retryAfter(resp)
+	if wait == 0 {
+		c.mu.Lock()
+		wait = time.Until(c.reset)
+		c.mu.Unlock()
+	}
+	if wait > maxRetryWait {
+		return fmt.Errorf("%s API rate limit exceeded, resets in %s: %w", c.provider, wait.Round(time.Second), apiErr)
+	}
+	return &retryableError{err: apiErr, retryAfter: max(wait, time.Second)}
+}
+
+// waitForRateLimit delays a request while the rate limit is exhausted, so the
+// request isn't rejected, or fails when the limit resets too far out
+func (c *providerClient) waitForRateLimit(ctx context.Context) error {
+	c.mu.Lock()
+	wait := time.Duration(0)
+	if c.remaining == 0 {
+		wait = time.Until(c.reset)
+	}
+	c.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if wait > maxRateLimitWait {
+		return fmt.Errorf("%s API rate limit exhausted, resets in %s", c.provider, wait.Round(time.Second))
+	}
+
+	c.logger.Warn(c.provider+" API rate limit exhausted, waiting for it to reset", zap.Duration("wait", wait))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+	}
+	c.mu.Lock()
+	c.remaining = -1
+	c.mu.Unlock()
+	return nil
+}
+
+// headerInt returns the first of the headers that is set as an integer, or -1
+func headerInt(header http.Header, names ...string) int {
+	for _, name := range names {
+		if value, err := strconv.Atoi(header.Get(name)); err == nil {
+			return value
+		}
+	}
+	return -1
+}
+
+// APIError is returned when a provider API responds with a non-success status
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+	// Permissions the request needs, as reported by GitHub, e.g. `checks: write`
+	Permissions string
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode == http.StatusForbidden && e.Permissions != "" {
+		return fmt.Sprintf("%s API returned status %d, the token is missing %s: %s", e.Provider, e.StatusCode, e.Permissions, e.Body)
+	}
+	return fmt.Sprintf("%s API returned status %d: %s", e.Provider, e.StatusCode, e.Body)
+}
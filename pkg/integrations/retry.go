@@ -0,0 +1,103 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxRetryWait caps the wait between attempts, including Retry-After hints
+const maxRetryWait = 30 * time.Second
+
+// RetryStats describes the retries needed to talk to a service
+type RetryStats struct {
+	Retries int           // Requests repeated after a transient failure
+	Waited  time.Duration // Time spent backing off between attempts
+}
+
+// retryableError is a transient failure worth repeating the request for
+type retryableError struct {
+	err        error
+	retryAfter time.Duration // Wait requested by the service, if any
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// SetRetries makes analysis requests retry transient failures (network errors,
+// 429 and 5xx responses) up to retries times, backing off exponentially from backoff
+func (c *GovernanceClient) SetRetries(retries int, backoff time.Duration) {
+	c.retries = retries
+	c.backoff = backoff
+}
+
+// SetTimeout bounds each request of the client, every retry gets the full timeout
+func (c *GovernanceClient) SetTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// RetryStats returns the retries used by the client so far
+func (c *GovernanceClient) RetryStats() RetryStats {
+	return c.retryStats
+}
+
+// withRetries runs attempt until it succeeds, fails permanently or runs out of
+// retries
+func (c *GovernanceClient) withRetries(ctx context.Context, operation string, attempt func(ctx context.Context) error) error {
+	return retry(ctx, c.logger, "Governance service", operation, c.retries, c.backoff, &c.retryStats, attempt)
+}
+
+// retry runs attempt until it succeeds, fails permanently or runs out of
+// retries. Each retry is logged with its attempt number and the time spent so
+// far, and counted in stats.
+func retry(ctx context.Context, logger *zap.Logger, service, operation string, retries int, backoff time.Duration, stats *RetryStats, attempt func(ctx context.Context) error) error {
+	started := time.Now()
+	for n := 1; ; n++ {
+		err := attempt(ctx)
+		var retryable *retryableError
+		if err == nil || !errors.As(err, &retryable) || n > retries {
+			if err != nil && n > 1 {
+				logger.Warn("Giving up after retries", zap.String("operation", operation),
+					zap.Int("attempt", n), zap.Duration("elapsed", time.Since(started)))
+			}
+			return err
+		}
+
+		wait := backoff << (n - 1)
+		if retryable.retryAfter > 0 {
+			wait = retryable.retryAfter
+		}
+		if wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+		logger.Warn(service+" request failed, retrying", zap.String("operation", operation),
+			zap.Int("attempt", n), zap.Int("max_attempts", retries+1), zap.Duration("wait", wait),
+			zap.Duration("elapsed", time.Since(started)), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		stats.Retries++
+		stats.Waited += wait
+	}
+}
+
+// retryableStatus reports whether a response status is a transient failure
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// retryAfter parses a Retry-After header given in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,132 @@
+package integrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// InlineRuleset is a local Spectral-style ruleset submitted with the analysis
+// request instead of a published one, so rules can be tested before publishing
+type InlineRuleset struct {
+	Name    string
+	Content json.RawMessage // The ruleset as JSON
+	// Metadata lists the rules the ruleset defines, like the metadata of a
+	// published ruleset
+	Metadata *Ruleset
+}
+
+// spectralRuleset is the part of a Spectral ruleset the action reads
+type spectralRuleset struct {
+	Extends interface{}                `json:"extends"`
+	Rules   map[string]json.RawMessage `json:"rules"`
+}
+
+// spectralRule is the part of a Spectral rule definition the action reads.
+// Rules can also be a bare severity that overrides an extended rule.
+type spectralRule struct {
+	Description string `json:"description"`
+}
+
+// LoadInlineRuleset reads a YAML or JSON Spectral ruleset from a file
+func LoadInlineRuleset(path string) (*InlineRuleset, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ruleset file: %w", err)
+	}
+	data, err := specJSON(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ruleset file %s: %w", path, err)
+	}
+	var parsed spectralRuleset
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse ruleset file %s: %w", path, err)
+	}
+	if parsed.Rules == nil && parsed.Extends == nil {
+		return nil, fmt.Errorf("ruleset file %s has neither rules nor extends", path)
+	}
+
+	name := filepath.Base(path)
+	metadata := &Ruleset{ID: name, Name: name}
+	for ruleName, definition := range parsed.Rules {
+		// Rules turned off don't evaluate
+		var severity interface{}
+		if json.Unmarshal(definition, &severity) == nil && (severity == "off" || severity == false) {
+			continue
+		}
+		rule := RuleMetadata{Name: ruleName}
+		var spec spectralRule
+		if json.Unmarshal(definition, &spec) == nil {
+			rule.Description = spec.Description
+		}
+		metadata.Rules = append(metadata.Rules, rule)
+	}
+	sort.Slice(metadata.Rules, func(i, j int) bool { return metadata.Rules[i].Name < metadata.Rules[j].Name })
+	return &InlineRuleset{Name: name, Content: data, Metadata: metadata}, nil
+}
+
+// ruleDoc is the documentation of a rule in a rule docs bundle
+type ruleDoc struct {
+	Description string   `json:"description"`
+	Remediation string   `json:"remediation"`
+	Frameworks  []string `json:"frameworks"`
+}
+
+// LoadRuleDocs reads a YAML or JSON rule docs bundle, mapping rule names to
+// their description, remediation and frameworks, as ruleset metadata for
+// enriching reports without the governance service
+func LoadRuleDocs(path string) (*Ruleset, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule docs: %w", err)
+	}
+	data, err := specJSON(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rule docs %s: %w", path, err)
+	}
+	var docs map[string]ruleDoc
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return nil, fmt.Errorf("failed to parse rule docs %s: must map rule names to a description and remediation: %w", path, err)
+	}
+
+	name := filepath.Base(path)
+	ruleset := &Ruleset{ID: name, Name: name}
+	for ruleName, doc := range docs {
+		ruleset.Rules = append(ruleset.Rules, RuleMetadata{
+			Name:        ruleName,
+			Description: doc.Description,
+			Frameworks:  doc.Frameworks,
+			Remediation: doc.Remediation,
+		})
+	}
+	sort.Slice(ruleset.Rules, func(i, j int) bool { return ruleset.Rules[i].Name < ruleset.Rules[j].Name })
+	return ruleset, nil
+}
+
+// WithDocs returns a copy of the ruleset with the descriptions, remediations
+// and frameworks it lacks filled in from a rule docs bundle. Rules only the
+// docs describe aren't added, as the ruleset doesn't evaluate them.
+func (r *Ruleset) WithDocs(docs *Ruleset) *Ruleset {
+	if r == nil || docs == nil {
+		return r
+	}
+	enriched := *r
+	enriched.Rules = make([]RuleMetadata, len(r.Rules))
+	for i, rule := range r.Rules {
+		if doc := docs.Rule(rule.Name); doc != nil {
+			if rule.Description == "" {
+				rule.Description = doc.Description
+			}
+			if rule.Remediation == "" {
+				rule.Remediation = doc.Remediation
+			}
+			if len(rule.Frameworks) == 0 {
+				rule.Frameworks = doc.Frameworks
+			}
+		}
+		enriched.Rules[i] = rule
+	}
+	return &enriched
+}
@@ -0,0 +1,70 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// slackRetries is how often transient Slack webhook failures are retried
+const slackRetries = 2
+
+// SlackMessage is a message posted to a Slack incoming webhook
+type SlackMessage struct {
+	Text string `json:"text"`
+}
+
+// SlackClient posts messages to Slack incoming webhooks. Webhook URLs carry
+// their credentials, so they are kept out of logs, errors and debug bundles.
+type SlackClient struct {
+	httpClient *http.Client
+	logger     *zap.Logger
+}
+
+// NewSlackClient creates a Slack webhook client
+func NewSlackClient(logger *zap.Logger) *SlackClient {
+	return &SlackClient{httpClient: newHTTPClient(30 * time.Second), logger: logger}
+}
+
+// Post posts a message to a webhook, retrying transient failures
+func (c *SlackClient) Post(ctx context.Context, webhookURL string, message SlackMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+	var stats RetryStats
+	return retry(ctx, c.logger, "Slack", "POST webhook", slackRetries, time.Second, &stats, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(payload))
+		if err != nil {
+			return errors.New("failed to create request: invalid Slack webhook URL")
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			// Drop the URL the error carries
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			return &retryableError{err: fmt.Errorf("failed to post to Slack: %w", err)}
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			err := fmt.Errorf("Slack returned status %d: %s", resp.StatusCode, body)
+			if retryableStatus(resp.StatusCode) {
+				return &retryableError{err: err, retryAfter: retryAfter(resp)}
+			}
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,91 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// ErrScopeUnknown is returned when the governance service doesn't report token scopes
+var ErrScopeUnknown = errors.New("governance service doesn't report token scopes")
+
+// TokenScope is the tenant a governance token is scoped to
+type TokenScope struct {
+	OrgID   string   `json:"orgId"`
+	TeamIDs []string `json:"teamIds"`
+}
+
+// SetTenant attributes all requests to an organization and team of a
+// multi-tenant governance deployment
+func (c *GovernanceClient) SetTenant(orgID, teamID string) {
+	c.orgID = orgID
+	c.teamID = teamID
+}
+
+// setHeaders sets the authentication and tenant headers of a request
+func (c *GovernanceClient) setHeaders(req *http.Request) {
+	req.Header.Set("X-API-Key", c.authToken)
+	if c.orgID != "" {
+		req.Header.Set("X-Org-ID", c.orgID)
+	}
+	if c.teamID != "" {
+		req.Header.Set("X-Team-ID", c.teamID)
+	}
+}
+
+// GetTokenScope returns the tenant the token is scoped to, or ErrScopeUnknown
+// when the service doesn't report it
+func (c *GovernanceClient) GetTokenScope(ctx context.Context) (*TokenScope, error) {
+	endpoint := c.baseURL + "/auth/scope"
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+
+	c.logger.Debug("Fetching token scope", zap.String("url", endpoint))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := authError(resp, body); err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrScopeUnknown
+	default:
+		return nil, fmt.Errorf("governance service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var scope TokenScope
+	if err := json.Unmarshal(body, &scope); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token scope: %w", err)
+	}
+	return &scope, nil
+}
+
+// Covers reports whether the scope includes the organization and team. A scope
+// without teams covers every team of its organization.
+func (s *TokenScope) Covers(orgID, teamID string) error {
+	if orgID != "" && s.OrgID != orgID {
+		return fmt.Errorf("%w: token is scoped to org %q, not %q", ErrInsufficientPermissions, s.OrgID, orgID)
+	}
+	if teamID != "" && len(s.TeamIDs) > 0 && !slices.Contains(s.TeamIDs, teamID) {
+		return fmt.Errorf("%w: token is not scoped to team %q", ErrInsufficientPermissions, teamID)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// flakyToken makes every other analysis request fail with 503, to exercise retries
+const flakyToken = "flaky-token"
+
+// Handler returns the mock governance service, with its API under /api. It
+// reports an error and a warning for any spec. Use "expired-token" or
+// "readonly-token" as the API key to simulate auth failures, and "flaky-token"
+// to have every other analysis request fail.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	var evaluations atomic.Int64
+
+	mux.HandleFunc("/api/version", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"version":        "2.4.0",
+			"payloadVersion": "v2",
+		})
+	})
+
+	mux.HandleFunc("/api/auth/scope", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"orgId":   "acme",
+			"teamIds": []string{"payments", "platform"},
+		})
+	})
+
+	mux.HandleFunc("/api/exemptions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			// The rate limit finding on test-data/openapi.yaml is exempted
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": "exm-0000", "status": "approved", "fingerprint": "fb05a525d82170ab", "rule": "owasp-rate-limit"},
+			})
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     "exm-0001",
+			"status": "pending",
+		})
+	})
+
+	mux.HandleFunc("/api/rulesets/evaluate", func(w http.ResponseWriter, r *http.Request) {
+		// Set CORS headers
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Check for X-API-Key header (accept any token for testing)
+		apiKey := r.Header.Get("X-API-Key")
+		if apiKey == flakyToken && evaluations.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]interface{}{"Status": "Error", "Message": "Service temporarily unavailable"})
+			return
+		}
+		if apiKey == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"Status":  "Error",
+				"Message": "Missing or invalid X-API-Key header",
+				"Meta":    nil,
+			})
+			return
+		}
+
+		// Mock response based on the example in requirements
+		response := []map[string]interface{}{
+			{
+				"code":     "owasp-define-error-responses-401",
+				"path":     []string{"paths", "/", "get", "responses"},
+				"message":  "missing response code `401` for `GET`",
+				"severity": 1,
+				"range": map[string]interface{}{
+					"start": map[string]interface{}{
+						"line":      1,
+						"character": 194,
+					},
+					"end": map[string]interface{}{
+						"line":      1,
+						"character": 205,
+					},
+				},
+				"source": "684acc5b0e08080001e72b3a",
+				"api": map[string]interface{}{
+					"id":   "684acc5b0e08080001e72b3a",
+					"name": "testing-rest-api-2025-05",
+				},
+				"rule": map[string]interface{}{
+					"name": "owasp-define-error-responses-401",
+				},
+			},
+			{
+				"code":     "owasp-rate-limit",
+				"path":     []string{"paths", "/", "get", "responses", "200"},
+				"message":  "response with code `200`, must contain one of the defined headers: `{X-RateLimit-Limit} {X-Rate-Limit-Limit} {RateLimit-Limit, RateLimit-Reset} {RateLimit} `",
+				"severity": 0,
+				"range": map[string]interface{}{
+					"start": map[string]interface{}{
+						"line":      1,
+						"character": 207,
+					},
+					"end": map[string]interface{}{
+						"line":      1,
+						"character": 212,
+					},
+				},
+				"source": "684acc5b0e08080001e72b3a",
+				"api": map[string]interface{}{
+					"id":   "684acc5b0e08080001e72b3a",
+					"name": "testing-rest-api-2025-05",
+				},
+				"rule": map[string]interface{}{
+					"name": "owasp-rate-limit",
+				},
+			},
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	})
+
+	// Ruleset lookup used by the action to validate the token before analysis.
+	// Use "expired-token" or "readonly-token" as the API key to simulate auth failures.
+	mux.HandleFunc("/api/rulesets/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Header.Get("X-API-Key") {
+		case "":
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"Status": "Error", "Message": "Missing or invalid X-API-Key header"})
+			return
+		case "expired-token":
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"Status": "Error", "Message": "Token has expired"})
+			return
+		case "readonly-token":
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{"Status": "Error", "Message": "Insufficient permissions"})
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/api/rulesets/")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      id,
+			"name":    "Mock Ruleset",
+			"version": "1.0.0",
+			"rules": []map[string]interface{}{
+				{
+					"name":        "owasp-define-error-responses-401",
+					"description": "Operations should define a 401 response",
+					"frameworks":  []string{"OWASP API2"},
+				},
+				{
+					"name":        "owasp-rate-limit",
+					"description": "Responses should carry rate limiting headers",
+					"frameworks":  []string{"OWASP API4", "PCI DSS 6.4"},
+				},
+			},
+		})
+	})
+
+	return mux
+}
@@ -0,0 +1,68 @@
+package mockserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerAuth(t *testing.T) {
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	tests := []struct {
+		token string
+		want  int
+	}{
+		{"mock-token", http.StatusOK},
+		{"", http.StatusUnauthorized},
+		{"expired-token", http.StatusUnauthorized},
+		{"readonly-token", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			req, err := http.NewRequest(method, server.URL+"/api/rulesets/r", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.token != "" {
+				req.Header.Set("X-API-Key", tt.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("%s with token %q: status %d, want %d", method, tt.token, resp.StatusCode, tt.want)
+			}
+		}
+	}
+}
+
+func TestHandlerFlakyToken(t *testing.T) {
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	var statuses []int
+	for i := 0; i < 4; i++ {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/api/rulesets/evaluate", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-API-Key", flakyToken)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		statuses = append(statuses, resp.StatusCode)
+	}
+	want := []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusServiceUnavailable, http.StatusOK}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("statuses %v, want %v", statuses, want)
+		}
+	}
+}